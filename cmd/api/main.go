@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"log/slog"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -12,8 +13,14 @@ import (
 	"go-api-first-steps/internal/api"
 	"go-api-first-steps/internal/config"
 	"go-api-first-steps/internal/dependencies"
+	"go-api-first-steps/internal/grpcapi"
+	"go-api-first-steps/internal/h3"
+	"go-api-first-steps/internal/tracing"
 	"go-api-first-steps/pkg/logger"
 
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+
 	// IMPORTANTE: Importe a pasta docs gerada pelo swag
 	_ "go-api-first-steps/cmd/api/swagger"
 )
@@ -32,6 +39,10 @@ import (
 // @securityDefinitions.apikey BearerAuth
 // @in header
 // @name Authorization
+//
+// @securityDefinitions.apikey ApiKeyAuth
+// @in header
+// @name X-API-Key
 func main() {
 	// 1. Carregar Configurações (Carrega .env e variáveis)
 	cfg, err := config.Load()
@@ -41,12 +52,53 @@ func main() {
 	}
 
 	// 2. Configuração Básica de Logs (Com App Insights se configurado)
-	logger.Setup(cfg.AppInsightsConnectionString, cfg.DevMode)
+	logger.Setup(logger.Options{
+		ConnectionString: cfg.AppInsightsConnectionString,
+		Debug:            cfg.DevMode,
+		Level:            cfg.LogLevel,
+		AzureLevel:       cfg.AppInsightsLogLevel,
+		AzureSampler:     logSampler(cfg),
+		Async:            logQueue(cfg),
+		Redaction:        cfg.LogRedaction,
+	})
+
+	// 2.1 Tracing (OpenTelemetry): propagação W3C e exportação OTLP/HTTP opcional
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Options{
+		ServiceName: cfg.OTelServiceName,
+		Endpoint:    cfg.OTelEndpoint,
+		SampleRatio: cfg.OTelSampleRatio,
+		AppInsights: logger.Telemetry(), // Requests e dependências no App Insights (nil = desabilitado)
+		Redactor:    logger.Redaction(), // Mesmas regras de redação dos logs
+	})
+	if err != nil {
+		slog.Error("Falha ao configurar tracing", "error", err)
+		os.Exit(1)
+	}
 
 	// 3. Injeção de Dependências
 	// Usamos o container para não poluir o main com construções complexas
 	ctn := dependencies.NewContainer(cfg)
 
+	// 3.1 Hot reload da política de autorização (se POLICY_FILE estiver definido)
+	watchCtx, stopWatch := context.WithCancel(context.Background())
+	defer stopWatch()
+	go ctn.Enforcer.Watch(watchCtx, cfg.PolicyReloadInterval)
+
+	// 3.2 Rotação das chaves JWT locais (AUTH_MODE=offline)
+	if ctn.KeySet != nil {
+		go ctn.KeySet.Watch(watchCtx, cfg.JWTKeysReloadInterval)
+	}
+
+	// 3.3 Rotação dos certificados TLS (TLS_CERT_FILE / TLS_CLIENT_CA_FILE)
+	if ctn.TLS != nil {
+		go ctn.TLS.Watch(watchCtx, cfg.TLSReloadInterval)
+	}
+
+	// 3.4 Descoberta OIDC em background (retry com backoff + JWKS em cache)
+	for _, discovery := range ctn.Authenticator.Discoveries() {
+		go discovery.Run(watchCtx)
+	}
+
 	// 4. Configuração do Servidor (Rotas)
 	r := api.NewRouter(cfg, ctn)
 
@@ -55,11 +107,72 @@ func main() {
 		Addr:    cfg.Port,
 		Handler: r,
 	}
+	if ctn.TLS != nil {
+		srv.TLSConfig = ctn.TLS.Config()
+	}
+
+	// 5.1 gRPC (opcional): porta própria ou multiplexado na porta HTTP (h2c, ou h2 com TLS)
+	var grpcServer *grpc.Server
+	if cfg.GRPCEnabled {
+		var grpcOpts []grpc.ServerOption
+		if ctn.TLS != nil {
+			grpcOpts = append(grpcOpts, grpc.Creds(credentials.NewTLS(ctn.TLS.Config())))
+		}
+		grpcServer = grpcapi.NewServer(ctn.Authenticator, ctn.Enforcer, ctn.ProductService, grpcOpts...)
+
+		if cfg.GRPCPort == "" || cfg.GRPCPort == cfg.Port {
+			srv.Handler = grpcapi.Multiplex(grpcServer, r)
+			if ctn.TLS == nil {
+				srv.Protocols = new(http.Protocols)
+				srv.Protocols.SetHTTP1(true)
+				srv.Protocols.SetUnencryptedHTTP2(true)
+			}
+			slog.Info("gRPC multiplexado na porta HTTP", "port", cfg.Port, "tls", ctn.TLS != nil)
+		} else {
+			lis, err := net.Listen("tcp", cfg.GRPCPort)
+			if err != nil {
+				slog.Error("Erro ao abrir porta gRPC", "port", cfg.GRPCPort, "error", err)
+				os.Exit(1)
+			}
+			go func() {
+				slog.Info("Servidor gRPC iniciado", "port", cfg.GRPCPort)
+				if err := grpcServer.Serve(lis); err != nil {
+					slog.Error("Erro ao iniciar servidor gRPC", "error", err)
+					os.Exit(1)
+				}
+			}()
+		}
+	}
+
+	// 5.2 HTTP/3 (opcional): mesmo handler em UDP, anunciado via Alt-Svc nas respostas TCP
+	var h3Server *h3.Server
+	if cfg.HTTP3Enabled {
+		h3Server, err = h3.Listen(cfg.HTTP3Port, srv.Handler, ctn.TLS.Config())
+		if err != nil {
+			slog.Error("Erro ao iniciar HTTP/3", "port", cfg.HTTP3Port, "error", err)
+			os.Exit(1)
+		}
+		srv.Handler = h3Server.AltSvc(srv.Handler)
+		go func() {
+			slog.Info("Servidor HTTP/3 iniciado", "port", cfg.HTTP3Port)
+			if err := h3Server.Serve(); err != nil && err != http.ErrServerClosed {
+				slog.Error("Erro no servidor HTTP/3", "error", err)
+				os.Exit(1)
+			}
+		}()
+	}
 
 	// 6. Iniciar servidor em goroutine
 	go func() {
-		slog.Info("Servidor iniciado", "port", cfg.Port, "azure_enabled", cfg.AppInsightsConnectionString != "")
-		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		slog.Info("Servidor iniciado", "port", cfg.Port, "tls", ctn.TLS != nil, "azure_enabled", cfg.AppInsightsConnectionString != "")
+		var err error
+		if ctn.TLS != nil {
+			// Certificado vem de srv.TLSConfig (GetCertificate), com recarga em rotação
+			err = srv.ListenAndServeTLS("", "")
+		} else {
+			err = srv.ListenAndServe()
+		}
+		if err != nil && err != http.ErrServerClosed {
 			slog.Error("Erro ao iniciar servidor", "error", err)
 			os.Exit(1)
 		}
@@ -76,10 +189,63 @@ func main() {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
+	// Streams gRPC abertos mantêm a conexão viva; paramos o gRPC antes do HTTP
+	if grpcServer != nil {
+		grpcapi.Stop(ctx, grpcServer)
+	}
+
+	if h3Server != nil {
+		if err := h3Server.Shutdown(ctx); err != nil {
+			slog.Error("Erro ao desligar HTTP/3", "error", err)
+		}
+	}
+
+	exitCode := 0
 	if err := srv.Shutdown(ctx); err != nil {
 		slog.Error("Erro ao desligar servidor", "error", err)
-		os.Exit(1)
+		exitCode = 1
+	}
+
+	// Exporta os spans pendentes antes de sair
+	if err := shutdownTracing(ctx); err != nil {
+		slog.Error("Erro ao exportar spans pendentes", "error", err)
+	}
+
+	if exitCode == 0 {
+		slog.Info("Servidor desligado com sucesso")
+	}
+
+	// Por último: envia os logs e a telemetria ainda em buffer (App Insights)
+	flushCtx, cancelFlush := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancelFlush()
+	if err := logger.Shutdown(flushCtx); err != nil {
+		slog.Error("Erro ao enviar logs pendentes", "error", err)
+	}
+	if exitCode != 0 {
+		os.Exit(exitCode)
+	}
+}
+
+// logQueue configura as filas assíncronas dos logs (nil = síncrono, LOG_QUEUE_SIZE=0).
+func logQueue(cfg *config.Config) *logger.AsyncOptions {
+	if cfg.LogQueueSize == 0 {
+		return nil
+	}
+	return &logger.AsyncOptions{
+		QueueSize:    cfg.LogQueueSize,
+		Workers:      cfg.LogWorkers,
+		Overflow:     cfg.LogOverflow,
+		BlockTimeout: cfg.LogBlockTimeout,
 	}
+}
 
-	slog.Info("Servidor desligado com sucesso")
+// logSampler escolhe a amostragem dos logs enviados ao App Insights (nil = todos).
+func logSampler(cfg *config.Config) logger.Sampler {
+	switch {
+	case cfg.AppInsightsMaxLogsPerSecond > 0:
+		return logger.NewAdaptiveSampler(cfg.AppInsightsMaxLogsPerSecond)
+	case cfg.AppInsightsSamplingRate < 1:
+		return logger.FixedRateSampler{Rate: cfg.AppInsightsSamplingRate}
+	}
+	return nil
 }
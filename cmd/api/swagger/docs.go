@@ -19,85 +19,161 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/health": {
+        "/admin/api-keys": {
             "get": {
-                "description": "Retorna status 200 se a API estiver rodando",
+                "description": "Retorna as chaves do tenant do usuário (sem os segredos), com roles, expiração, último uso e revogação",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "sistema"
+                    "admin"
                 ],
-                "summary": "Verifica saúde da API",
+                "summary": "Lista API keys",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.APIKey"
                             }
                         }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
                     }
-                }
-            }
-        },
-        "/products": {
-            "get": {
+                },
                 "security": [
                     {
                         "BearerAuth": []
                     }
+                ]
+            },
+            "post": {
+                "description": "Emite uma chave para acesso serviço-a-serviço. O valor em texto puro só é retornado nesta resposta.",
+                "consumes": [
+                    "application/json"
                 ],
-                "description": "Retorna a lista de produtos com paginação",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "produtos"
+                    "admin"
                 ],
-                "summary": "Lista produtos",
+                "summary": "Cria uma API key",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "default": 1,
-                        "description": "Número da página",
-                        "name": "page",
-                        "in": "query"
+                        "description": "Dados da chave",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.CreateAPIKeyRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.CreateAPIKeyResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
                     },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/admin/api-keys/{id}": {
+            "delete": {
+                "description": "Invalida a chave imediatamente (idempotente). Só chaves do tenant do usuário.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Revoga uma API key",
+                "parameters": [
                     {
                         "type": "integer",
-                        "default": 10,
-                        "description": "Itens por página",
-                        "name": "page_size",
-                        "in": "query"
+                        "description": "ID da chave",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/handlers.ProductResponse"
-                            }
+                            "$ref": "#/definitions/handlers.MessageResponse"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/handlers.ErrorResponse"
                         }
                     }
-                }
-            },
-            "post": {
+                },
                 "security": [
                     {
                         "BearerAuth": []
                     }
+                ]
+            }
+        },
+        "/admin/log-level": {
+            "get": {
+                "description": "Nível mínimo do console e do App Insights e os overrides por pacote/rota ainda válidos",
+                "produces": [
+                    "application/json"
                 ],
-                "description": "Cria um novo produto no banco de dados",
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Níveis de log atuais",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.LogLevelResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            },
+            "put": {
+                "description": "Troca o nível mínimo do console e/ou do App Insights sem reiniciar. Campos omitidos ficam como estão. Não expira.",
                 "consumes": [
                     "application/json"
                 ],
@@ -105,17 +181,62 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "produtos"
+                    "admin"
                 ],
-                "summary": "Cria um produto",
+                "summary": "Altera o nível de log global",
                 "parameters": [
                     {
-                        "description": "Dados do Produto",
+                        "description": "Novos níveis",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/handlers.CreateProductRequest"
+                            "$ref": "#/definitions/handlers.SetLogLevelRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.LogLevelResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/admin/log-level/debug-tokens": {
+            "post": {
+                "description": "Gera o valor do header X-Debug-Log para um trace ID (ou um novo, se omitido). Requisições desse trace (header traceparent devolvido) que enviarem o header têm os logs de debug liberados até expires_in (default 5m, máx 24h).",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Token de debug por requisição",
+                "parameters": [
+                    {
+                        "description": "Trace e validade",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.DebugTokenRequest"
                         }
                     }
                 ],
@@ -123,7 +244,7 @@ const docTemplate = `{
                     "201": {
                         "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/handlers.MessageResponse"
+                            "$ref": "#/definitions/handlers.DebugTokenResponse"
                         }
                     },
                     "400": {
@@ -132,23 +253,23 @@ const docTemplate = `{
                             "$ref": "#/definitions/handlers.ErrorResponse"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "503": {
+                        "description": "Service Unavailable",
                         "schema": {
                             "$ref": "#/definitions/handlers.ErrorResponse"
                         }
                     }
-                }
-            }
-        },
-        "/products/{id}": {
-            "put": {
+                },
                 "security": [
                     {
                         "BearerAuth": []
                     }
-                ],
-                "description": "Atualiza o nome de um produto existente pelo ID",
+                ]
+            }
+        },
+        "/admin/log-level/overrides": {
+            "post": {
+                "description": "Define o nível mínimo dos logs de um pacote Go (ex: go-api-first-steps/internal/middleware) ou de uma rota (template do Gin, ex: /api/v1/products/:id, ou método gRPC) por expires_in (default 15m, máx 24h). Vale para todos os destinos.",
                 "consumes": [
                     "application/json"
                 ],
@@ -156,32 +277,25 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "produtos"
+                    "admin"
                 ],
-                "summary": "Atualiza um produto",
+                "summary": "Override de nível por pacote ou rota",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "ID do Produto",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Novos dados",
+                        "description": "Override",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/handlers.UpdateProductRequest"
+                            "$ref": "#/definitions/handlers.SetLogOverrideRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/handlers.MessageResponse"
+                            "$ref": "#/definitions/logger.Override"
                         }
                     },
                     "400": {
@@ -189,43 +303,101 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/handlers.ErrorResponse"
                         }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            },
+            "delete": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Remove override de nível",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "route",
+                        "description": "package ou route",
+                        "name": "scope",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "/api/v1/products",
+                        "description": "Pacote ou rota do override",
+                        "name": "name",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.MessageResponse"
+                        }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/handlers.ErrorResponse"
                         }
                     }
-                }
-            },
-            "delete": {
+                },
                 "security": [
                     {
                         "BearerAuth": []
                     }
-                ],
-                "description": "Remove um produto do banco pelo ID (Soft Delete)",
+                ]
+            }
+        },
+        "/admin/policy/explain": {
+            "get": {
+                "description": "Informa se um conjunto de roles (ou o próprio usuário, se roles for omitido) pode acessar a rota, com as roles e permissões efetivas e o motivo",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "produtos"
+                    "admin"
                 ],
-                "summary": "Deleta um produto",
+                "summary": "Explica a política de acesso",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "ID do Produto",
-                        "name": "id",
-                        "in": "path",
+                        "type": "string",
+                        "example": "PUT",
+                        "description": "Método HTTP (ou GRPC)",
+                        "name": "method",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "/api/v1/products/5",
+                        "description": "Caminho ou template da rota",
+                        "name": "path",
+                        "in": "query",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "develop,manager",
+                        "description": "Roles separadas por vírgula (default: roles do usuário)",
+                        "name": "roles",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/handlers.MessageResponse"
+                            "$ref": "#/definitions/authz.Decision"
                         }
                     },
                     "400": {
@@ -233,27 +405,613 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/handlers.ErrorResponse"
                         }
-                    },
-                    "500": {
-                        "description": "Internal Server Error",
-                        "schema": {
-                            "$ref": "#/definitions/handlers.ErrorResponse"
-                        }
                     }
-                }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
             }
-        }
-    },
-    "definitions": {
-        "handlers.CreateProductRequest": {
-            "type": "object",
-            "required": [
-                "name"
+        },
+        "/admin/tokens/revocations": {
+            "post": {
+                "description": "Adiciona o jti e/ou sid à lista de revogação. Tokens com esses identificadores passam a receber 401 até expires_in (default 1h, máx 24h).",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Revoga token ou sessão",
+                "parameters": [
+                    {
+                        "description": "Identificadores a revogar",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.RevokeTokenRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/health": {
+            "get": {
+                "description": "Retorna status 200 se a API estiver rodando",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "sistema"
+                ],
+                "summary": "Verifica saúde da API",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/me": {
+            "get": {
+                "description": "Retorna a identidade extraída do token e as operações da API que a política permite ao usuário, para a UI esconder ações que dariam 403. Regras por atributos (ex: só os próprios rascunhos) ainda podem negar um recurso específico.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "sistema"
+                ],
+                "summary": "Usuário autenticado",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.MeResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
+            }
+        },
+        "/products": {
+            "get": {
+                "description": "Retorna a lista de produtos com paginação",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "produtos"
+                ],
+                "summary": "Lista produtos",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Número da página",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Itens por página",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/handlers.ProductResponse"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
+            },
+            "post": {
+                "description": "Cria um novo produto no banco de dados",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "produtos"
+                ],
+                "summary": "Cria um produto",
+                "parameters": [
+                    {
+                        "description": "Dados do Produto",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.CreateProductRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Chave para retries seguros (replay da resposta original)",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
+            }
+        },
+        "/products/{id}": {
+            "put": {
+                "description": "Atualiza nome, categoria e status de um produto pelo ID. Além da rota, valem as regras por atributos (ex: o autor só edita os próprios rascunhos).",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "produtos"
+                ],
+                "summary": "Atualiza um produto",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID do Produto",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Novos dados",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.UpdateProductRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
+            },
+            "delete": {
+                "description": "Remove um produto do banco pelo ID (Soft Delete)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "produtos"
+                ],
+                "summary": "Deleta um produto",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID do Produto",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
+            }
+        },
+        "/ready": {
+            "get": {
+                "description": "Retorna 503 enquanto a descoberta OIDC de algum emissor não concluiu (sem chaves para validar tokens). Com JWKS em cache, responde 200 com status=cached.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "sistema"
+                ],
+                "summary": "Verifica prontidão da API",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ReadinessResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ReadinessResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "authz.Decision": {
+            "type": "object",
+            "properties": {
+                "allowed": {
+                    "type": "boolean"
+                },
+                "expression": {
+                    "type": "string"
+                },
+                "method": {
+                    "type": "string"
+                },
+                "permissions": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "roles": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "route": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.APIKey": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_used_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "prefix": {
+                    "type": "string"
+                },
+                "revoked_at": {
+                    "type": "string"
+                },
+                "roles": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "tenant": {
+                    "description": "Tenant em que o serviço atua (o de quem criou a chave)",
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.CreateAPIKeyRequest": {
+            "type": "object",
+            "required": [
+                "name",
+                "roles"
+            ],
+            "properties": {
+                "expires_in": {
+                    "description": "Vazio = sem expiração",
+                    "type": "string",
+                    "example": "720h"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "batch-estoque"
+                },
+                "roles": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "develop"
+                    ]
+                }
+            }
+        },
+        "handlers.CreateAPIKeyResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "key": {
+                    "type": "string",
+                    "example": "pak_1a2b3c4d5e6f_..."
+                },
+                "last_used_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "prefix": {
+                    "type": "string"
+                },
+                "revoked_at": {
+                    "type": "string"
+                },
+                "roles": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "tenant": {
+                    "description": "Tenant em que o serviço atua (o de quem criou a chave)",
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.CreateProductRequest": {
+            "type": "object",
+            "required": [
+                "name"
             ],
             "properties": {
+                "category": {
+                    "type": "string",
+                    "example": "monitores"
+                },
                 "name": {
                     "type": "string",
                     "example": "Monitor UltraWide"
+                },
+                "status": {
+                    "description": "Vazio = draft",
+                    "type": "string",
+                    "enum": [
+                        "draft",
+                        "published"
+                    ],
+                    "example": "draft"
+                }
+            }
+        },
+        "handlers.DebugTokenRequest": {
+            "type": "object",
+            "properties": {
+                "expires_in": {
+                    "type": "string",
+                    "example": "5m"
+                },
+                "trace_id": {
+                    "description": "Vazio = novo trace",
+                    "type": "string",
+                    "example": "4bf92f3577b34da6a3ce929d0e0e4736"
+                }
+            }
+        },
+        "handlers.DebugTokenResponse": {
+            "type": "object",
+            "properties": {
+                "expires_at": {
+                    "type": "string"
+                },
+                "header": {
+                    "type": "string",
+                    "example": "X-Debug-Log"
+                },
+                "token": {
+                    "type": "string",
+                    "example": "1767225600.9f86d0..."
+                },
+                "trace_id": {
+                    "type": "string",
+                    "example": "4bf92f3577b34da6a3ce929d0e0e4736"
+                },
+                "traceparent": {
+                    "type": "string",
+                    "example": "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
                 }
             }
         },
@@ -266,6 +1024,89 @@ const docTemplate = `{
                 }
             }
         },
+        "handlers.LogLevelResponse": {
+            "type": "object",
+            "properties": {
+                "appinsights": {
+                    "type": "string",
+                    "example": "WARN"
+                },
+                "console": {
+                    "type": "string",
+                    "example": "INFO"
+                },
+                "overrides": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/logger.Override"
+                    }
+                }
+            }
+        },
+        "handlers.MeResponse": {
+            "type": "object",
+            "properties": {
+                "effective_roles": {
+                    "description": "Com a hierarquia da política",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "develop"
+                    ]
+                },
+                "email": {
+                    "type": "string",
+                    "example": "ana@exemplo.com"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "f0c1..."
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Ana Souza"
+                },
+                "operations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handlers.Operation"
+                    }
+                },
+                "permissions": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "products:read"
+                    ]
+                },
+                "roles": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "develop"
+                    ]
+                },
+                "tenant": {
+                    "type": "string",
+                    "example": "varejo"
+                },
+                "type": {
+                    "description": "user ou service (API key)",
+                    "type": "string",
+                    "example": "user"
+                },
+                "username": {
+                    "type": "string",
+                    "example": "ana"
+                }
+            }
+        },
         "handlers.MessageResponse": {
             "type": "object",
             "properties": {
@@ -275,13 +1116,34 @@ const docTemplate = `{
                 }
             }
         },
+        "handlers.Operation": {
+            "type": "object",
+            "properties": {
+                "method": {
+                    "type": "string",
+                    "example": "PUT"
+                },
+                "path": {
+                    "type": "string",
+                    "example": "/api/v1/products/:id"
+                }
+            }
+        },
         "handlers.ProductResponse": {
             "type": "object",
             "properties": {
+                "category": {
+                    "type": "string",
+                    "example": "monitores"
+                },
                 "created_at": {
                     "type": "string",
                     "example": "2023-12-25T15:00:00Z"
                 },
+                "created_by": {
+                    "type": "string",
+                    "example": "f0c1..."
+                },
                 "id": {
                     "type": "integer",
                     "example": 1
@@ -289,6 +1151,87 @@ const docTemplate = `{
                 "name": {
                     "type": "string",
                     "example": "Monitor UltraWide"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "draft"
+                },
+                "updated_by": {
+                    "type": "string",
+                    "example": "f0c1..."
+                }
+            }
+        },
+        "handlers.ReadinessResponse": {
+            "type": "object",
+            "properties": {
+                "auth": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/middleware.AuthState"
+                    }
+                },
+                "status": {
+                    "type": "string",
+                    "example": "ok"
+                }
+            }
+        },
+        "handlers.RevokeTokenRequest": {
+            "type": "object",
+            "properties": {
+                "expires_in": {
+                    "description": "Até quando rejeitar (o exp restante do token)",
+                    "type": "string",
+                    "example": "1h"
+                },
+                "jti": {
+                    "type": "string",
+                    "example": "5f1c9e2a-..."
+                },
+                "sid": {
+                    "type": "string",
+                    "example": "b7d3..."
+                }
+            }
+        },
+        "handlers.SetLogLevelRequest": {
+            "type": "object",
+            "properties": {
+                "appinsights": {
+                    "type": "string",
+                    "example": "info"
+                },
+                "console": {
+                    "type": "string",
+                    "example": "debug"
+                }
+            }
+        },
+        "handlers.SetLogOverrideRequest": {
+            "type": "object",
+            "required": [
+                "level",
+                "name",
+                "scope"
+            ],
+            "properties": {
+                "expires_in": {
+                    "type": "string",
+                    "example": "15m"
+                },
+                "level": {
+                    "type": "string",
+                    "example": "debug"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "/api/v1/products/:id"
+                },
+                "scope": {
+                    "description": "package ou route",
+                    "type": "string",
+                    "example": "route"
                 }
             }
         },
@@ -298,14 +1241,73 @@ const docTemplate = `{
                 "name"
             ],
             "properties": {
+                "category": {
+                    "description": "Vazio = mantém",
+                    "type": "string",
+                    "example": "monitores"
+                },
                 "name": {
                     "type": "string",
                     "example": "Monitor UltraWide Pro"
+                },
+                "status": {
+                    "description": "Vazio = mantém",
+                    "type": "string",
+                    "enum": [
+                        "draft",
+                        "published"
+                    ],
+                    "example": "published"
+                }
+            }
+        },
+        "logger.Override": {
+            "type": "object",
+            "properties": {
+                "expires_at": {
+                    "type": "string"
+                },
+                "level": {
+                    "type": "string",
+                    "example": "DEBUG"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "/api/v1/products"
+                },
+                "scope": {
+                    "type": "string",
+                    "example": "route"
+                }
+            }
+        },
+        "middleware.AuthState": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
+                "issuer": {
+                    "type": "string"
+                },
+                "last_error": {
+                    "type": "string"
+                },
+                "ready_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
                 }
             }
         }
     },
     "securityDefinitions": {
+        "ApiKeyAuth": {
+            "type": "apiKey",
+            "name": "X-API-Key",
+            "in": "header"
+        },
         "BearerAuth": {
             "type": "apiKey",
             "name": "Authorization",
@@ -1,83 +1,73 @@
+// mock_token sobe um emissor OIDC local (mesmas rotas do Keycloak) para desenvolvimento.
+//
+//	go run ./cmd/mock_token                      # serve em :8180
+//	go run ./cmd/mock_token -once                # só imprime um token e sai
+//
+//	curl -d "sub=ana&realm_roles=manager" \
+//	  http://localhost:8180/realms/mock/protocol/openid-connect/token
 package main
 
 import (
-	"crypto/rand"
-	"crypto/rsa"
 	"crypto/x509"
-	"encoding/pem"
+	"encoding/base64"
+	"flag"
 	"fmt"
+	"log"
+	"net/http"
+	"strings"
 	"time"
 
-	"github.com/golang-jwt/jwt/v5"
+	"go-api-first-steps/internal/mockoidc"
 )
 
 func main() {
-	// 1. Gera um par de chaves RSA de 2048 bits
-	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
-	if err != nil {
-		panic(err)
-	}
-
-	// 2. Prepara a Chave Pública para o .env
-	pubKeyBytes, _ := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
-
-	pemBlock := pem.EncodeToMemory(&pem.Block{
-		Type:  "PUBLIC KEY",
-		Bytes: pubKeyBytes,
-	})
+	addr := flag.String("addr", ":8180", "endereço do servidor")
+	baseURL := flag.String("url", "", "URL pública do servidor (default: http://localhost<addr>)")
+	keyFile := flag.String("key", ".mock_oidc/key.pem", "arquivo da chave de assinatura (criado se não existir)")
+	roles := flag.String("roles", "admin,manager", "realm roles do token de exemplo")
+	once := flag.Bool("once", false, "apenas imprime o token de exemplo e sai")
+	flag.Parse()
 
-	pemStr := string(pemBlock)
-	lines := ""
-	for _, line := range splitLines(pemStr) {
-		if line != "-----BEGIN PUBLIC KEY-----" && line != "-----END PUBLIC KEY-----" && line != "" {
-			lines += line
+	if *baseURL == "" {
+		*baseURL = "http://localhost" + *addr
+		if !strings.HasPrefix(*addr, ":") {
+			*baseURL = "http://" + *addr
 		}
 	}
 
-	fmt.Println("=== 1. COPIE ISTO PARA SEU .ENV (KEYCLOAK_PUBLIC_KEY) ===")
-	fmt.Println(lines)
-	fmt.Println("=========================================================")
-	fmt.Println("")
-
-	// 3. Gera um Token JWT
-	claims := jwt.MapClaims{
-		"sub": "usuario-teste-id-123",
-		"realm_access": map[string]interface{}{
-			"roles": []string{"admin", "manager"},
-		},
-		"exp": time.Now().Add(time.Hour * 24).Unix(),
-		"iat": time.Now().Unix(),
+	provider, err := mockoidc.New(*baseURL, *keyFile)
+	if err != nil {
+		log.Fatal(err)
 	}
 
-	// --- CORREÇÃO AQUI ---
-	// Removemos o 'privateKey' desta função
-	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
-
-	// A chave privada entra APENAS aqui, na hora de assinar
-	tokenString, err := token.SignedString(privateKey)
+	token, err := provider.Token(mockoidc.TokenRequest{
+		RealmRoles: strings.Split(*roles, ","),
+		ExpiresIn:  24 * time.Hour,
+	})
 	if err != nil {
-		panic(err)
+		log.Fatal(err)
 	}
 
+	pubKey, _ := x509.MarshalPKIXPublicKey(provider.PublicKey())
+
+	fmt.Println("=== 1. COPIE ISTO PARA SEU .ENV ===")
+	fmt.Printf("KEYCLOAK_URL=%s\n", provider.Issuer())
+	fmt.Printf("KEYCLOAK_CLIENT_ID=%s\n", mockoidc.DefaultClientID)
+	fmt.Println("ROLE_SOURCES=client,realm # o token de exemplo usa realm roles")
+	fmt.Println("# ou, sem rede (AUTH_MODE=offline):")
+	fmt.Printf("KEYCLOAK_PUBLIC_KEY=%s\n", base64.StdEncoding.EncodeToString(pubKey))
+	fmt.Println("===================================")
+	fmt.Println("")
 	fmt.Println("=== 2. USE ESTE TOKEN NO SWAGGER OU POSTMAN ===")
-	fmt.Printf("Bearer %s\n", tokenString)
+	fmt.Printf("Bearer %s\n", token)
 	fmt.Println("===============================================")
-}
 
-// splitLines ajuda a limpar a string PEM
-func splitLines(s string) []string {
-	var lines []string
-	var currentLine string
-	for _, char := range s {
-		if char == '\n' {
-			lines = append(lines, currentLine)
-			currentLine = ""
-		} else {
-			currentLine += string(char)
-		}
+	if *once {
+		return
 	}
-	if currentLine != "" {
-		lines = append(lines, currentLine)
-	}
-	return lines
+
+	fmt.Printf("\nEmissor OIDC mock ouvindo em %s (token endpoint: %s/protocol/openid-connect/token)\n",
+		*addr, provider.Issuer())
+	srv := &http.Server{Addr: *addr, Handler: provider.Handler(), ReadHeaderTimeout: 5 * time.Second}
+	log.Fatal(srv.ListenAndServe())
 }
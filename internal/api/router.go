@@ -1,13 +1,12 @@
 package api
 
 import (
-	"context"
 	v1 "go-api-first-steps/internal/api/v1"
 	"go-api-first-steps/internal/config"
 	"go-api-first-steps/internal/dependencies"
 	"go-api-first-steps/internal/handlers"
+	"go-api-first-steps/internal/metrics"
 	"go-api-first-steps/internal/middleware"
-	"log/slog"
 
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
@@ -19,34 +18,19 @@ import (
 // Ele registra:
 //   - Middleware de Logger e Recovery.
 //   - Rotas do Swagger UI.
-//   - Rotas de Health Check.
+//   - Rotas de Health Check e métricas (/metrics).
 //   - Grupos de API versionados (ex: /api/v1).
 func NewRouter(cfg *config.Config, ctn *dependencies.Container) *gin.Engine {
 	// Logger Configuration
 	r := gin.New()
-	r.Use(gin.Recovery())
+	// Só proxies confiáveis definem o IP do cliente (rate limit por IP, logs); sem
+	// eles o X-Forwarded-For é ignorado. Os valores já foram validados em config.Load.
+	_ = r.SetTrustedProxies(cfg.TrustedProxies)
+	r.Use(middleware.Tracing())
+	r.Use(middleware.DebugLog(cfg.LogDebugSecret))
 	r.Use(middleware.RequestLogger())
-
-	// Initialize OIDC Authenticator
-	var authenticator *middleware.Authenticator
-	var err error
-
-	if cfg.DevMode {
-		slog.WarnContext(
-			context.Background(),
-			"⚠️  DevMode ativo - usando autenticador de desenvolvimento (sem validação real)")
-		authenticator = middleware.NewDevAuthenticator()
-	} else {
-		authenticator, err = middleware.NewAuthenticator(cfg)
-		if err != nil {
-			slog.WarnContext(
-				context.Background(),
-				"Failed to initialize Authenticator",
-				"error", err)
-			// Log error but allow startup. Auth middleware will return 500 if verifier is missing.
-			// In production, you might want to os.Exit(1) here.
-		}
-	}
+	r.Use(middleware.Metrics())
+	r.Use(middleware.Recovery())
 
 	// Swagger
 	r.GET("/swagger", func(c *gin.Context) {
@@ -54,12 +38,29 @@ func NewRouter(cfg *config.Config, ctn *dependencies.Container) *gin.Engine {
 	})
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 	r.GET("/health", handlers.HealthCheck)
+	r.GET("/ready", ctn.ReadinessHandler.Ready)
+	if cfg.MetricsEnabled {
+		r.GET("/metrics", gin.WrapH(metrics.Default.Handler()))
+	}
 
 	// API V1 Config
 	apiV1 := r.Group("/api/v1")
 	{
 		// Pass dependencies to V1 router
-		v1.RegisterRoutes(apiV1, authenticator, ctn.ProductHandler)
+		v1.RegisterRoutes(apiV1, v1.Middlewares{
+			Auth:        ctn.Authenticator,
+			Policy:      ctn.Enforcer,
+			Idempotency: middleware.Idempotency(ctn.IdempotencyStore, cfg.IdempotencyTTL, cfg.IdempotencyMaxBodyBytes),
+			RateLimiter: ctn.RateLimiter,
+		}, v1.Handlers{
+			Product:  ctn.ProductHandler,
+			Policy:   ctn.PolicyHandler,
+			APIKey:   ctn.APIKeyHandler,
+			Token:    ctn.TokenHandler,
+			LogLevel: ctn.LogLevelHandler,
+			// Criado aqui, e não no container, porque lista as rotas do próprio engine
+			Me: &handlers.MeHandler{Enforcer: ctn.Enforcer, Routes: r.Routes, BasePath: apiV1.BasePath()},
+		})
 	}
 
 	return r
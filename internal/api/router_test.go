@@ -0,0 +1,431 @@
+package api_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go-api-first-steps/internal/api"
+	"go-api-first-steps/internal/config"
+	"go-api-first-steps/internal/dependencies"
+	"go-api-first-steps/internal/handlers"
+	"go-api-first-steps/internal/mockoidc"
+	"go-api-first-steps/internal/tracing"
+	"go-api-first-steps/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// setupRouter sobe o router real autenticando contra o emissor OIDC mock (sem DevMode).
+// opts ajustam a configuração antes de montar o container.
+func setupRouter(t *testing.T, opts ...func(*config.Config)) (*gin.Engine, *mockoidc.TestServer) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	idp := mockoidc.Start(t)
+	cfg := &config.Config{
+		DBUrl:       ":memory:",
+		AuthMode:    "oidc",
+		KeycloakURL: idp.Issuer(),
+		ClientID:    mockoidc.DefaultClientID,
+		RoleSources: "client,realm",
+		RoleMerge:   "union",
+
+		IdempotencyMaxBodyBytes: 1 << 20,
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	ctn := dependencies.NewContainer(cfg)
+	require.NotNil(t, ctn.Authenticator.Verifier, "discovery no emissor mock falhou")
+
+	return api.NewRouter(cfg, ctn), idp
+}
+
+func do(r http.Handler, method, path, token, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestRouter_RealAuthentication(t *testing.T) {
+	r, idp := setupRouter(t)
+
+	develop := idp.MustToken(t, mockoidc.TokenRequest{Subject: "dev-1", ClientRoles: []string{"develop"}})
+	admin := idp.MustToken(t, mockoidc.TokenRequest{Subject: "adm-1", RealmRoles: []string{"admin"}})
+	otherClient := idp.MustToken(t, mockoidc.TokenRequest{ClientID: "outro-client", RealmRoles: []string{"admin"}})
+
+	assert.Equal(t, http.StatusUnauthorized, do(r, http.MethodGet, "/api/v1/products", "", "").Code)
+	assert.Equal(t, http.StatusUnauthorized, do(r, http.MethodGet, "/api/v1/products", otherClient, "").Code)
+	assert.Equal(t, http.StatusOK, do(r, http.MethodGet, "/api/v1/products", develop, "").Code)
+
+	assert.Equal(t, http.StatusCreated, do(r, http.MethodPost, "/api/v1/products", develop, `{"name":"Mouse"}`).Code)
+	assert.Equal(t, http.StatusForbidden, do(r, http.MethodDelete, "/api/v1/products/1", develop, "").Code)
+	assert.Equal(t, http.StatusOK, do(r, http.MethodDelete, "/api/v1/products/1", admin, "").Code)
+}
+
+func TestRouter_APIKeys(t *testing.T) {
+	r, idp := setupRouter(t)
+	admin := idp.MustToken(t, mockoidc.TokenRequest{Subject: "adm-1", RealmRoles: []string{"admin"}})
+	develop := idp.MustToken(t, mockoidc.TokenRequest{ClientRoles: []string{"develop"}})
+
+	// Só admin gerencia chaves
+	assert.Equal(t, http.StatusForbidden,
+		do(r, http.MethodPost, "/api/v1/admin/api-keys", develop, `{"name":"job","roles":["develop"]}`).Code)
+
+	w := do(r, http.MethodPost, "/api/v1/admin/api-keys", admin, `{"name":"job","roles":["develop"],"expires_in":"24h"}`)
+	require.Equal(t, http.StatusCreated, w.Code)
+	var created struct {
+		ID        uint   `json:"id"`
+		Key       string `json:"key"`
+		CreatedBy string `json:"created_by"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	assert.Equal(t, "adm-1", created.CreatedBy)
+
+	withKey := func(method, path string, header, value string) int {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set(header, value)
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	// A chave passa pelas mesmas regras da política que um usuário develop
+	assert.Equal(t, http.StatusOK, withKey(http.MethodGet, "/api/v1/products", "X-API-Key", created.Key))
+	assert.Equal(t, http.StatusOK, withKey(http.MethodGet, "/api/v1/products", "Authorization", "ApiKey "+created.Key))
+	assert.Equal(t, http.StatusForbidden, withKey(http.MethodDelete, "/api/v1/products/1", "X-API-Key", created.Key))
+	assert.Equal(t, http.StatusUnauthorized, withKey(http.MethodGet, "/api/v1/products", "X-API-Key", "pak_000000000000_x"))
+
+	// A listagem não expõe o segredo
+	w = do(r, http.MethodGet, "/api/v1/admin/api-keys", admin, "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), created.Key)
+	assert.Contains(t, w.Body.String(), `"last_used_at"`)
+
+	path := fmt.Sprintf("/api/v1/admin/api-keys/%d", created.ID)
+	assert.Equal(t, http.StatusOK, do(r, http.MethodDelete, path, admin, "").Code)
+	assert.Equal(t, http.StatusUnauthorized, withKey(http.MethodGet, "/api/v1/products", "X-API-Key", created.Key))
+}
+
+func TestRouter_RevokeSession(t *testing.T) {
+	r, idp := setupRouter(t)
+	admin := idp.MustToken(t, mockoidc.TokenRequest{Subject: "adm-1", RealmRoles: []string{"admin"}})
+	user := idp.MustToken(t, mockoidc.TokenRequest{SessionID: "sessao-ana", ClientRoles: []string{"develop"}})
+
+	assert.Equal(t, http.StatusOK, do(r, http.MethodGet, "/api/v1/products", user, "").Code)
+
+	assert.Equal(t, http.StatusBadRequest, do(r, http.MethodPost, "/api/v1/admin/tokens/revocations", admin, `{}`).Code)
+	assert.Equal(t, http.StatusForbidden,
+		do(r, http.MethodPost, "/api/v1/admin/tokens/revocations", user, `{"sid":"sessao-ana"}`).Code)
+	assert.Equal(t, http.StatusOK,
+		do(r, http.MethodPost, "/api/v1/admin/tokens/revocations", admin, `{"sid":"sessao-ana","expires_in":"1h"}`).Code)
+
+	assert.Equal(t, http.StatusUnauthorized, do(r, http.MethodGet, "/api/v1/products", user, "").Code)
+}
+
+func TestRouter_TenantIsolation(t *testing.T) {
+	r, idp := setupRouter(t, func(cfg *config.Config) { cfg.TenantClaim = "tenant" })
+	tokenFor := func(tenant string, roles ...string) string {
+		return idp.MustToken(t, mockoidc.TokenRequest{RealmRoles: roles, Extra: map[string]any{"tenant": tenant}})
+	}
+	varejo, atacado := tokenFor("varejo", "develop"), tokenFor("atacado", "admin")
+
+	// Nome único por tenant, não global
+	require.Equal(t, http.StatusCreated, do(r, http.MethodPost, "/api/v1/products", varejo, `{"name":"Mouse"}`).Code)
+	assert.Equal(t, http.StatusCreated, do(r, http.MethodPost, "/api/v1/products", atacado, `{"name":"Mouse"}`).Code)
+	assert.Equal(t, http.StatusConflict, do(r, http.MethodPost, "/api/v1/products", varejo, `{"name":"Mouse"}`).Code)
+
+	list := func(token string) []map[string]any {
+		w := do(r, http.MethodGet, "/api/v1/products", token, "")
+		require.Equal(t, http.StatusOK, w.Code)
+		var products []map[string]any
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &products))
+		return products
+	}
+	require.Len(t, list(varejo), 1)
+	varejoID := list(varejo)[0]["id"]
+	require.Len(t, list(atacado), 1)
+	assert.NotEqual(t, varejoID, list(atacado)[0]["id"])
+
+	// Escritas no produto de outro tenant não o alcançam
+	path := fmt.Sprintf("/api/v1/products/%v", varejoID)
+	assert.Equal(t, http.StatusNotFound, do(r, http.MethodPut, path, atacado, `{"name":"Hackeado"}`).Code)
+	do(r, http.MethodDelete, path, atacado, "")
+	products := list(varejo)
+	require.Len(t, products, 1)
+	assert.Equal(t, "Mouse", products[0]["name"])
+
+	// Com TENANT_CLAIM configurada, token sem tenant é rejeitado
+	semTenant := idp.MustToken(t, mockoidc.TokenRequest{RealmRoles: []string{"develop"}})
+	assert.Equal(t, http.StatusUnauthorized, do(r, http.MethodGet, "/api/v1/products", semTenant, "").Code)
+}
+
+func TestRouter_ProductOwnership(t *testing.T) {
+	r, idp := setupRouter(t, func(cfg *config.Config) { cfg.UserAttributeClaims = "categories" })
+	ana := idp.MustToken(t, mockoidc.TokenRequest{Subject: "ana", ClientRoles: []string{"develop"}})
+	bia := idp.MustToken(t, mockoidc.TokenRequest{Subject: "bia", ClientRoles: []string{"develop"}})
+	gerente := idp.MustToken(t, mockoidc.TokenRequest{Subject: "gil", ClientRoles: []string{"manager"},
+		Extra: map[string]any{"categories": []string{"monitores"}}})
+	outroGerente := idp.MustToken(t, mockoidc.TokenRequest{Subject: "leo", ClientRoles: []string{"manager"},
+		Extra: map[string]any{"categories": []string{"mouses"}}})
+
+	require.Equal(t, http.StatusCreated,
+		do(r, http.MethodPost, "/api/v1/products", ana, `{"name":"Monitor","category":"monitores"}`).Code)
+
+	// Rascunho: o autor edita, outro develop não; publicar cabe ao manager da categoria
+	assert.Equal(t, http.StatusForbidden, do(r, http.MethodPut, "/api/v1/products/1", bia, `{"name":"B"}`).Code)
+	assert.Equal(t, http.StatusOK, do(r, http.MethodPut, "/api/v1/products/1", ana, `{"name":"Monitor 4K"}`).Code)
+	assert.Equal(t, http.StatusForbidden,
+		do(r, http.MethodPut, "/api/v1/products/1", ana, `{"name":"Monitor 4K","status":"published"}`).Code)
+	assert.Equal(t, http.StatusForbidden,
+		do(r, http.MethodPut, "/api/v1/products/1", gerente, `{"name":"Monitor 4K","category":"mouses"}`).Code)
+	assert.Equal(t, http.StatusOK,
+		do(r, http.MethodPut, "/api/v1/products/1", gerente, `{"name":"Monitor 4K","status":"published"}`).Code)
+
+	// Publicado: o autor não edita mais; só o manager da categoria
+	assert.Equal(t, http.StatusForbidden, do(r, http.MethodPut, "/api/v1/products/1", ana, `{"name":"A"}`).Code)
+	assert.Equal(t, http.StatusForbidden, do(r, http.MethodPut, "/api/v1/products/1", outroGerente, `{"name":"L"}`).Code)
+	assert.Equal(t, http.StatusOK, do(r, http.MethodPut, "/api/v1/products/1", gerente, `{"name":"Monitor 5K"}`).Code)
+
+	w := do(r, http.MethodGet, "/api/v1/products", ana, "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var products []map[string]any
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &products))
+	require.Len(t, products, 1)
+	assert.Equal(t, "Monitor 5K", products[0]["name"])
+	assert.Equal(t, "ana", products[0]["created_by"])
+	assert.Equal(t, "gil", products[0]["updated_by"])
+}
+
+func TestRouter_Me(t *testing.T) {
+	r, idp := setupRouter(t)
+
+	assert.Equal(t, http.StatusUnauthorized, do(r, http.MethodGet, "/api/v1/me", "", "").Code)
+
+	me := func(token string) handlers.MeResponse {
+		w := do(r, http.MethodGet, "/api/v1/me", token, "")
+		require.Equal(t, http.StatusOK, w.Code)
+		var resp handlers.MeResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		return resp
+	}
+
+	develop := me(idp.MustToken(t, mockoidc.TokenRequest{
+		Subject: "ana", Name: "Ana", Email: "ana@exemplo.com", Username: "ana", ClientRoles: []string{"develop"},
+	}))
+	assert.Equal(t, "ana", develop.ID)
+	assert.Equal(t, "ana@exemplo.com", develop.Email)
+	assert.Equal(t, []string{"develop"}, develop.Roles)
+	assert.Contains(t, develop.Permissions, "products:read")
+	assert.Equal(t, []handlers.Operation{
+		{Method: http.MethodGet, Path: "/api/v1/products"},
+		{Method: http.MethodPost, Path: "/api/v1/products"},
+		{Method: http.MethodPut, Path: "/api/v1/products/:id"},
+	}, develop.Operations)
+
+	// admin herda tudo: vê as rotas de admin e o DELETE
+	admin := me(idp.MustToken(t, mockoidc.TokenRequest{Subject: "root", RealmRoles: []string{"admin"}}))
+	assert.Equal(t, []string{"admin", "develop", "manager"}, admin.EffectiveRoles)
+	assert.Contains(t, admin.Operations, handlers.Operation{Method: http.MethodDelete, Path: "/api/v1/products/:id"})
+	assert.Contains(t, admin.Operations, handlers.Operation{Method: http.MethodGet, Path: "/api/v1/admin/api-keys"})
+
+	// Sem roles: autenticado, mas sem operações
+	none := me(idp.MustToken(t, mockoidc.TokenRequest{Subject: "zé"}))
+	assert.Empty(t, none.Operations)
+}
+
+func TestRouter_Metrics(t *testing.T) {
+	r, idp := setupRouter(t, func(cfg *config.Config) { cfg.MetricsEnabled = true })
+	develop := idp.MustToken(t, mockoidc.TokenRequest{ClientRoles: []string{"develop"}})
+
+	assert.Equal(t, http.StatusOK, do(r, http.MethodGet, "/api/v1/products", develop, "").Code)
+	assert.Equal(t, http.StatusUnauthorized, do(r, http.MethodGet, "/api/v1/products", "", "").Code)
+	assert.Equal(t, http.StatusForbidden, do(r, http.MethodDelete, "/api/v1/products/424242", develop, "").Code)
+	assert.Equal(t, http.StatusNotFound, do(r, http.MethodGet, "/nao-existe/424242", "", "").Code)
+
+	w := do(r, http.MethodGet, "/metrics", "", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Type"), "text/plain; version=0.0.4")
+	body := w.Body.String()
+
+	// Rota pelo template, nunca pelo path com o ID
+	assert.Contains(t, body, `http_requests_total{method="DELETE",route="/api/v1/products/:id",status="403"}`)
+	assert.Contains(t, body, `http_request_duration_seconds_bucket{method="GET",route="/api/v1/products",status="200",le="+Inf"}`)
+	assert.Contains(t, body, `http_requests_total{method="GET",route="unmatched",status="404"}`)
+	assert.NotContains(t, body, "424242")
+	assert.Contains(t, body, `auth_attempts_total{transport="http",credential="none",outcome="missing"}`)
+	assert.Contains(t, body, `auth_attempts_total{transport="http",credential="token",outcome="success"}`)
+	assert.Contains(t, body, `authz_decisions_total{transport="http",decision="denied"}`)
+	assert.Contains(t, body, `db_query_duration_seconds_count{operation="query",table="products"}`)
+	assert.Contains(t, body, "# TYPE http_requests_in_flight gauge")
+	assert.Contains(t, body, "go_goroutines ")
+}
+
+func TestRouter_Tracing(t *testing.T) {
+	spans := tracing.StartInMemory(t)
+	r, idp := setupRouter(t)
+	develop := idp.MustToken(t, mockoidc.TokenRequest{ClientRoles: []string{"develop"}})
+	spans.Reset() // descarta os spans das migrações
+
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/products", nil)
+	req.Header.Set("Authorization", "Bearer "+develop)
+	req.Header.Set("Traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	assert.Equal(t, traceID, w.Header().Get("X-Trace-ID"))
+	assert.Contains(t, w.Header().Get("Traceparent"), traceID)
+
+	byName := map[string]tracetest.SpanStub{}
+	for _, s := range spans.GetSpans() {
+		// O JWKS é buscado fora da requisição (cache do go-oidc) e tem trace próprio
+		if s.SpanContext.TraceID().String() == traceID {
+			byName[s.Name] = s
+		}
+	}
+	for _, name := range []string{"GET /api/v1/products", "auth.verify", "product.ListProducts", "db.query"} {
+		assert.Contains(t, byName, name)
+	}
+	assert.Equal(t, byName["GET /api/v1/products"].SpanContext.SpanID(), byName["product.ListProducts"].Parent.SpanID())
+	assert.Equal(t, byName["product.ListProducts"].SpanContext.SpanID(), byName["db.query"].Parent.SpanID())
+
+	// Cliente legado: o X-Trace-ID volta como foi enviado
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/products", nil)
+	req.Header.Set("Authorization", "Bearer "+develop)
+	req.Header.Set("X-Trace-ID", "trace-abc")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, "trace-abc", w.Header().Get("X-Trace-ID"))
+}
+
+func TestRouter_LogLevel(t *testing.T) {
+	r, idp := setupRouter(t, func(cfg *config.Config) { cfg.LogDebugSecret = []byte("segredo-debug") })
+	admin := idp.MustToken(t, mockoidc.TokenRequest{Subject: "adm-1", RealmRoles: []string{"admin"}})
+	develop := idp.MustToken(t, mockoidc.TokenRequest{ClientRoles: []string{"develop"}})
+	levels := logger.Controller()
+	console := levels.Console.Level()
+	t.Cleanup(func() {
+		levels.Console.Set(console)
+		levels.RemoveOverride(logger.ScopeRoute, "/api/v1/products")
+	})
+
+	assert.Equal(t, http.StatusForbidden, do(r, http.MethodGet, "/api/v1/admin/log-level", develop, "").Code)
+
+	assert.Equal(t, http.StatusBadRequest, do(r, http.MethodPut, "/api/v1/admin/log-level", admin, `{"console":"verbose"}`).Code)
+	w := do(r, http.MethodPut, "/api/v1/admin/log-level", admin, `{"console":"debug"}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, slog.LevelDebug, levels.Console.Level())
+
+	w = do(r, http.MethodPost, "/api/v1/admin/log-level/overrides", admin,
+		`{"scope":"route","name":"/api/v1/products","level":"debug","expires_in":"10m"}`)
+	require.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, http.StatusBadRequest, do(r, http.MethodPost, "/api/v1/admin/log-level/overrides", admin,
+		`{"scope":"route","name":"/api/v1/products","level":"debug","expires_in":"48h"}`).Code)
+
+	var resp handlers.LogLevelResponse
+	w = do(r, http.MethodGet, "/api/v1/admin/log-level", admin, "")
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "DEBUG", resp.Console)
+	require.Len(t, resp.Overrides, 1)
+	assert.Equal(t, "/api/v1/products", resp.Overrides[0].Name)
+
+	path := "/api/v1/admin/log-level/overrides?scope=route&name=/api/v1/products"
+	assert.Equal(t, http.StatusOK, do(r, http.MethodDelete, path, admin, "").Code)
+	assert.Equal(t, http.StatusNotFound, do(r, http.MethodDelete, path, admin, "").Code)
+
+	// Token de debug: vale para o trace pedido
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	w = do(r, http.MethodPost, "/api/v1/admin/log-level/debug-tokens", admin, `{"trace_id":"`+traceID+`"}`)
+	require.Equal(t, http.StatusCreated, w.Code)
+	var token handlers.DebugTokenResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &token))
+	assert.Equal(t, traceID, token.TraceID)
+	assert.Contains(t, token.Traceparent, traceID)
+	assert.Equal(t, "X-Debug-Log", token.Header)
+	assert.NotEmpty(t, token.Token)
+	assert.Equal(t, http.StatusBadRequest,
+		do(r, http.MethodPost, "/api/v1/admin/log-level/debug-tokens", admin, `{"trace_id":"abc"}`).Code)
+}
+
+// O mesmo sub em dois tenants não compartilha respostas idempotentes nem API keys.
+func TestRouter_RateLimitIgnoresSpoofedForwardedFor(t *testing.T) {
+	rateLimitByIP := func(cfg *config.Config) {
+		cfg.RateLimit = config.RateLimitConfig{Enabled: true, KeyBy: "ip", Default: config.RateLimitRule{RPS: 0.01, Burst: 1}}
+	}
+	getFrom := func(r http.Handler, token, forwardedFor string) int {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/products", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	// Sem TRUSTED_PROXIES, trocar o X-Forwarded-For não dá um bucket novo
+	r, idp := setupRouter(t, rateLimitByIP)
+	token := idp.MustToken(t, mockoidc.TokenRequest{Subject: "ana", ClientRoles: []string{"develop"}})
+	assert.Equal(t, http.StatusOK, getFrom(r, token, "203.0.113.1"))
+	assert.Equal(t, http.StatusTooManyRequests, getFrom(r, token, "203.0.113.2"))
+
+	// Atrás de um proxy confiável (o RemoteAddr do httptest), o header identifica o cliente
+	r, idp = setupRouter(t, rateLimitByIP, func(cfg *config.Config) { cfg.TrustedProxies = []string{"192.0.2.0/24"} })
+	token = idp.MustToken(t, mockoidc.TokenRequest{Subject: "ana", ClientRoles: []string{"develop"}})
+	assert.Equal(t, http.StatusOK, getFrom(r, token, "203.0.113.1"))
+	assert.Equal(t, http.StatusOK, getFrom(r, token, "203.0.113.2"))
+	assert.Equal(t, http.StatusTooManyRequests, getFrom(r, token, "203.0.113.1"))
+}
+
+func TestRouter_TenantIsolation_IdempotencyAndAPIKeys(t *testing.T) {
+	r, idp := setupRouter(t, func(cfg *config.Config) { cfg.TenantClaim = "tenant" })
+	tokenFor := func(tenant string) string {
+		return idp.MustToken(t, mockoidc.TokenRequest{Subject: "ana", RealmRoles: []string{"admin"}, Extra: map[string]any{"tenant": tenant}})
+	}
+	varejo, atacado := tokenFor("varejo"), tokenFor("atacado")
+
+	create := func(token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/products", bytes.NewBufferString(`{"name":"Mouse"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("Idempotency-Key", "pedido-1")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	require.Equal(t, http.StatusCreated, create(varejo).Code)
+	w := create(atacado)
+	require.Equal(t, http.StatusCreated, w.Code)
+	assert.Empty(t, w.Header().Get("Idempotent-Replayed"), "resposta do outro tenant não é reaproveitada")
+	w = do(r, http.MethodGet, "/api/v1/products", atacado, "")
+	assert.Contains(t, w.Body.String(), `"name":"Mouse"`, "o atacado criou o próprio produto")
+
+	w = do(r, http.MethodPost, "/api/v1/admin/api-keys", varejo, `{"name":"job","roles":["develop"]}`)
+	require.Equal(t, http.StatusCreated, w.Code)
+	var created struct {
+		ID uint `json:"id"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+
+	w = do(r, http.MethodGet, "/api/v1/admin/api-keys", atacado, "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `[]`, w.Body.String())
+	path := fmt.Sprintf("/api/v1/admin/api-keys/%d", created.ID)
+	assert.Equal(t, http.StatusNotFound, do(r, http.MethodDelete, path, atacado, "").Code)
+
+	w = do(r, http.MethodGet, "/api/v1/admin/api-keys", varejo, "")
+	assert.NotContains(t, w.Body.String(), `"revoked_at":"`, "a chave do varejo continua ativa")
+}
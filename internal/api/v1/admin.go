@@ -0,0 +1,26 @@
+package v1
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+func registerAdminRoutes(router *gin.RouterGroup, mw Middlewares, h Handlers) {
+	authorize := mw.Auth.CheckPolicy(mw.Policy)
+
+	admin := router.Group("/admin")
+	{
+		admin.GET("/policy/explain", authorize, h.Policy.Explain)
+
+		admin.GET("/api-keys", authorize, h.APIKey.List)
+		admin.POST("/api-keys", authorize, h.APIKey.Create)
+		admin.DELETE("/api-keys/:id", authorize, h.APIKey.Revoke)
+
+		admin.POST("/tokens/revocations", authorize, h.Token.Revoke)
+
+		admin.GET("/log-level", authorize, h.LogLevel.Get)
+		admin.PUT("/log-level", authorize, h.LogLevel.Set)
+		admin.POST("/log-level/overrides", authorize, h.LogLevel.SetOverride)
+		admin.DELETE("/log-level/overrides", authorize, h.LogLevel.RemoveOverride)
+		admin.POST("/log-level/debug-tokens", authorize, h.LogLevel.CreateDebugToken)
+	}
+}
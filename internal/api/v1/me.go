@@ -0,0 +1,12 @@
+package v1
+
+import (
+	"go-api-first-steps/internal/handlers"
+
+	"github.com/gin-gonic/gin"
+)
+
+// /me só exige autenticação: qualquer usuário pode consultar a si mesmo
+func registerMeRoutes(router *gin.RouterGroup, mw Middlewares, h *handlers.MeHandler) {
+	router.GET("/me", mw.Auth.CheckMiddleware("OR"), h.Me)
+}
@@ -2,17 +2,20 @@ package v1
 
 import (
 	"go-api-first-steps/internal/handlers"
-	"go-api-first-steps/internal/middleware"
 
 	"github.com/gin-gonic/gin"
 )
 
-func registerProductRoutes(router *gin.RouterGroup, auth *middleware.Authenticator, h *handlers.ProductHandler) {
+// As permissões de cada rota ficam na política (internal/authz/default_policy.yaml ou POLICY_FILE)
+func registerProductRoutes(router *gin.RouterGroup, mw Middlewares, h *handlers.ProductHandler) {
+	authorize := mw.Auth.CheckPolicy(mw.Policy)
+	limit := mw.RateLimiter.Middleware("products")
+
 	products := router.Group("/products")
 	{
-		products.GET("", auth.CheckMiddleware("OR", "develop"), h.List)
-		products.POST("", auth.CheckMiddleware("OR", "develop"), h.Create)
-		products.PUT("/:id", auth.CheckMiddleware("OR", "manager"), h.Update)
-		products.DELETE("/:id", auth.CheckMiddleware("OR", "admin"), h.Delete)
+		products.GET("", authorize, limit, h.List)
+		products.POST("", authorize, limit, mw.Idempotency, h.Create)
+		products.PUT("/:id", authorize, limit, h.Update)
+		products.DELETE("/:id", authorize, limit, h.Delete)
 	}
 }
@@ -1,13 +1,38 @@
 package v1
 
 import (
+	"go-api-first-steps/internal/authz"
 	"go-api-first-steps/internal/handlers"
 	"go-api-first-steps/internal/middleware"
 
 	"github.com/gin-gonic/gin"
 )
 
-func RegisterRoutes(router *gin.RouterGroup, auth *middleware.Authenticator, productHandler *handlers.ProductHandler) {
+// Middlewares agrupa os middlewares compartilhados pelas rotas da v1.
+type Middlewares struct {
+	Auth        *middleware.Authenticator
+	Policy      *authz.Enforcer
+	Idempotency gin.HandlerFunc
+	RateLimiter *middleware.RateLimiter
+}
+
+// Handlers agrupa os handlers registrados na v1.
+type Handlers struct {
+	Product  *handlers.ProductHandler
+	Policy   *handlers.PolicyHandler
+	APIKey   *handlers.APIKeyHandler
+	Token    *handlers.TokenHandler
+	LogLevel *handlers.LogLevelHandler
+	Me       *handlers.MeHandler
+}
+
+func RegisterRoutes(router *gin.RouterGroup, mw Middlewares, h Handlers) {
 	// Register Product Routes
-	registerProductRoutes(router, auth, productHandler)
+	registerProductRoutes(router, mw, h.Product)
+
+	// Register Admin Routes
+	registerAdminRoutes(router, mw, h)
+
+	// Register Me Routes
+	registerMeRoutes(router, mw, h.Me)
 }
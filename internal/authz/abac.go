@@ -0,0 +1,203 @@
+package authz
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// ResourceRule concede uma ação sobre um recurso às roles (e às que as herdam),
+// opcionalmente só quando a condição When sobre os atributos for verdadeira.
+//
+//	resources:
+//	  products:update:
+//	    - roles: [admin]
+//	    - roles: [manager]
+//	      when: resource.category in subject.categories
+//	    - roles: [develop]
+//	      when: resource.created_by == subject.id && resource.status == "draft"
+type ResourceRule struct {
+	Roles []string `yaml:"roles" json:"roles"`
+	When  string   `yaml:"when" json:"when"`
+}
+
+type resourceRule struct {
+	roles []string
+	when  string
+	cond  []comparison // Conjunção; vazia = sempre verdadeira
+}
+
+// Subject descreve quem executa a ação nas condições (subject.<atributo>).
+// Atributos têm vários valores (ex: categories); id, tenant etc. têm um só.
+type Subject struct {
+	Roles      []string
+	Attributes map[string][]string
+}
+
+// ResourceDecision é o resultado (explicável) de uma checagem por atributos.
+type ResourceDecision struct {
+	Allowed bool   `json:"allowed"`
+	Action  string `json:"action"`
+	Rule    string `json:"rule,omitempty"`
+	Reason  string `json:"reason"`
+}
+
+// DecideResource avalia se o subject pode executar action sobre o recurso.
+// Ações sem regras em "resources" são permitidas (basta a permissão da rota).
+func (p *Policy) DecideResource(subject Subject, action string, resource map[string]string) ResourceDecision {
+	d := ResourceDecision{Action: action}
+
+	rules, ok := p.resources[action]
+	if !ok {
+		d.Allowed = true
+		d.Reason = fmt.Sprintf("nenhuma regra por atributos para %s", action)
+		return d
+	}
+
+	roles := p.EffectiveRoles(subject.Roles)
+	for _, rule := range rules {
+		if !slices.ContainsFunc(rule.roles, func(r string) bool { return slices.Contains(roles, r) }) {
+			continue
+		}
+		if evalConditions(rule.cond, subject.Attributes, resource) {
+			d.Allowed = true
+			d.Rule = rule.describe()
+			d.Reason = fmt.Sprintf("regra %s satisfeita", d.Rule)
+			return d
+		}
+	}
+	d.Reason = fmt.Sprintf("nenhuma regra de %s satisfeita pelas roles %v e atributos do recurso", action, roles)
+	return d
+}
+
+func (r resourceRule) describe() string {
+	desc := "roles=" + strings.Join(r.roles, ",")
+	if r.when != "" {
+		desc += " when " + r.when
+	}
+	return desc
+}
+
+// comparison é "operando operador operando", com operador ==, != ou in.
+type comparison struct {
+	left, op, right string
+}
+
+// parseCondition compila comparações unidas por &&. Operandos são
+// subject.<atributo>, resource.<atributo> ou literais entre aspas duplas.
+func parseCondition(s string) ([]comparison, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+
+	tokens, err := tokenizeCondition(s)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []comparison
+	for i := 0; ; {
+		if i+3 > len(tokens) {
+			return nil, fmt.Errorf("comparação incompleta em %q", s)
+		}
+		c := comparison{left: tokens[i], op: tokens[i+1], right: tokens[i+2]}
+		if c.op != "==" && c.op != "!=" && c.op != "in" {
+			return nil, fmt.Errorf("operador inválido %q em %q (use ==, != ou in)", c.op, s)
+		}
+		for _, operand := range []string{c.left, c.right} {
+			if !validOperand(operand) {
+				return nil, fmt.Errorf("operando inválido %q em %q", operand, s)
+			}
+		}
+		out = append(out, c)
+
+		i += 3
+		if i == len(tokens) {
+			return out, nil
+		}
+		if tokens[i] != "&&" {
+			return nil, fmt.Errorf("esperava && em %q, encontrou %q", s, tokens[i])
+		}
+		i++
+	}
+}
+
+func tokenizeCondition(s string) ([]string, error) {
+	var tokens []string
+	for i := 0; i < len(s); {
+		switch {
+		case unicode.IsSpace(rune(s[i])):
+			i++
+		case s[i] == '"':
+			end := strings.IndexByte(s[i+1:], '"')
+			if end < 0 {
+				return nil, fmt.Errorf("aspas não fechadas em %q", s)
+			}
+			tokens = append(tokens, s[i:i+end+2])
+			i += end + 2
+		case strings.HasPrefix(s[i:], "&&"), strings.HasPrefix(s[i:], "=="), strings.HasPrefix(s[i:], "!="):
+			tokens = append(tokens, s[i:i+2])
+			i += 2
+		default:
+			j := i
+			for j < len(s) && !unicode.IsSpace(rune(s[j])) && !strings.ContainsRune(`"&=!`, rune(s[j])) {
+				j++
+			}
+			if j == i {
+				j++
+			}
+			tokens = append(tokens, s[i:j])
+			i = j
+		}
+	}
+	return tokens, nil
+}
+
+func validOperand(s string) bool {
+	if len(s) >= 2 && strings.HasPrefix(s, `"`) && strings.HasSuffix(s, `"`) {
+		return true
+	}
+	for _, prefix := range []string{"subject.", "resource."} {
+		if name, ok := strings.CutPrefix(s, prefix); ok && name != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func evalConditions(conds []comparison, subject map[string][]string, resource map[string]string) bool {
+	for _, c := range conds {
+		left := operandValues(c.left, subject, resource)
+		right := operandValues(c.right, subject, resource)
+
+		// Valores vazios nunca são iguais: produto sem dono não "pertence" a quem não tem id
+		equal := len(left) == 1 && len(right) == 1 && left[0] != "" && left[0] == right[0]
+		var ok bool
+		switch c.op {
+		case "==":
+			ok = equal
+		case "!=":
+			ok = !equal
+		case "in":
+			ok = len(left) == 1 && left[0] != "" && slices.Contains(right, left[0])
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func operandValues(operand string, subject map[string][]string, resource map[string]string) []string {
+	if name, ok := strings.CutPrefix(operand, "subject."); ok {
+		return subject[name]
+	}
+	if name, ok := strings.CutPrefix(operand, "resource."); ok {
+		if v, ok := resource[name]; ok {
+			return []string{v}
+		}
+		return nil
+	}
+	return []string{strings.Trim(operand, `"`)}
+}
@@ -0,0 +1,76 @@
+package authz
+
+import (
+	"testing"
+
+	"go-api-first-steps/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultPolicy_ProductUpdateRules(t *testing.T) {
+	e, err := NewEnforcer("")
+	require.NoError(t, err)
+
+	draft := map[string]string{"created_by": "ana", "status": "draft", "category": "monitores"}
+	published := map[string]string{"created_by": "ana", "status": "published", "category": "monitores"}
+
+	ana := domain.Actor{ID: "ana", Roles: []string{"develop"}}
+	bia := domain.Actor{ID: "bia", Roles: []string{"develop"}}
+	gerente := domain.Actor{ID: "gil", Roles: []string{"manager"}, Attributes: map[string][]string{"categories": {"monitores", "teclados"}}}
+	outroGerente := domain.Actor{ID: "leo", Roles: []string{"manager"}, Attributes: map[string][]string{"categories": {"mouses"}}}
+	admin := domain.Actor{ID: "root", Roles: []string{"admin"}}
+
+	// Autor: só os próprios rascunhos
+	assert.NoError(t, e.Authorize(ana, "products:update", draft))
+	assert.ErrorIs(t, e.Authorize(ana, "products:update", published), domain.ErrForbidden)
+	assert.ErrorIs(t, e.Authorize(bia, "products:update", draft), domain.ErrForbidden)
+
+	// Manager: só na sua categoria
+	assert.NoError(t, e.Authorize(gerente, "products:update", published))
+	assert.ErrorIs(t, e.Authorize(outroGerente, "products:update", published), domain.ErrForbidden)
+
+	// Admin: tudo; ações sem regras por atributos são livres
+	assert.NoError(t, e.Authorize(admin, "products:update", published))
+	assert.NoError(t, e.Authorize(bia, "products:delete", published))
+}
+
+func TestDecideResource_Explains(t *testing.T) {
+	policy, err := ParsePolicy([]byte(`
+roles:
+  develop: {permissions: [products:update:own]}
+resources:
+  products:update:
+    - roles: [develop]
+      when: resource.created_by == subject.id && resource.status != "archived"
+`))
+	require.NoError(t, err)
+
+	d := policy.DecideResource(Subject{Roles: []string{"develop"}, Attributes: map[string][]string{"id": {"ana"}}},
+		"products:update", map[string]string{"created_by": "ana", "status": "draft"})
+	assert.True(t, d.Allowed)
+	assert.Contains(t, d.Rule, "roles=develop")
+
+	// Produto sem dono não pertence a ninguém, nem a quem não tem id
+	d = policy.DecideResource(Subject{Roles: []string{"develop"}}, "products:update", map[string]string{"status": "draft"})
+	assert.False(t, d.Allowed)
+}
+
+func TestCompile_ResourceValidation(t *testing.T) {
+	cases := map[string]string{
+		"role inexistente":   `{roles: {a: {}}, resources: {x: [{roles: [b]}]}}`,
+		"sem roles":          `{roles: {a: {}}, resources: {x: [{when: "subject.id == resource.owner"}]}}`,
+		"operador inválido":  `{roles: {a: {}}, resources: {x: [{roles: [a], when: "subject.id > resource.owner"}]}}`,
+		"operando inválido":  `{roles: {a: {}}, resources: {x: [{roles: [a], when: "owner == subject.id"}]}}`,
+		"comparação solta":   `{roles: {a: {}}, resources: {x: [{roles: [a], when: "subject.id == resource.owner &&"}]}}`,
+		"aspas não fechadas": `{roles: {a: {}}, resources: {x: [{roles: [a], when: "resource.status == \"draft"}]}}`,
+	}
+	for name, policy := range cases {
+		_, err := ParsePolicy([]byte(policy))
+		assert.Error(t, err, name)
+	}
+
+	_, err := ParsePolicy([]byte(`{roles: {a: {}}, resources: {x: [{roles: [a], when: "resource.category in subject.categories"}]}}`))
+	assert.NoError(t, err)
+}
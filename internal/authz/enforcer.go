@@ -0,0 +1,135 @@
+package authz
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"go-api-first-steps/internal/domain"
+)
+
+//go:embed default_policy.yaml
+var defaultPolicy []byte
+
+// Enforcer guarda a política ativa e permite trocá-la em tempo de execução
+// (hot reload) sem locks no caminho das requisições.
+type Enforcer struct {
+	policy  atomic.Pointer[Policy]
+	path    string
+	modTime time.Time // data de modificação do arquivo na carga inicial
+}
+
+// NewEnforcer carrega a política de path (YAML/JSON). Com path vazio, usa a
+// política padrão embutida no binário.
+func NewEnforcer(path string) (*Enforcer, error) {
+	e := &Enforcer{path: path}
+
+	data := defaultPolicy
+	if path != "" {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("falha ao ler política: %w", err)
+		}
+		e.modTime = info.ModTime()
+
+		if data, err = os.ReadFile(path); err != nil {
+			return nil, fmt.Errorf("falha ao ler política: %w", err)
+		}
+	}
+
+	policy, err := ParsePolicy(data)
+	if err != nil {
+		return nil, err
+	}
+	e.policy.Store(policy)
+	return e, nil
+}
+
+// NewEnforcerFromPolicy cria um Enforcer com uma política já compilada (útil em testes).
+func NewEnforcerFromPolicy(policy *Policy) *Enforcer {
+	e := &Enforcer{}
+	e.policy.Store(policy)
+	return e
+}
+
+// Policy devolve a política ativa.
+func (e *Enforcer) Policy() *Policy {
+	return e.policy.Load()
+}
+
+// Decide avalia method+path com a política ativa. Veja Policy.Decide.
+func (e *Enforcer) Decide(roles []string, method, path string) Decision {
+	return e.Policy().Decide(roles, method, path)
+}
+
+// Authorize aplica as regras por atributos ("resources") da política ativa.
+// Implementa domain.Authorizer: a negação é um erro que envolve domain.ErrForbidden.
+func (e *Enforcer) Authorize(actor domain.Actor, action string, resource map[string]string) error {
+	attrs := make(map[string][]string, len(actor.Attributes)+2)
+	for k, v := range actor.Attributes {
+		attrs[k] = v
+	}
+	attrs["id"] = []string{actor.ID}
+	attrs["tenant"] = []string{actor.Tenant}
+
+	d := e.Policy().DecideResource(Subject{Roles: actor.Roles, Attributes: attrs}, action, resource)
+	if !d.Allowed {
+		return fmt.Errorf("%w: %s", domain.ErrForbidden, d.Reason)
+	}
+	return nil
+}
+
+// Reload relê o arquivo da política. Se o arquivo for inválido, a política
+// atual é mantida e o erro é devolvido.
+func (e *Enforcer) Reload() error {
+	if e.path == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(e.path)
+	if err != nil {
+		return fmt.Errorf("falha ao ler política: %w", err)
+	}
+	policy, err := ParsePolicy(data)
+	if err != nil {
+		return err
+	}
+	e.policy.Store(policy)
+	return nil
+}
+
+// Watch verifica o arquivo da política a cada interval e recarrega quando a
+// data de modificação muda. Roda até ctx ser cancelado; deve ser chamado em goroutine.
+func (e *Enforcer) Watch(ctx context.Context, interval time.Duration) {
+	if e.path == "" {
+		return
+	}
+
+	lastMod := e.modTime
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			info, err := os.Stat(e.path)
+			if err != nil || info.ModTime().Equal(lastMod) {
+				continue
+			}
+			lastMod = info.ModTime()
+
+			if err := e.Reload(); err != nil {
+				slog.ErrorContext(ctx, "Política inválida, mantendo a anterior", "path", e.path, "error", err)
+				continue
+			}
+			slog.InfoContext(ctx, "Política de autorização recarregada", "path", e.path)
+		}
+	}
+}
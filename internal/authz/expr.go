@@ -0,0 +1,170 @@
+package authz
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Expr é uma expressão de permissão já compilada, ex:
+//
+//	products:read
+//	products:update || products:admin
+//	(products:read && reports:read) || *
+type Expr interface {
+	// Eval avalia a expressão contra o conjunto de permissões efetivas.
+	Eval(perms PermissionSet) bool
+	String() string
+}
+
+type permExpr string
+
+func (e permExpr) Eval(perms PermissionSet) bool { return perms.Has(string(e)) }
+func (e permExpr) String() string                { return string(e) }
+
+type andExpr struct{ left, right Expr }
+
+func (e andExpr) Eval(perms PermissionSet) bool { return e.left.Eval(perms) && e.right.Eval(perms) }
+func (e andExpr) String() string                { return "(" + e.left.String() + " && " + e.right.String() + ")" }
+
+type orExpr struct{ left, right Expr }
+
+func (e orExpr) Eval(perms PermissionSet) bool { return e.left.Eval(perms) || e.right.Eval(perms) }
+func (e orExpr) String() string                { return "(" + e.left.String() + " || " + e.right.String() + ")" }
+
+type notExpr struct{ inner Expr }
+
+func (e notExpr) Eval(perms PermissionSet) bool { return !e.inner.Eval(perms) }
+func (e notExpr) String() string                { return "!" + e.inner.String() }
+
+// ParseExpr compila uma expressão com permissões, &&, ||, ! e parênteses.
+// Precedência: ! > && > ||.
+func ParseExpr(s string) (Expr, error) {
+	p := &parser{tokens: tokenize(s)}
+	if len(p.tokens) == 0 {
+		return nil, fmt.Errorf("expressão vazia")
+	}
+
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("token inesperado %q em %q", p.tokens[p.pos], s)
+	}
+	return expr, nil
+}
+
+func tokenize(s string) []string {
+	var tokens []string
+	for i := 0; i < len(s); {
+		switch {
+		case unicode.IsSpace(rune(s[i])):
+			i++
+		case strings.HasPrefix(s[i:], "&&"), strings.HasPrefix(s[i:], "||"):
+			tokens = append(tokens, s[i:i+2])
+			i += 2
+		case s[i] == '(' || s[i] == ')' || s[i] == '!':
+			tokens = append(tokens, s[i:i+1])
+			i++
+		default:
+			j := i
+			for j < len(s) && !unicode.IsSpace(rune(s[j])) && !strings.ContainsRune("()!&|", rune(s[j])) {
+				j++
+			}
+			if j == i {
+				// Caractere solto (ex: "&" sozinho): vira token inválido para o parser reclamar
+				j++
+			}
+			tokens = append(tokens, s[i:j])
+			i = j
+		}
+	}
+	return tokens
+}
+
+type parser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *parser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *parser) parseOr() (Expr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek() == "||" {
+		p.pos++
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orExpr{left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (Expr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek() == "&&" {
+		p.pos++
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = andExpr{left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseUnary() (Expr, error) {
+	switch tok := p.peek(); tok {
+	case "":
+		return nil, fmt.Errorf("expressão incompleta")
+	case "!":
+		p.pos++
+		inner, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notExpr{inner}, nil
+	case "(":
+		p.pos++
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() != ")" {
+			return nil, fmt.Errorf("parêntese não fechado")
+		}
+		p.pos++
+		return inner, nil
+	case ")", "&&", "||":
+		return nil, fmt.Errorf("token inesperado %q", tok)
+	default:
+		if !validPermission(tok) {
+			return nil, fmt.Errorf("permissão inválida %q", tok)
+		}
+		p.pos++
+		return permExpr(tok), nil
+	}
+}
+
+func validPermission(s string) bool {
+	for _, r := range s {
+		if !(unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune(":_-.*", r)) {
+			return false
+		}
+	}
+	return s != ""
+}
@@ -0,0 +1,322 @@
+// Package authz implementa a autorização declarativa: uma política (YAML/JSON)
+// define a hierarquia de roles, as permissões de cada role e a expressão de
+// permissão exigida por cada rota.
+package authz
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// GRPCMethod é o "método HTTP" usado nas chaves de rota de métodos gRPC,
+// ex: "GRPC /product.v1.ProductService/ListProducts".
+const GRPCMethod = "GRPC"
+
+// RoleDefinition descreve uma role na política.
+type RoleDefinition struct {
+	// Inherits lista roles cujas permissões esta role também recebe (ex: admin herda manager).
+	Inherits []string `yaml:"inherits" json:"inherits"`
+	// Permissions concedidas diretamente. "products:*" concede todas as permissões "products:...".
+	Permissions []string `yaml:"permissions" json:"permissions"`
+}
+
+// PolicyFile é o formato do arquivo de política (YAML ou JSON).
+//
+//	roles:
+//	  admin:   {inherits: [manager], permissions: ["products:delete"]}
+//	  manager: {inherits: [develop], permissions: ["products:update"]}
+//	  develop: {permissions: ["products:read", "products:create"]}
+//	routes:
+//	  "GET /api/v1/products": "products:read"
+//	resources:
+//	  products:update:
+//	    - {roles: [manager], when: "resource.category in subject.categories"}
+//
+// Routes decide o acesso à rota; Resources (opcional) restringe a ação pelos
+// atributos do recurso, avaliado na camada de serviço (veja ResourceRule).
+type PolicyFile struct {
+	Roles     map[string]RoleDefinition `yaml:"roles" json:"roles"`
+	Routes    map[string]string         `yaml:"routes" json:"routes"`
+	Resources map[string][]ResourceRule `yaml:"resources" json:"resources"`
+}
+
+// PermissionSet é o conjunto de permissões efetivas de um usuário.
+type PermissionSet map[string]bool
+
+// Has informa se a permissão é concedida, considerando curingas ("*", "products:*").
+func (s PermissionSet) Has(perm string) bool {
+	if s[perm] || s["*"] {
+		return true
+	}
+	for i := len(perm) - 1; i >= 0; i-- {
+		if perm[i] == ':' && s[perm[:i]+":*"] {
+			return true
+		}
+	}
+	return false
+}
+
+// Sorted devolve as permissões em ordem alfabética.
+func (s PermissionSet) Sorted() []string {
+	out := make([]string, 0, len(s))
+	for p := range s {
+		out = append(out, p)
+	}
+	sort.Strings(out)
+	return out
+}
+
+type routeRule struct {
+	method   string
+	template string
+	expr     Expr
+}
+
+// Policy é uma PolicyFile validada e compilada, pronta para decisões.
+type Policy struct {
+	// roles: role -> roles efetivas (ela mesma + todas as herdadas, transitivamente)
+	roles       map[string][]string
+	permissions map[string][]string
+	routes      []routeRule
+	resources   map[string][]resourceRule // ação -> regras por atributos
+}
+
+// ParsePolicy lê uma política em YAML ou JSON (JSON é YAML válido) e a compila.
+func ParsePolicy(data []byte) (*Policy, error) {
+	var file PolicyFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("política inválida: %w", err)
+	}
+	return Compile(file)
+}
+
+// Compile valida a PolicyFile: roles herdadas devem existir, sem ciclos, e
+// toda rota deve ter o formato "MÉTODO /caminho" e uma expressão válida.
+func Compile(file PolicyFile) (*Policy, error) {
+	p := &Policy{
+		roles:       make(map[string][]string, len(file.Roles)),
+		permissions: make(map[string][]string, len(file.Roles)),
+	}
+
+	for name, def := range file.Roles {
+		p.permissions[name] = def.Permissions
+		for _, parent := range def.Inherits {
+			if _, ok := file.Roles[parent]; !ok {
+				return nil, fmt.Errorf("role %q herda role inexistente %q", name, parent)
+			}
+		}
+	}
+
+	for name := range file.Roles {
+		expanded, err := expandRole(file.Roles, name, map[string]bool{})
+		if err != nil {
+			return nil, err
+		}
+		p.roles[name] = expanded
+	}
+
+	for key, exprStr := range file.Routes {
+		method, template, ok := strings.Cut(strings.TrimSpace(key), " ")
+		template = strings.TrimSpace(template)
+		if !ok || method == "" || !strings.HasPrefix(template, "/") {
+			return nil, fmt.Errorf("rota inválida %q (use \"MÉTODO /caminho\")", key)
+		}
+
+		expr, err := ParseExpr(exprStr)
+		if err != nil {
+			return nil, fmt.Errorf("rota %q: %w", key, err)
+		}
+		p.routes = append(p.routes, routeRule{method: strings.ToUpper(method), template: template, expr: expr})
+	}
+
+	p.resources = make(map[string][]resourceRule, len(file.Resources))
+	for action, rules := range file.Resources {
+		if len(rules) == 0 {
+			return nil, fmt.Errorf("recurso %q sem regras", action)
+		}
+		for i, rule := range rules {
+			if len(rule.Roles) == 0 {
+				return nil, fmt.Errorf("recurso %q, regra #%d: roles obrigatórias", action, i+1)
+			}
+			for _, role := range rule.Roles {
+				if _, ok := file.Roles[role]; !ok {
+					return nil, fmt.Errorf("recurso %q, regra #%d: role inexistente %q", action, i+1, role)
+				}
+			}
+			cond, err := parseCondition(rule.When)
+			if err != nil {
+				return nil, fmt.Errorf("recurso %q, regra #%d: %w", action, i+1, err)
+			}
+			p.resources[action] = append(p.resources[action], resourceRule{roles: rule.Roles, when: rule.When, cond: cond})
+		}
+	}
+
+	// Rotas sem parâmetros primeiro, para que "/products/me" vença "/products/:id"
+	sort.SliceStable(p.routes, func(i, j int) bool {
+		return routeSpecificity(p.routes[i].template) > routeSpecificity(p.routes[j].template)
+	})
+
+	return p, nil
+}
+
+func expandRole(roles map[string]RoleDefinition, name string, visiting map[string]bool) ([]string, error) {
+	if visiting[name] {
+		return nil, fmt.Errorf("ciclo na hierarquia de roles envolvendo %q", name)
+	}
+	visiting[name] = true
+	defer delete(visiting, name)
+
+	out := []string{name}
+	for _, parent := range roles[name].Inherits {
+		inherited, err := expandRole(roles, parent, visiting)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, inherited...)
+	}
+	return out, nil
+}
+
+// EffectiveRoles expande as roles do usuário pela hierarquia (admin -> manager -> develop).
+// Roles desconhecidas pela política são mantidas, mas não concedem permissões.
+func (p *Policy) EffectiveRoles(roles []string) []string {
+	seen := make(map[string]bool)
+	var out []string
+	for _, role := range roles {
+		expanded, ok := p.roles[role]
+		if !ok {
+			expanded = []string{role}
+		}
+		for _, r := range expanded {
+			if !seen[r] {
+				seen[r] = true
+				out = append(out, r)
+			}
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// Permissions devolve as permissões efetivas de um conjunto de roles.
+func (p *Policy) Permissions(roles []string) PermissionSet {
+	perms := make(PermissionSet)
+	for _, role := range p.EffectiveRoles(roles) {
+		for _, perm := range p.permissions[role] {
+			perms[perm] = true
+		}
+	}
+	return perms
+}
+
+// Decision é o resultado (explicável) de uma checagem de autorização.
+type Decision struct {
+	Allowed     bool     `json:"allowed"`
+	Method      string   `json:"method"`
+	Route       string   `json:"route,omitempty"`
+	Expression  string   `json:"expression,omitempty"`
+	Roles       []string `json:"roles"`
+	Permissions []string `json:"permissions"`
+	Reason      string   `json:"reason"`
+}
+
+// Decide avalia se as roles podem acessar method+path. path pode ser o template
+// da rota (c.FullPath()) ou um caminho concreto ("/api/v1/products/5").
+// Rotas sem regra na política são negadas.
+func (p *Policy) Decide(roles []string, method, path string) Decision {
+	method = strings.ToUpper(method)
+	perms := p.Permissions(roles)
+
+	d := Decision{
+		Method:      method,
+		Roles:       p.EffectiveRoles(roles),
+		Permissions: perms.Sorted(),
+	}
+	if d.Roles == nil {
+		d.Roles = []string{}
+	}
+
+	rule, ok := p.match(method, path)
+	if !ok {
+		d.Reason = fmt.Sprintf("nenhuma regra na política para %s %s (negado por padrão)", method, path)
+		return d
+	}
+
+	d.Route = rule.template
+	d.Expression = rule.expr.String()
+	d.Allowed = rule.expr.Eval(perms)
+	if d.Allowed {
+		d.Reason = fmt.Sprintf("permissões efetivas satisfazem %s", d.Expression)
+	} else {
+		d.Reason = fmt.Sprintf("permissões efetivas não satisfazem %s", d.Expression)
+	}
+	return d
+}
+
+// HasRule informa se existe regra para method+path.
+func (p *Policy) HasRule(method, path string) bool {
+	_, ok := p.match(strings.ToUpper(method), path)
+	return ok
+}
+
+// Routes devolve as chaves "MÉTODO /template" de todas as rotas da política.
+func (p *Policy) Routes() []string {
+	out := make([]string, len(p.routes))
+	for i, r := range p.routes {
+		out[i] = r.method + " " + r.template
+	}
+	sort.Strings(out)
+	return out
+}
+
+func (p *Policy) match(method, path string) (routeRule, bool) {
+	for _, r := range p.routes {
+		if r.method == method && matchTemplate(r.template, path) {
+			return r, true
+		}
+	}
+	return routeRule{}, false
+}
+
+// matchTemplate compara um template no estilo Gin (":param", "*wildcard") com um caminho.
+func matchTemplate(template, path string) bool {
+	if template == path {
+		return true
+	}
+
+	tSegs := strings.Split(strings.Trim(template, "/"), "/")
+	pSegs := strings.Split(strings.Trim(path, "/"), "/")
+
+	for i, seg := range tSegs {
+		if strings.HasPrefix(seg, "*") {
+			return true
+		}
+		if i >= len(pSegs) {
+			return false
+		}
+		if strings.HasPrefix(seg, ":") {
+			if pSegs[i] == "" {
+				return false
+			}
+			continue
+		}
+		if seg != pSegs[i] {
+			return false
+		}
+	}
+	return len(tSegs) == len(pSegs)
+}
+
+// routeSpecificity conta segmentos literais (mais literais = mais específica)
+func routeSpecificity(template string) int {
+	n := 0
+	for _, seg := range strings.Split(template, "/") {
+		if seg != "" && !strings.HasPrefix(seg, ":") && !strings.HasPrefix(seg, "*") {
+			n++
+		}
+	}
+	return n
+}
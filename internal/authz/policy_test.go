@@ -0,0 +1,111 @@
+package authz
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultPolicy_RoleHierarchy(t *testing.T) {
+	e, err := NewEnforcer("")
+	require.NoError(t, err)
+
+	// admin ⊇ manager ⊇ develop: admin lista produtos sem ter a role develop
+	assert.True(t, e.Decide([]string{"admin"}, "GET", "/api/v1/products").Allowed)
+	assert.True(t, e.Decide([]string{"manager"}, "PUT", "/api/v1/products/:id").Allowed)
+	assert.False(t, e.Decide([]string{"develop"}, "DELETE", "/api/v1/products/:id").Allowed)
+	assert.False(t, e.Decide(nil, "GET", "/api/v1/products").Allowed)
+
+	d := e.Decide([]string{"admin"}, "DELETE", "/api/v1/products/7")
+	assert.True(t, d.Allowed)
+	assert.Equal(t, "/api/v1/products/:id", d.Route)
+	assert.Equal(t, []string{"admin", "develop", "manager"}, d.Roles)
+}
+
+func TestPolicy_UnknownRouteIsDenied(t *testing.T) {
+	e, err := NewEnforcer("")
+	require.NoError(t, err)
+
+	d := e.Decide([]string{"admin"}, "GET", "/api/v1/secret")
+
+	assert.False(t, d.Allowed)
+	assert.Contains(t, d.Reason, "nenhuma regra")
+}
+
+func TestParseExpr(t *testing.T) {
+	perms := PermissionSet{"products:read": true, "reports:*": true}
+
+	cases := map[string]bool{
+		"products:read":                                    true,
+		"products:update":                                  false,
+		"products:read && reports:export":                  true,
+		"products:update || reports:export":                true,
+		"!products:read":                                   false,
+		"(products:update || products:read) && !admin:all": true,
+	}
+	for input, want := range cases {
+		expr, err := ParseExpr(input)
+		require.NoError(t, err, input)
+		assert.Equal(t, want, expr.Eval(perms), input)
+	}
+
+	for _, invalid := range []string{"", "a &&", "(a || b", "a b", "a & b", "a$b"} {
+		_, err := ParseExpr(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestCompile_Validation(t *testing.T) {
+	_, err := ParsePolicy([]byte(`
+roles:
+  a: {inherits: [b]}
+  b: {inherits: [a]}
+`))
+	assert.ErrorContains(t, err, "ciclo")
+
+	_, err = ParsePolicy([]byte(`{"roles": {"a": {"inherits": ["ghost"]}}}`))
+	assert.ErrorContains(t, err, "inexistente")
+
+	_, err = ParsePolicy([]byte(`{"routes": {"/sem/metodo": "x"}}`))
+	assert.ErrorContains(t, err, "rota inválida")
+}
+
+func TestEnforcer_HotReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policy.yaml")
+	write := func(content string, mod time.Time) {
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+		require.NoError(t, os.Chtimes(path, mod, mod))
+	}
+
+	write(`
+roles: {develop: {permissions: [products:read]}}
+routes: {"GET /api/v1/products": products:read}
+`, time.Now().Add(-time.Hour))
+
+	e, err := NewEnforcer(path)
+	require.NoError(t, err)
+	assert.True(t, e.Decide([]string{"develop"}, "GET", "/api/v1/products").Allowed)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go e.Watch(ctx, 5*time.Millisecond)
+
+	write(`
+roles: {develop: {permissions: [products:read]}}
+routes: {"GET /api/v1/products": products:admin}
+`, time.Now())
+
+	assert.Eventually(t, func() bool {
+		return !e.Decide([]string{"develop"}, "GET", "/api/v1/products").Allowed
+	}, time.Second, 5*time.Millisecond)
+
+	// Arquivo inválido mantém a política anterior
+	write(`routes: {"GET /x": "(("}`, time.Now().Add(time.Minute))
+	time.Sleep(30 * time.Millisecond)
+	assert.True(t, e.Policy().HasRule("GET", "/api/v1/products"))
+}
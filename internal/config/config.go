@@ -3,24 +3,163 @@ package config
 import (
 	"fmt"
 	"log/slog"
+	"net"
+	"net/url"
 	"os"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
+
+	"go-api-first-steps/pkg/logger"
 
 	"github.com/joho/godotenv"
 )
 
+// RateLimitRule define um token bucket: RPS tokens por segundo, até Burst acumulados.
+type RateLimitRule struct {
+	RPS   float64
+	Burst int
+}
+
+// RateLimitConfig agrupa os limites por grupo de rotas e por role.
+type RateLimitConfig struct {
+	Enabled bool
+	KeyBy   string                   // "user" (default), "client" ou "ip"
+	Default RateLimitRule            // Limite padrão de cada grupo de rotas
+	Groups  map[string]RateLimitRule // Limite específico por grupo (ex: "products")
+	Roles   map[string]RateLimitRule // Limite por role (maior entre as roles); substitui só o Default, não Groups
+}
+
 type Config struct {
 	Port                        string
 	DBUrl                       string
 	AppInsightsConnectionString string
 
+	// Logs: nível mínimo do console e do App Insights (debug, info, warn, error)
+	LogLevel            slog.Level
+	AppInsightsLogLevel slog.Level
+
+	// Amostragem dos logs abaixo de Error enviados ao App Insights (erros sempre vão):
+	// fração fixa, ou adaptativa (~N logs/s) se AppInsightsMaxLogsPerSecond > 0
+	AppInsightsSamplingRate     float64
+	AppInsightsMaxLogsPerSecond float64
+
+	// Fila assíncrona por destino de log (console, App Insights). LogQueueSize 0 = síncrono
+	LogQueueSize    int
+	LogWorkers      int
+	LogOverflow     logger.OverflowPolicy // drop_oldest, drop_new ou block
+	LogBlockTimeout time.Duration         // Espera máxima por espaço na fila com block
+
+	// Redação de PII nos logs (veja logger.ParseKeyRules e logger.ParsePatternRules).
+	// Authorization, X-API-Key, cookies e JWTs são sempre mascarados.
+	LogRedaction logger.RedactOptions
+
+	// Chave HMAC dos tokens do header X-Debug-Log (debug de uma requisição).
+	// Vazia = header ignorado e POST /admin/log-level/debug-tokens indisponível
+	LogDebugSecret []byte
+
 	// OIDC Configurations
 	KeycloakURL string // Ex: http://localhost:8080/realms/myrealm
 	ClientID    string // Ex: my-backend
 
+	// Descoberta OIDC resiliente: com OIDCStrict a API não sobe se o provedor estiver fora;
+	// sem ele, sobe respondendo 503 nas rotas protegidas e tenta de novo em background.
+	OIDCStrict           bool
+	OIDCJWKSCacheFile    string // Último JWKS válido, para warm restart com o provedor fora
+	OIDCRetryMaxInterval time.Duration
+
+	// Multi-issuer: arquivo YAML/JSON com os emissores confiáveis (um por tenant/realm).
+	// Se definido, substitui KEYCLOAK_URL/KEYCLOAK_CLIENT_ID (veja middleware.LoadIssuers).
+	OIDCIssuersFile string
+
+	// TenantClaim: caminho da claim com o tenant do usuário (ex: "tenant", "org.id").
+	// Vazio = tenant do emissor (OIDC_ISSUERS_FILE) ou o tenant padrão. Com
+	// OIDC_ISSUERS_FILE vale o tenant do emissor e a claim precisa concordar com ele.
+	TenantClaim string
+
+	// UserAttributeClaims: atributos do usuário para as regras ABAC, "atributo[=claim],..."
+	// (ex: "categories=product_categories"). Veja middleware.ParseAttributeClaims.
+	UserAttributeClaims string
+
+	// AuthMode: "oidc" (discovery no Keycloak) ou "offline" (chaves locais, sem rede)
+	AuthMode string
+
+	// Verificação offline de JWT (AuthMode=offline)
+	JWTPublicKeys         string        // Ex: kid1=/keys/a.pem,/keys/b.pem
+	JWTInlinePublicKey    string        // Chave em base64 (DER), como impressa pelo cmd/mock_token
+	JWTJWKSFile           string        // Arquivo JWKS local
+	JWTIssuer             string        // Default: KeycloakURL; vazio desativa a checagem
+	JWTAudience           string        // Default: ClientID; vazio desativa a checagem
+	JWTLeeway             time.Duration // Tolerância de relógio para exp/iat
+	JWTKeysReloadInterval time.Duration
+
+	// Introspecção de tokens (RFC 7662): off, opaque (só tokens opacos) ou always
+	IntrospectionMode         string
+	IntrospectionURL          string // Default: {KEYCLOAK_URL}/protocol/openid-connect/token/introspect
+	IntrospectionClientID     string // Default: ClientID
+	IntrospectionClientSecret string
+	IntrospectionCacheTTL     time.Duration
+
+	// Mapeamento de roles do token (veja middleware.ParseRoleMapping)
+	RoleSources string // Default: client. Ex: client,realm=realm:,scope=scope:
+	RoleMerge   string // union ou first
+
+	// Expõe GET /metrics (formato Prometheus). Default: true
+	MetricsEnabled bool
+
+	// OpenTelemetry (tracing). Sem endpoint, os spans são criados (IDs nos logs e
+	// propagação W3C) mas não exportados.
+	OTelEndpoint    string  // URL OTLP/HTTP do collector (ex: http://localhost:4318)
+	OTelServiceName string  // Default: go-api-first-steps
+	OTelSampleRatio float64 // Fração de traces novos amostrados (0 a 1). Default: 1
+
+	// gRPC Configurations
+	// Se GRPCPort estiver vazio (ou igual a Port), o gRPC é servido na mesma porta via h2c.
+	GRPCEnabled bool
+	GRPCPort    string
+
+	// TLS (HTTP e gRPC). Sem TLSCertFile/TLSKeyFile, o servidor sobe sem TLS.
+	// Os arquivos são verificados a cada TLSReloadInterval (rotação sem restart).
+	TLSCertFile       string
+	TLSKeyFile        string
+	TLSMinVersion     string // 1.2 ou 1.3
+	TLSCipherSuites   string // Nomes separados por vírgula (só TLS 1.2)
+	TLSClientCAFile   string // CA dos certificados de cliente (mTLS)
+	TLSClientAuth     string // none, optional ou require
+	TLSReloadInterval time.Duration
+	MTLSClientsFile   string // Certificado de cliente -> identidade (veja middleware.LoadClientCertMapper)
+
+	// HTTP/3 (QUIC) opcional, só com TLS. HTTP3Port é UDP; vazio = mesmo número da porta HTTP.
+	HTTP3Enabled bool
+	HTTP3Port    string
+
+	// Idempotency-Key: por quanto tempo as respostas ficam guardadas para replay
+	IdempotencyTTL time.Duration
+	// Tamanho máximo do body (bytes) de requisições com Idempotency-Key; acima disso, 413
+	IdempotencyMaxBodyBytes int64
+
+	// Política de autorização (YAML/JSON). Vazio = política padrão embutida.
+	// O arquivo é verificado a cada PolicyReloadInterval e recarregado se mudar.
+	PolicyFile           string
+	PolicyReloadInterval time.Duration
+
+	// Rate Limiting por usuário, client ou IP
+	RateLimit RateLimitConfig
+
+	// IPs/CIDRs dos proxies cujo X-Forwarded-For é aceito como IP do cliente.
+	// Vazio = nenhum: o IP do cliente é o da conexão (X-Forwarded-For ignorado).
+	TrustedProxies []string
+
 	// Development Mode
-	// Se true, permite rodar sem autenticação (apenas para desenvolvimento local)
-	DevMode bool
+	// Se true, não valida tokens: a identidade é DevUser/DevRoles (ou os headers
+	// X-Dev-User/X-Dev-Roles). As roles das rotas continuam sendo exigidas.
+	DevMode  bool
+	DevUser  string // Default: dev-user
+	DevRoles string // Default: admin
+	// Hosts de KEYCLOAK_URL aceitos com DevMode além de localhost/loopback
+	// (ex: "keycloak" no docker compose). Sem domínio não basta: pode ser um Service do Kubernetes.
+	DevModeAllowHosts []string
 }
 
 func Load() (*Config, error) {
@@ -37,27 +176,316 @@ func Load() (*Config, error) {
 		AppInsightsConnectionString: os.Getenv("APPINSIGHTS_CONNECTION_STRING"),
 		KeycloakURL:                 os.Getenv("KEYCLOAK_URL"),
 		ClientID:                    os.Getenv("KEYCLOAK_CLIENT_ID"),
+		AuthMode:                    strings.ToLower(getEnv("AUTH_MODE", "oidc")),
+		JWTPublicKeys:               os.Getenv("JWT_PUBLIC_KEYS"),
+		JWTInlinePublicKey:          os.Getenv("KEYCLOAK_PUBLIC_KEY"),
+		JWTJWKSFile:                 os.Getenv("JWT_JWKS_FILE"),
+		RoleSources:                 getEnv("ROLE_SOURCES", "client"),
+		RoleMerge:                   getEnv("ROLE_MERGE", "union"),
+		MetricsEnabled:              strings.ToLower(getEnv("METRICS_ENABLED", "true")) == "true",
+		GRPCEnabled:                 strings.ToLower(os.Getenv("GRPC_ENABLED")) == "true",
+		GRPCPort:                    os.Getenv("GRPC_PORT"),
 		DevMode:                     devMode,
+		DevUser:                     getEnv("DEV_USER", "dev-user"),
+		DevRoles:                    getEnv("DEV_ROLES", "admin"),
+	}
+	for _, host := range strings.Split(os.Getenv("DEV_MODE_ALLOW_HOSTS"), ",") {
+		if host = strings.TrimSpace(host); host != "" {
+			cfg.DevModeAllowHosts = append(cfg.DevModeAllowHosts, strings.ToLower(host))
+		}
+	}
+
+	idempotencyTTL, err := time.ParseDuration(getEnv("IDEMPOTENCY_TTL", "24h"))
+	if err != nil {
+		return nil, fmt.Errorf("IDEMPOTENCY_TTL inválido: %w", err)
+	}
+	cfg.IdempotencyTTL = idempotencyTTL
+	if cfg.IdempotencyMaxBodyBytes, err = strconv.ParseInt(getEnv("IDEMPOTENCY_MAX_BODY_BYTES", "1048576"), 10, 64); err != nil || cfg.IdempotencyMaxBodyBytes < 1 {
+		return nil, fmt.Errorf("IDEMPOTENCY_MAX_BODY_BYTES inválido: %q", os.Getenv("IDEMPOTENCY_MAX_BODY_BYTES"))
+	}
+
+	cfg.TLSCertFile = os.Getenv("TLS_CERT_FILE")
+	cfg.TLSKeyFile = os.Getenv("TLS_KEY_FILE")
+	cfg.TLSMinVersion = getEnv("TLS_MIN_VERSION", "1.2")
+	cfg.TLSCipherSuites = os.Getenv("TLS_CIPHER_SUITES")
+	cfg.TLSClientCAFile = os.Getenv("TLS_CLIENT_CA_FILE")
+	cfg.TLSClientAuth = os.Getenv("TLS_CLIENT_AUTH")
+	cfg.MTLSClientsFile = os.Getenv("MTLS_CLIENTS_FILE")
+	tlsReload, err := time.ParseDuration(getEnv("TLS_RELOAD_INTERVAL", "30s"))
+	if err != nil {
+		return nil, fmt.Errorf("TLS_RELOAD_INTERVAL inválido: %w", err)
+	}
+	cfg.TLSReloadInterval = tlsReload
+	if (cfg.TLSCertFile == "") != (cfg.TLSKeyFile == "") {
+		return nil, fmt.Errorf("ERRO CRITICO: TLS_CERT_FILE e TLS_KEY_FILE devem ser definidos juntos")
+	}
+	if cfg.LogLevel, err = parseLogLevel("LOG_LEVEL"); err != nil {
+		return nil, err
+	}
+	if cfg.AppInsightsLogLevel, err = parseLogLevel("APPINSIGHTS_LOG_LEVEL"); err != nil {
+		return nil, err
+	}
+	samplingRate, err := strconv.ParseFloat(getEnv("APPINSIGHTS_SAMPLING_RATE", "1"), 64)
+	if err != nil || samplingRate < 0 || samplingRate > 1 {
+		return nil, fmt.Errorf("APPINSIGHTS_SAMPLING_RATE inválido (use 0 a 1): %q", os.Getenv("APPINSIGHTS_SAMPLING_RATE"))
+	}
+	cfg.AppInsightsSamplingRate = samplingRate
+	maxLogs, err := strconv.ParseFloat(getEnv("APPINSIGHTS_MAX_LOGS_PER_SECOND", "0"), 64)
+	if err != nil || maxLogs < 0 {
+		return nil, fmt.Errorf("APPINSIGHTS_MAX_LOGS_PER_SECOND inválido: %q", os.Getenv("APPINSIGHTS_MAX_LOGS_PER_SECOND"))
+	}
+	cfg.AppInsightsMaxLogsPerSecond = maxLogs
+
+	if cfg.LogQueueSize, err = strconv.Atoi(getEnv("LOG_QUEUE_SIZE", "1024")); err != nil || cfg.LogQueueSize < 0 {
+		return nil, fmt.Errorf("LOG_QUEUE_SIZE inválido: %q", os.Getenv("LOG_QUEUE_SIZE"))
+	}
+	if cfg.LogWorkers, err = strconv.Atoi(getEnv("LOG_WORKERS", "1")); err != nil || cfg.LogWorkers < 1 {
+		return nil, fmt.Errorf("LOG_WORKERS inválido: %q", os.Getenv("LOG_WORKERS"))
+	}
+	if cfg.LogOverflow, err = logger.ParseOverflowPolicy(getEnv("LOG_OVERFLOW", "drop_oldest")); err != nil {
+		return nil, fmt.Errorf("LOG_OVERFLOW inválido: %w", err)
+	}
+	if cfg.LogBlockTimeout, err = time.ParseDuration(getEnv("LOG_BLOCK_TIMEOUT", "100ms")); err != nil {
+		return nil, fmt.Errorf("LOG_BLOCK_TIMEOUT inválido: %w", err)
+	}
+	if cfg.LogRedaction.Keys, err = logger.ParseKeyRules(getEnv("LOG_REDACT_KEYS", logger.DefaultRedactKeys)); err != nil {
+		return nil, fmt.Errorf("LOG_REDACT_KEYS inválido: %w", err)
+	}
+	if cfg.LogRedaction.Patterns, err = logger.ParsePatternRules(getEnv("LOG_REDACT_PATTERNS", logger.DefaultRedactPatterns)); err != nil {
+		return nil, fmt.Errorf("LOG_REDACT_PATTERNS inválido: %w", err)
+	}
+	if hashKey := os.Getenv("LOG_REDACT_HASH_KEY"); hashKey != "" {
+		cfg.LogRedaction.HashKey = []byte(hashKey)
+	}
+	if secret := os.Getenv("LOG_DEBUG_SECRET"); secret != "" {
+		cfg.LogDebugSecret = []byte(secret)
+	}
+
+	cfg.OTelEndpoint = os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT")
+	cfg.OTelServiceName = getEnv("OTEL_SERVICE_NAME", "go-api-first-steps")
+	sampleRatio, err := strconv.ParseFloat(getEnv("OTEL_TRACES_SAMPLER_ARG", "1"), 64)
+	if err != nil || sampleRatio < 0 || sampleRatio > 1 {
+		return nil, fmt.Errorf("OTEL_TRACES_SAMPLER_ARG inválido (use 0 a 1): %q", os.Getenv("OTEL_TRACES_SAMPLER_ARG"))
+	}
+	cfg.OTelSampleRatio = sampleRatio
+
+	cfg.HTTP3Enabled = strings.ToLower(os.Getenv("HTTP3_ENABLED")) == "true"
+	cfg.HTTP3Port = getEnv("HTTP3_PORT", cfg.Port)
+	if cfg.HTTP3Enabled && cfg.TLSCertFile == "" {
+		return nil, fmt.Errorf("ERRO CRITICO: HTTP3_ENABLED requer TLS_CERT_FILE e TLS_KEY_FILE")
+	}
+	if cfg.TLSCertFile == "" && (cfg.TLSClientCAFile != "" || cfg.MTLSClientsFile != "") {
+		return nil, fmt.Errorf("ERRO CRITICO: TLS_CLIENT_CA_FILE/MTLS_CLIENTS_FILE requerem TLS_CERT_FILE e TLS_KEY_FILE")
+	}
+
+	cfg.PolicyFile = os.Getenv("POLICY_FILE")
+	policyReload, err := time.ParseDuration(getEnv("POLICY_RELOAD_INTERVAL", "10s"))
+	if err != nil {
+		return nil, fmt.Errorf("POLICY_RELOAD_INTERVAL inválido: %w", err)
+	}
+	cfg.PolicyReloadInterval = policyReload
+
+	cfg.OIDCIssuersFile = os.Getenv("OIDC_ISSUERS_FILE")
+	cfg.TenantClaim = os.Getenv("TENANT_CLAIM")
+	cfg.UserAttributeClaims = getEnv("USER_ATTRIBUTE_CLAIMS", "categories")
+	cfg.OIDCStrict = strings.ToLower(os.Getenv("OIDC_STRICT")) == "true"
+	cfg.OIDCJWKSCacheFile = os.Getenv("OIDC_JWKS_CACHE_FILE")
+	retryMax, err := time.ParseDuration(getEnv("OIDC_RETRY_MAX_INTERVAL", "1m"))
+	if err != nil {
+		return nil, fmt.Errorf("OIDC_RETRY_MAX_INTERVAL inválido: %w", err)
+	}
+	cfg.OIDCRetryMaxInterval = retryMax
+
+	cfg.JWTIssuer = getEnv("JWT_ISSUER", cfg.KeycloakURL)
+	cfg.JWTAudience = getEnv("JWT_AUDIENCE", cfg.ClientID)
+	leeway, err := time.ParseDuration(getEnv("JWT_LEEWAY", "30s"))
+	if err != nil {
+		return nil, fmt.Errorf("JWT_LEEWAY inválido: %w", err)
+	}
+	cfg.JWTLeeway = leeway
+	keysReload, err := time.ParseDuration(getEnv("JWT_KEYS_RELOAD_INTERVAL", "30s"))
+	if err != nil {
+		return nil, fmt.Errorf("JWT_KEYS_RELOAD_INTERVAL inválido: %w", err)
+	}
+	cfg.JWTKeysReloadInterval = keysReload
+
+	cfg.IntrospectionMode = strings.ToLower(getEnv("INTROSPECTION_MODE", "off"))
+	switch cfg.IntrospectionMode {
+	case "off", "opaque", "always":
+	default:
+		return nil, fmt.Errorf("INTROSPECTION_MODE inválido: %q (use off, opaque ou always)", cfg.IntrospectionMode)
+	}
+	cfg.IntrospectionURL = getEnv("INTROSPECTION_URL",
+		strings.TrimSuffix(cfg.KeycloakURL, "/")+"/protocol/openid-connect/token/introspect")
+	cfg.IntrospectionClientID = getEnv("INTROSPECTION_CLIENT_ID", cfg.ClientID)
+	cfg.IntrospectionClientSecret = os.Getenv("INTROSPECTION_CLIENT_SECRET")
+	introspectionTTL, err := time.ParseDuration(getEnv("INTROSPECTION_CACHE_TTL", "30s"))
+	if err != nil {
+		return nil, fmt.Errorf("INTROSPECTION_CACHE_TTL inválido: %w", err)
+	}
+	cfg.IntrospectionCacheTTL = introspectionTTL
+
+	rateLimit, err := loadRateLimit()
+	if err != nil {
+		return nil, err
+	}
+	cfg.RateLimit = rateLimit
+
+	for _, proxy := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+		if proxy = strings.TrimSpace(proxy); proxy == "" {
+			continue
+		}
+		if _, _, err := net.ParseCIDR(proxy); err != nil && net.ParseIP(proxy) == nil {
+			return nil, fmt.Errorf("TRUSTED_PROXIES inválido: %q (use IPs ou CIDRs)", proxy)
+		}
+		cfg.TrustedProxies = append(cfg.TrustedProxies, proxy)
 	}
 
 	// Validação básica (apenas em modo produção)
-	if !cfg.DevMode {
-		if cfg.KeycloakURL == "" {
-			return nil, fmt.Errorf("ERRO CRITICO: KEYCLOAK_URL ausente (use DEV_MODE=true para desenvolvimento)")
+	switch cfg.AuthMode {
+	case "oidc", "offline":
+	default:
+		return nil, fmt.Errorf("AUTH_MODE inválido: %q (use oidc ou offline)", cfg.AuthMode)
+	}
+
+	if !cfg.DevMode && cfg.AuthMode == "offline" {
+		if cfg.JWTPublicKeys == "" && cfg.JWTInlinePublicKey == "" && cfg.JWTJWKSFile == "" {
+			return nil, fmt.Errorf("ERRO CRITICO: AUTH_MODE=offline requer JWT_PUBLIC_KEYS, KEYCLOAK_PUBLIC_KEY ou JWT_JWKS_FILE")
 		}
-		if cfg.ClientID == "" {
-			return nil, fmt.Errorf("ERRO CRITICO: KEYCLOAK_CLIENT_ID ausente (use DEV_MODE=true para desenvolvimento)")
+	} else if !cfg.DevMode {
+		// Com OIDC_ISSUERS_FILE os emissores vêm do arquivo (validado em middleware.LoadIssuers)
+		if cfg.OIDCIssuersFile == "" {
+			if cfg.KeycloakURL == "" {
+				return nil, fmt.Errorf("ERRO CRITICO: KEYCLOAK_URL ausente (use DEV_MODE=true para desenvolvimento)")
+			}
+			if cfg.ClientID == "" {
+				return nil, fmt.Errorf("ERRO CRITICO: KEYCLOAK_CLIENT_ID ausente (use DEV_MODE=true para desenvolvimento)")
+			}
 		}
 	} else {
-		slog.Warn("⚠️  Modo de desenvolvimento ativado - Autenticação pode estar desabilitada!")
+		if reasons := productionSignals(cfg); len(reasons) > 0 {
+			return nil, fmt.Errorf("ERRO CRITICO: DEV_MODE=true com configuração de produção (%s)", strings.Join(reasons, "; "))
+		}
+		slog.Warn("⚠️  Modo de desenvolvimento ativado - tokens não são validados!")
 	}
 
 	return cfg, nil
 }
 
+// productionSignals lista os indícios de que a configuração é de produção,
+// onde o DevMode (sem validação de token) nunca deve ser ligado.
+func productionSignals(cfg *Config) []string {
+	var reasons []string
+
+	switch strings.ToLower(os.Getenv("APP_ENV")) {
+	case "prod", "production":
+		reasons = append(reasons, "APP_ENV="+os.Getenv("APP_ENV"))
+	}
+	if os.Getenv("GIN_MODE") == "release" {
+		reasons = append(reasons, "GIN_MODE=release")
+	}
+	if cfg.KeycloakURL != "" && !isLocalURL(cfg.KeycloakURL, cfg.DevModeAllowHosts) {
+		reasons = append(reasons, "KEYCLOAK_URL aponta para "+cfg.KeycloakURL)
+	}
+	return reasons
+}
+
+// isLocalURL indica se a URL aponta para a máquina local (localhost ou loopback)
+// ou para um dos hosts liberados explicitamente (DEV_MODE_ALLOW_HOSTS).
+func isLocalURL(raw string, allowHosts []string) bool {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return false
+	}
+	host := strings.ToLower(u.Hostname())
+	if host == "localhost" || slices.Contains(allowHosts, host) {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// parseLogLevel lê um nível do slog (debug, info, warn, error ou "info+2"). Default: info.
+func parseLogLevel(key string) (slog.Level, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(getEnv(key, "info"))); err != nil {
+		return 0, fmt.Errorf("%s inválido: %w", key, err)
+	}
+	return level, nil
+}
+
 func getEnv(key, fallback string) string {
 	if value, exists := os.LookupEnv(key); exists {
 		return value
 	}
 	return fallback
 }
+
+// loadRateLimit lê RATE_LIMIT_* no formato "rps:burst", ex:
+//
+//	RATE_LIMIT_DEFAULT=10:20
+//	RATE_LIMIT_GROUPS=products=5:10
+//	RATE_LIMIT_ROLES=admin=50:100,manager=20:40
+func loadRateLimit() (RateLimitConfig, error) {
+	rl := RateLimitConfig{
+		Enabled: strings.ToLower(os.Getenv("RATE_LIMIT_ENABLED")) == "true",
+		KeyBy:   strings.ToLower(getEnv("RATE_LIMIT_KEY", "user")),
+	}
+
+	switch rl.KeyBy {
+	case "user", "client", "ip":
+	default:
+		return rl, fmt.Errorf("RATE_LIMIT_KEY inválido: %q (use user, client ou ip)", rl.KeyBy)
+	}
+
+	var err error
+	if rl.Default, err = ParseRateLimitRule(getEnv("RATE_LIMIT_DEFAULT", "10:20")); err != nil {
+		return rl, fmt.Errorf("RATE_LIMIT_DEFAULT inválido: %w", err)
+	}
+	if rl.Groups, err = parseRateLimitMap(os.Getenv("RATE_LIMIT_GROUPS")); err != nil {
+		return rl, fmt.Errorf("RATE_LIMIT_GROUPS inválido: %w", err)
+	}
+	if rl.Roles, err = parseRateLimitMap(os.Getenv("RATE_LIMIT_ROLES")); err != nil {
+		return rl, fmt.Errorf("RATE_LIMIT_ROLES inválido: %w", err)
+	}
+	return rl, nil
+}
+
+// ParseRateLimitRule converte "rps:burst" (ex: "10:20" ou "0.5:1") em RateLimitRule.
+func ParseRateLimitRule(s string) (RateLimitRule, error) {
+	rpsStr, burstStr, ok := strings.Cut(strings.TrimSpace(s), ":")
+	if !ok {
+		return RateLimitRule{}, fmt.Errorf("formato esperado rps:burst, recebido %q", s)
+	}
+
+	rps, err := strconv.ParseFloat(rpsStr, 64)
+	if err != nil || rps <= 0 {
+		return RateLimitRule{}, fmt.Errorf("rps inválido em %q", s)
+	}
+	burst, err := strconv.Atoi(burstStr)
+	if err != nil || burst < 1 {
+		return RateLimitRule{}, fmt.Errorf("burst inválido em %q", s)
+	}
+	return RateLimitRule{RPS: rps, Burst: burst}, nil
+}
+
+// parseRateLimitMap converte "nome=rps:burst,nome2=rps:burst" em mapa.
+func parseRateLimitMap(s string) (map[string]RateLimitRule, error) {
+	rules := make(map[string]RateLimitRule)
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, ruleStr, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("formato esperado nome=rps:burst, recebido %q", entry)
+		}
+		rule, err := ParseRateLimitRule(ruleStr)
+		if err != nil {
+			return nil, err
+		}
+		rules[strings.TrimSpace(name)] = rule
+	}
+	return rules, nil
+}
@@ -0,0 +1,81 @@
+package config_test
+
+import (
+	"testing"
+
+	"go-api-first-steps/internal/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad_DevModeDefaults(t *testing.T) {
+	t.Setenv("DEV_MODE", "true")
+
+	cfg, err := config.Load()
+	require.NoError(t, err)
+	assert.Equal(t, "dev-user", cfg.DevUser)
+	assert.Equal(t, "admin", cfg.DevRoles)
+	assert.Equal(t, "client", cfg.RoleSources, "realm roles só concedem permissões se habilitadas")
+}
+
+func TestLoad_DevModeRefusesProductionConfig(t *testing.T) {
+	cases := map[string]map[string]string{
+		"APP_ENV":      {"APP_ENV": "production"},
+		"GIN_MODE":     {"GIN_MODE": "release"},
+		"KEYCLOAK_URL": {"KEYCLOAK_URL": "https://sso.empresa.com.br/realms/prod"},
+		// Sem domínio não quer dizer local: no Kubernetes é um Service do cluster
+		"KEYCLOAK_URL sem domínio": {"KEYCLOAK_URL": "http://keycloak:8080/realms/prod"},
+		"host liberado é outro":    {"KEYCLOAK_URL": "http://keycloak:8080/realms/prod", "DEV_MODE_ALLOW_HOSTS": "sso-dev"},
+	}
+
+	for name, env := range cases {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv("DEV_MODE", "true")
+			for k, v := range env {
+				t.Setenv(k, v)
+			}
+			_, err := config.Load()
+			assert.ErrorContains(t, err, "DEV_MODE")
+		})
+	}
+
+	// Keycloak local é permitido; o do docker compose só se liberado explicitamente
+	t.Setenv("DEV_MODE", "true")
+	for _, url := range []string{"http://localhost:8080/realms/dev", "http://127.0.0.1:8180/realms/mock", "http://[::1]:8080/realms/dev"} {
+		t.Setenv("KEYCLOAK_URL", url)
+		_, err := config.Load()
+		assert.NoError(t, err, url)
+	}
+	t.Setenv("KEYCLOAK_URL", "http://keycloak:8080/realms/dev")
+	t.Setenv("DEV_MODE_ALLOW_HOSTS", "keycloak, mock-oidc")
+	_, err := config.Load()
+	assert.NoError(t, err)
+}
+
+func TestLoad_TrustedProxies(t *testing.T) {
+	t.Setenv("DEV_MODE", "true")
+	t.Setenv("TRUSTED_PROXIES", "10.0.0.0/8, 192.168.1.10")
+
+	cfg, err := config.Load()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"10.0.0.0/8", "192.168.1.10"}, cfg.TrustedProxies)
+
+	t.Setenv("TRUSTED_PROXIES", "proxy.interno")
+	_, err = config.Load()
+	assert.ErrorContains(t, err, "TRUSTED_PROXIES")
+}
+
+func TestLoad_IssuersFileInProduction(t *testing.T) {
+	// Multi-issuer dispensa KEYCLOAK_URL/KEYCLOAK_CLIENT_ID e não cai no DevMode
+	t.Setenv("DEV_MODE", "false")
+	t.Setenv("GIN_MODE", "release")
+	t.Setenv("KEYCLOAK_URL", "")
+	t.Setenv("KEYCLOAK_CLIENT_ID", "")
+	t.Setenv("OIDC_ISSUERS_FILE", "/etc/api/issuers.yaml")
+
+	cfg, err := config.Load()
+	require.NoError(t, err)
+	assert.False(t, cfg.DevMode)
+	assert.Equal(t, "/etc/api/issuers.yaml", cfg.OIDCIssuersFile)
+}
@@ -1,16 +1,37 @@
 package dependencies
 
 import (
+	"context"
+	"log/slog"
+
+	"go-api-first-steps/internal/authz"
 	"go-api-first-steps/internal/config"
 	"go-api-first-steps/internal/handlers"
+	"go-api-first-steps/internal/middleware"
+	"go-api-first-steps/internal/services/apikey"
 	"go-api-first-steps/internal/services/product"
 	sqliteRepo "go-api-first-steps/internal/storage/sqlite"
+	"go-api-first-steps/internal/tlsconfig"
+	"go-api-first-steps/pkg/logger"
 )
 
 // Container mantém todas as dependências da aplicação inicializadas.
 // Centraliza a criação de objetos (Wiring) para manter o main.go limpo.
 type Container struct {
-	ProductHandler *handlers.ProductHandler
+	Authenticator    *middleware.Authenticator
+	KeySet           *middleware.FileKeySet // Só em AUTH_MODE=offline
+	TLS              *tlsconfig.Reloader    // nil = servidor sem TLS
+	Enforcer         *authz.Enforcer
+	IdempotencyStore middleware.IdempotencyStore
+	RateLimiter      *middleware.RateLimiter
+	ProductService   *product.Service
+	APIKeyService    *apikey.Service
+	ProductHandler   *handlers.ProductHandler
+	PolicyHandler    *handlers.PolicyHandler
+	APIKeyHandler    *handlers.APIKeyHandler
+	TokenHandler     *handlers.TokenHandler
+	LogLevelHandler  *handlers.LogLevelHandler
+	ReadinessHandler *handlers.ReadinessHandler
 }
 
 // NewContainer inicializa todas as dependências do projeto.
@@ -19,13 +40,130 @@ func NewContainer(cfg *config.Config) *Container {
 	// Repositories
 	repo := sqliteRepo.NewRepository(cfg.DBUrl)
 
+	apiKeyRepo := sqliteRepo.NewAPIKeyRepository(repo.DB)
+
+	// Authorization (política declarativa de rotas e regras por atributos)
+	enforcer, err := authz.NewEnforcer(cfg.PolicyFile)
+	if err != nil {
+		panic("falha ao carregar política de autorização: " + err.Error())
+	}
+
 	// Services
 	service := product.NewService(repo)
+	service.Authorizer = enforcer
+	apiKeyService := apikey.NewService(apiKeyRepo)
 
 	// Handlers
 	productHandler := &handlers.ProductHandler{Service: service}
 
+	authenticator, keySet := newAuthenticator(cfg)
+	// Tenant dos produtos: claim do token ou o emissor (multi-issuer)
+	if err := authenticator.SetTenantClaim(cfg.TenantClaim); err != nil {
+		panic(err.Error())
+	}
+	if authenticator.AttributeClaims, err = middleware.ParseAttributeClaims(cfg.UserAttributeClaims); err != nil {
+		panic(err.Error())
+	}
+
+	// TLS do servidor e identidades de serviço por certificado de cliente (mTLS)
+	var tlsReloader *tlsconfig.Reloader
+	if cfg.TLSCertFile != "" {
+		tlsReloader, err = tlsconfig.New(tlsconfig.Options{
+			CertFile:     cfg.TLSCertFile,
+			KeyFile:      cfg.TLSKeyFile,
+			ClientCAFile: cfg.TLSClientCAFile,
+			ClientAuth:   cfg.TLSClientAuth,
+			MinVersion:   cfg.TLSMinVersion,
+			CipherSuites: cfg.TLSCipherSuites,
+		})
+		if err != nil {
+			panic("falha ao configurar TLS: " + err.Error())
+		}
+	}
+	if cfg.MTLSClientsFile != "" {
+		if authenticator.ClientCerts, err = middleware.LoadClientCertMapper(cfg.MTLSClientsFile); err != nil {
+			panic(err.Error())
+		}
+	}
+	// API keys de serviço são aceitas junto com os tokens OIDC
+	authenticator.APIKeys = apiKeyService
+	revocations := middleware.NewMemoryRevocationList()
+	authenticator.Revocations = revocations
+	if cfg.IntrospectionMode != "off" {
+		authenticator.Introspector = middleware.NewIntrospector(
+			cfg.IntrospectionURL, cfg.IntrospectionClientID, cfg.IntrospectionClientSecret, cfg.IntrospectionCacheTTL)
+		authenticator.IntrospectAll = cfg.IntrospectionMode == "always"
+	}
+
 	return &Container{
-		ProductHandler: productHandler,
+		Authenticator:    authenticator,
+		KeySet:           keySet,
+		TLS:              tlsReloader,
+		Enforcer:         enforcer,
+		IdempotencyStore: middleware.NewMemoryIdempotencyStore(),
+		RateLimiter:      middleware.NewRateLimiter(middleware.NewMemoryRateLimitStore(), cfg.RateLimit),
+		ProductService:   service,
+		APIKeyService:    apiKeyService,
+		ProductHandler:   productHandler,
+		PolicyHandler:    &handlers.PolicyHandler{Enforcer: enforcer},
+		APIKeyHandler:    &handlers.APIKeyHandler{Service: apiKeyService},
+		TokenHandler:     &handlers.TokenHandler{Revocations: revocations},
+		LogLevelHandler:  &handlers.LogLevelHandler{Levels: logger.Controller(), DebugSecret: cfg.LogDebugSecret},
+		ReadinessHandler: &handlers.ReadinessHandler{Discoveries: authenticator.Discoveries()},
+	}
+}
+
+// newAuthenticator inicializa o Authenticator compartilhado pelo REST e pelo gRPC.
+// Em AUTH_MODE=offline também retorna o KeySet, para o main vigiar os arquivos de chaves.
+func newAuthenticator(cfg *config.Config) (*middleware.Authenticator, *middleware.FileKeySet) {
+	if cfg.DevMode {
+		slog.WarnContext(
+			context.Background(),
+			"⚠️  DevMode ativo - usando autenticador de desenvolvimento (sem validação real)",
+			"dev_user", cfg.DevUser, "dev_roles", cfg.DevRoles)
+		return middleware.NewDevAuthenticator(middleware.User{
+			ID:       cfg.DevUser,
+			Username: cfg.DevUser,
+			Roles:    middleware.ParseDevRoles(cfg.DevRoles),
+		}), nil
+	}
+
+	if cfg.AuthMode == "offline" {
+		keySet, err := middleware.NewFileKeySet(
+			middleware.ParseKeyFiles(cfg.JWTPublicKeys), cfg.JWTJWKSFile, cfg.JWTInlinePublicKey)
+		if err != nil {
+			panic("falha ao carregar chaves JWT: " + err.Error())
+		}
+		authenticator, err := middleware.NewOfflineAuthenticator(cfg, keySet)
+		if err != nil {
+			panic("falha ao inicializar autenticação offline: " + err.Error())
+		}
+		return authenticator, keySet
+	}
+
+	var authenticator *middleware.Authenticator
+	if cfg.OIDCIssuersFile != "" {
+		// Multi-issuer: um emissor (realm) por tenant
+		issuers, err := middleware.LoadIssuers(cfg.OIDCIssuersFile)
+		if err != nil {
+			panic("falha ao carregar emissores: " + err.Error())
+		}
+		authenticator, err = middleware.NewMultiIssuerAuthenticator(issuers, cfg.OIDCJWKSCacheFile, cfg.OIDCRetryMaxInterval)
+		if err != nil {
+			panic("falha ao inicializar autenticação: " + err.Error())
+		}
+	} else {
+		var err error
+		authenticator, err = middleware.NewOIDCAuthenticator(cfg)
+		if err != nil {
+			panic("falha ao inicializar autenticação: " + err.Error())
+		}
+	}
+
+	// Provedor fora do ar: no modo estrito não sobe; senão sobe (503 nas rotas
+	// protegidas, ou JWKS do cache) e o main continua a descoberta em background
+	if err := authenticator.InitDiscoveries(context.Background()); err != nil && cfg.OIDCStrict {
+		panic("OIDC_STRICT: " + err.Error())
 	}
+	return authenticator, nil
 }
@@ -0,0 +1,27 @@
+package domain
+
+import "time"
+
+// APIKey é uma credencial de serviço (jobs, parceiros) para acesso sem OIDC interativo.
+// Só o hash do segredo é guardado; o Prefix (público) identifica a chave em logs e na listagem.
+type APIKey struct {
+	ID         uint       `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Hash       string     `json:"-"`
+	Roles      []string   `json:"roles"`
+	CreatedBy  string     `json:"created_by"`
+	Tenant     string     `json:"tenant,omitempty"` // Tenant em que o serviço atua (o de quem criou a chave)
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+// Active indica se a chave pode ser usada no instante now.
+func (k *APIKey) Active(now time.Time) bool {
+	if k.RevokedAt != nil {
+		return false
+	}
+	return k.ExpiresAt == nil || now.Before(*k.ExpiresAt)
+}
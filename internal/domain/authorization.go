@@ -0,0 +1,16 @@
+package domain
+
+// Actor é quem executa uma operação de negócio. As camadas de transporte o
+// montam a partir do usuário autenticado (middleware.User.Actor).
+type Actor struct {
+	ID         string
+	Tenant     string
+	Roles      []string
+	Attributes map[string][]string // Atributos do token (ex: categories), usados nas regras ABAC
+}
+
+// Authorizer decide se o Actor pode executar action (ex: "products:update") sobre
+// um recurso descrito por seus atributos. Devolve nil ou um erro que envolve ErrForbidden.
+type Authorizer interface {
+	Authorize(actor Actor, action string, resource map[string]string) error
+}
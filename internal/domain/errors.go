@@ -0,0 +1,28 @@
+package domain
+
+import "errors"
+
+// Erros de domínio. As camadas de transporte (REST, gRPC) os traduzem
+// para o status adequado sem depender do ORM.
+var (
+	// ErrProductNotFound indica que o produto não existe (ou foi removido).
+	ErrProductNotFound = errors.New("produto não encontrado")
+
+	// ErrInvalidProductName indica que o nome do produto é inválido (ex: vazio).
+	ErrInvalidProductName = errors.New("nome vazio")
+
+	// ErrDuplicateProductName indica que já existe um produto com o mesmo nome no tenant.
+	ErrDuplicateProductName = errors.New("já existe um produto com este nome")
+
+	// ErrInvalidProductStatus indica um status de produto desconhecido.
+	ErrInvalidProductStatus = errors.New("status inválido (use draft ou published)")
+
+	// ErrForbidden indica que as regras de acesso (roles + atributos) negaram a operação.
+	ErrForbidden = errors.New("sem permissão para esta operação")
+
+	// ErrAPIKeyNotFound indica que a API key não existe.
+	ErrAPIKeyNotFound = errors.New("API key não encontrada")
+
+	// ErrInvalidAPIKey indica que a API key é desconhecida, está revogada ou expirou.
+	ErrInvalidAPIKey = errors.New("API key inválida")
+)
@@ -1,6 +1,15 @@
 package domain
 
-import "time"
+import (
+	"strconv"
+	"time"
+)
+
+// Status de publicação do produto
+const (
+	ProductStatusDraft     = "draft"
+	ProductStatusPublished = "published"
+)
 
 // Product representa a entidade de domínio Produto.
 // Esta struct é agnóstica de framework e pode ser usada em qualquer camada.
@@ -8,7 +17,22 @@ type Product struct {
 	ID        uint       `json:"id"`
 	Name      string     `json:"name"`
 	Price     float64    `json:"price"`
+	Category  string     `json:"category"`
+	Status    string     `json:"status"`
+	CreatedBy string     `json:"created_by"`
+	UpdatedBy string     `json:"updated_by"`
 	CreatedAt time.Time  `json:"created_at"`
 	UpdatedAt time.Time  `json:"updated_at"`
 	DeletedAt *time.Time `json:"deleted_at,omitempty"`
 }
+
+// Attributes descreve o produto para as regras de autorização (resource.<atributo>).
+func (p *Product) Attributes() map[string]string {
+	return map[string]string{
+		"id":         strconv.FormatUint(uint64(p.ID), 10),
+		"category":   p.Category,
+		"status":     p.Status,
+		"created_by": p.CreatedBy,
+		"updated_by": p.UpdatedBy,
+	}
+}
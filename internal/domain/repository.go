@@ -1,10 +1,23 @@
 package domain
 
+import (
+	"context"
+	"time"
+)
+
 // ProductRepository define o contrato para persistência de produtos.
 // Qualquer implementação (SQLite, PostgreSQL, MongoDB) deve seguir esta interface.
+//
+// Implementações são restritas a um tenant: nenhum método lê ou altera produtos de outro.
 type ProductRepository interface {
+	// WithTenant devolve o repositório restrito ao tenant informado.
+	WithTenant(tenant string) ProductRepository
+
+	// WithContext devolve o repositório cujas consultas usam ctx (cancelamento e trace).
+	WithContext(ctx context.Context) ProductRepository
+
 	// Save persiste um novo produto e retorna o produto criado.
-	Save(name string) (*Product, error)
+	Save(p Product) (*Product, error)
 
 	// FindAll retorna uma lista paginada de produtos.
 	FindAll(page, pageSize int) ([]Product, error)
@@ -12,9 +25,28 @@ type ProductRepository interface {
 	// FindByID busca um produto pelo ID.
 	FindByID(id uint) (*Product, error)
 
-	// Update atualiza o nome de um produto existente.
-	Update(id uint, name string) error
+	// Update grava nome, categoria, status e updated_by de um produto existente.
+	Update(p *Product) error
 
 	// Delete remove um produto (soft delete).
 	Delete(id uint) error
 }
+
+// APIKeyRepository define o contrato para persistência de API keys.
+type APIKeyRepository interface {
+	// Save persiste uma nova chave (preenche ID e CreatedAt).
+	Save(key *APIKey) error
+
+	// FindAll retorna as chaves do tenant, inclusive revogadas e expiradas.
+	FindAll(tenant string) ([]APIKey, error)
+
+	// FindByPrefix busca uma chave pelo prefixo público.
+	FindByPrefix(prefix string) (*APIKey, error)
+
+	// Revoke marca a chave do tenant como revogada em at. Chave de outro tenant
+	// é ErrAPIKeyNotFound.
+	Revoke(tenant string, id uint, at time.Time) error
+
+	// TouchLastUsed registra o último uso da chave.
+	TouchLastUsed(id uint, at time.Time) error
+}
@@ -0,0 +1,247 @@
+package grpcapi
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"log/slog"
+	"strings"
+
+	"go-api-first-steps/internal/authz"
+	"go-api-first-steps/internal/middleware"
+	"go-api-first-steps/internal/tracing"
+	"go-api-first-steps/pkg/logger"
+
+	otelcodes "go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// traceIDMetadataKey é o equivalente gRPC do header X-Trace-ID (metadata é sempre minúsculo)
+const traceIDMetadataKey = "x-trace-id"
+
+// publicServices não exigem autenticação; todo o resto passa pela política
+var publicServices = []string{
+	"/grpc.health.v1.Health/",
+	"/grpc.reflection.v1.ServerReflection/",
+	"/grpc.reflection.v1alpha.ServerReflection/",
+}
+
+// TraceUnaryInterceptor abre o span do RPC continuando o trace do cliente (metadata
+// traceparent/tracestate, ou o x-trace-id legado), grava o ID legado no contexto
+// (logger.TraceIDKey) e o devolve no header "x-trace-id" da resposta.
+func TraceUnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, span := startSpan(ctx, info.FullMethod)
+		resp, err := handler(ctx, req)
+		endSpan(span, err)
+		return resp, err
+	}
+}
+
+// TraceStreamInterceptor é a versão streaming de TraceUnaryInterceptor.
+func TraceStreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, span := startSpan(ss.Context(), info.FullMethod)
+		err := handler(srv, &wrappedStream{ServerStream: ss, ctx: ctx})
+		endSpan(span, err)
+		return err
+	}
+}
+
+func startSpan(ctx context.Context, fullMethod string) (context.Context, trace.Span) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	ctx = tracing.Extract(ctx, metadataCarrier(md))
+
+	service, method, _ := strings.Cut(strings.TrimPrefix(fullMethod, "/"), "/")
+	ctx, span := tracing.Tracer().Start(ctx, strings.TrimPrefix(fullMethod, "/"),
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(semconv.RPCSystemGRPC, semconv.RPCService(service), semconv.RPCMethod(method)),
+	)
+
+	traceID := tracing.LegacyTraceID(ctx, metadataCarrier(md).Get(traceIDMetadataKey))
+	_ = grpc.SetHeader(ctx, metadata.Pairs(traceIDMetadataKey, traceID))
+	ctx = logger.WithRoute(ctx, fullMethod) // Overrides de nível por método
+	return context.WithValue(ctx, logger.TraceIDKey, traceID), span
+}
+
+func endSpan(span trace.Span, err error) {
+	code := status.Code(err)
+	span.SetAttributes(semconv.RPCGRPCStatusCodeKey.Int(int(code)))
+	if code != codes.OK {
+		span.SetStatus(otelcodes.Error, status.Convert(err).Message())
+	}
+	span.End()
+}
+
+// metadataCarrier adapta a metadata gRPC ao propagador W3C.
+type metadataCarrier metadata.MD
+
+func (c metadataCarrier) Get(key string) string {
+	if values := metadata.MD(c).Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+func (c metadataCarrier) Set(key, value string) {
+	metadata.MD(c).Set(key, value)
+}
+
+func (c metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for k := range c {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// AuthUnaryInterceptor valida o token Bearer da metadata "authorization" usando o
+// mesmo Authenticator do middleware REST e autoriza o método pela política
+// (chave "GRPC /pacote.Servico/Metodo"). Métodos sem regra são negados.
+func AuthUnaryInterceptor(auth *middleware.Authenticator, enforcer *authz.Enforcer) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, err := authorize(ctx, auth, enforcer, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// AuthStreamInterceptor é a versão streaming de AuthUnaryInterceptor.
+func AuthStreamInterceptor(auth *middleware.Authenticator, enforcer *authz.Enforcer) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authorize(ss.Context(), auth, enforcer, info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &wrappedStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+func authorize(ctx context.Context, auth *middleware.Authenticator, enforcer *authz.Enforcer, method string) (context.Context, error) {
+	for _, prefix := range publicServices {
+		if strings.HasPrefix(method, prefix) {
+			return ctx, nil
+		}
+	}
+
+	// Sem Authenticator não há identidade: nega em vez de liberar sem política nem
+	// tenant. Testes sem autenticação usam um Authenticator em DevMode.
+	if auth == nil {
+		return nil, status.Error(codes.Unauthenticated, "Autenticação não configurada")
+	}
+
+	user, err := authenticate(ctx, auth, method)
+	if err != nil {
+		return nil, err
+	}
+
+	decision := enforcer.Decide(user.Roles, authz.GRPCMethod, method)
+	middleware.RecordAuthz("grpc", decision.Allowed)
+	if !decision.Allowed {
+		slog.WarnContext(ctx, "Acesso negado pela política", "user_id", user.ID, "method", method, "reason", decision.Reason)
+		return nil, status.Error(codes.PermissionDenied, "Sem permissão")
+	}
+
+	return middleware.WithUser(ctx, user), nil
+}
+
+// authenticate extrai o usuário do metadata: token Bearer ou, em DevMode,
+// a identidade sintética (x-dev-user / x-dev-roles).
+func authenticate(ctx context.Context, auth *middleware.Authenticator, method string) (*middleware.User, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	if auth.DevMode {
+		var roles []string
+		if values := md.Get(middleware.DevRolesHeader); len(values) > 0 {
+			roles = middleware.ParseDevRoles(values[0])
+		}
+		var userID string
+		if values := md.Get(middleware.DevUserHeader); len(values) > 0 {
+			userID = values[0]
+		}
+		user := auth.DevIdentity(userID, roles)
+		if values := md.Get(middleware.DevTenantHeader); len(values) > 0 {
+			user.Tenant = values[0]
+		}
+		slog.DebugContext(ctx, "DevMode: identidade sintética", "user_id", user.ID, "method", method)
+		middleware.RecordAuth("grpc", middleware.CredentialDev, nil)
+		return user, nil
+	}
+
+	var authHeader, apiKeyHeader string
+	if values := md.Get("authorization"); len(values) > 0 {
+		authHeader = values[0]
+	}
+	if values := md.Get(middleware.APIKeyHeader); len(values) > 0 {
+		apiKeyHeader = values[0]
+	}
+	token, apiKey := middleware.Credentials(authHeader, apiKeyHeader)
+
+	var user *middleware.User
+	var err error
+	credential := middleware.CredentialToken
+	if apiKey != "" {
+		credential = middleware.CredentialAPIKey
+		user, err = auth.AuthenticateAPIKey(ctx, apiKey)
+	} else if tlsState := peerTLS(ctx); authHeader == "" && auth.ClientCerts != nil && middleware.HasClientCert(tlsState) {
+		credential = middleware.CredentialClientCert
+		user, err = auth.AuthenticateClientCert(tlsState)
+	} else {
+		if authHeader == "" && auth.ExpectsToken() {
+			middleware.RecordAuth("grpc", middleware.CredentialNone, nil)
+			return nil, status.Error(codes.Unauthenticated, "Token não informado")
+		}
+		user, err = auth.Authenticate(ctx, token)
+	}
+	middleware.RecordAuth("grpc", credential, err)
+
+	switch {
+	case errors.Is(err, middleware.ErrAuthNotConfigured):
+		return nil, status.Error(codes.Internal, "Autenticação não configurada")
+	case errors.Is(err, middleware.ErrAuthUnavailable):
+		return nil, status.Error(codes.Unavailable, "Autenticação indisponível, tente novamente")
+	case errors.Is(err, middleware.ErrInvalidToken):
+		slog.WarnContext(ctx, "Token inválido", "error", err, "method", method)
+		return nil, status.Error(codes.Unauthenticated, "Token inválido")
+	case errors.Is(err, middleware.ErrInvalidAPIKey):
+		slog.WarnContext(ctx, "API key inválida", "error", err, "method", method)
+		return nil, status.Error(codes.Unauthenticated, "API key inválida")
+	case errors.Is(err, middleware.ErrInvalidClientCert):
+		slog.WarnContext(ctx, "Certificado de cliente não autorizado", "error", err, "method", method)
+		return nil, status.Error(codes.Unauthenticated, "Certificado de cliente não autorizado")
+	case err != nil:
+		return nil, status.Error(codes.Internal, "Erro ao ler claims")
+	}
+	return user, nil
+}
+
+// peerTLS devolve o estado TLS da conexão (nil sem TLS).
+func peerTLS(ctx context.Context) *tls.ConnectionState {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return nil
+	}
+	if info, ok := p.AuthInfo.(credentials.TLSInfo); ok {
+		return &info.State
+	}
+	return nil
+}
+
+// wrappedStream permite substituir o contexto de um grpc.ServerStream
+type wrappedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (w *wrappedStream) Context() context.Context {
+	return w.ctx
+}
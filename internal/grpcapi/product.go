@@ -0,0 +1,147 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"go-api-first-steps/internal/domain"
+	"go-api-first-steps/internal/middleware"
+	"go-api-first-steps/internal/services/product"
+	productv1 "go-api-first-steps/pkg/pb/product/v1"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// ProductServer implementa productv1.ProductServiceServer sobre o product.Service.
+type ProductServer struct {
+	productv1.UnimplementedProductServiceServer
+	Service *product.Service
+}
+
+// service devolve o Service que opera em nome do usuário autenticado (tenant, autoria e regras ABAC)
+// e no contexto da requisição (trace).
+func (s *ProductServer) service(ctx context.Context) *product.Service {
+	return s.Service.ForActor(middleware.UserFromContext(ctx).Actor()).WithContext(ctx)
+}
+
+func (s *ProductServer) ListProducts(ctx context.Context, req *productv1.ListProductsRequest) (*productv1.ListProductsResponse, error) {
+	products, err := s.service(ctx).ListProducts(int(req.GetPage()), int(req.GetPageSize()))
+	if err != nil {
+		return nil, toStatus(ctx, "Erro ao listar", err)
+	}
+
+	resp := &productv1.ListProductsResponse{Products: make([]*productv1.Product, len(products))}
+	for i := range products {
+		resp.Products[i] = toProto(&products[i])
+	}
+	return resp, nil
+}
+
+// StreamProducts percorre todas as páginas do Service e envia um produto por mensagem.
+func (s *ProductServer) StreamProducts(req *productv1.StreamProductsRequest, stream productv1.ProductService_StreamProductsServer) error {
+	ctx := stream.Context()
+	pageSize := int(req.GetPageSize())
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 10
+	}
+
+	svc := s.service(ctx)
+	for page := 1; ; page++ {
+		if err := ctx.Err(); err != nil {
+			return status.FromContextError(err).Err()
+		}
+
+		products, err := svc.ListProducts(page, pageSize)
+		if err != nil {
+			return toStatus(ctx, "Erro ao listar", err)
+		}
+
+		for i := range products {
+			if err := stream.Send(toProto(&products[i])); err != nil {
+				return err
+			}
+		}
+
+		if len(products) < pageSize {
+			return nil
+		}
+	}
+}
+
+func (s *ProductServer) GetProduct(ctx context.Context, req *productv1.GetProductRequest) (*productv1.GetProductResponse, error) {
+	if req.GetId() == 0 {
+		return nil, status.Error(codes.InvalidArgument, "ID inválido: deve ser maior que zero")
+	}
+
+	p, err := s.service(ctx).GetProduct(uint(req.GetId()))
+	if err != nil {
+		return nil, toStatus(ctx, "Erro ao buscar", err)
+	}
+	return &productv1.GetProductResponse{Product: toProto(p)}, nil
+}
+
+func (s *ProductServer) CreateProduct(ctx context.Context, req *productv1.CreateProductRequest) (*productv1.CreateProductResponse, error) {
+	slog.InfoContext(ctx, "Criando produto", "name", req.GetName())
+
+	name, err := s.service(ctx).CreateProduct(product.ProductInput{Name: req.GetName(), Category: req.GetCategory(), Status: req.GetStatus()})
+	if err != nil {
+		return nil, toStatus(ctx, "Erro ao criar", err)
+	}
+	return &productv1.CreateProductResponse{Name: name}, nil
+}
+
+func (s *ProductServer) UpdateProduct(ctx context.Context, req *productv1.UpdateProductRequest) (*productv1.UpdateProductResponse, error) {
+	if req.GetId() == 0 {
+		return nil, status.Error(codes.InvalidArgument, "ID inválido: deve ser maior que zero")
+	}
+
+	in := product.ProductInput{Name: req.GetName(), Category: req.GetCategory(), Status: req.GetStatus()}
+	if err := s.service(ctx).UpdateProduct(uint(req.GetId()), in); err != nil {
+		return nil, toStatus(ctx, "Erro ao atualizar", err)
+	}
+	return &productv1.UpdateProductResponse{}, nil
+}
+
+func (s *ProductServer) DeleteProduct(ctx context.Context, req *productv1.DeleteProductRequest) (*productv1.DeleteProductResponse, error) {
+	if req.GetId() == 0 {
+		return nil, status.Error(codes.InvalidArgument, "ID inválido: deve ser maior que zero")
+	}
+
+	if err := s.service(ctx).DeleteProduct(uint(req.GetId())); err != nil {
+		return nil, toStatus(ctx, "Erro ao deletar", err)
+	}
+	return &productv1.DeleteProductResponse{}, nil
+}
+
+// toStatus traduz erros de domínio em códigos gRPC. Erros inesperados viram
+// codes.Internal com a mensagem genérica (o detalhe vai só para o log).
+func toStatus(ctx context.Context, msg string, err error) error {
+	switch {
+	case errors.Is(err, domain.ErrProductNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, domain.ErrInvalidProductName), errors.Is(err, domain.ErrInvalidProductStatus):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, domain.ErrDuplicateProductName):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errors.Is(err, domain.ErrForbidden):
+		return status.Error(codes.PermissionDenied, domain.ErrForbidden.Error())
+	default:
+		slog.ErrorContext(ctx, msg, "error", err)
+		return status.Error(codes.Internal, msg)
+	}
+}
+
+func toProto(p *domain.Product) *productv1.Product {
+	return &productv1.Product{
+		Id:        uint64(p.ID),
+		Name:      p.Name,
+		Price:     p.Price,
+		Category:  p.Category,
+		Status:    p.Status,
+		CreatedAt: timestamppb.New(p.CreatedAt),
+		UpdatedAt: timestamppb.New(p.UpdatedAt),
+	}
+}
@@ -0,0 +1,78 @@
+package grpcapi
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"go-api-first-steps/internal/authz"
+	"go-api-first-steps/internal/middleware"
+	"go-api-first-steps/internal/services/product"
+	productv1 "go-api-first-steps/pkg/pb/product/v1"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+)
+
+// NewServer cria o servidor gRPC com os serviços de produto, health e reflection.
+//
+// Ele registra:
+//   - Interceptor de tracing (span por RPC, traceparent W3C e x-trace-id legado).
+//   - Interceptor de autenticação Bearer (reusa o Authenticator e a política do REST).
+//   - product.v1.ProductService.
+//   - grpc.health.v1.Health e Server Reflection (para grpcurl, Postman, etc).
+//
+// opts são repassadas ao grpc.NewServer (ex: grpc.Creds para TLS em porta própria).
+func NewServer(auth *middleware.Authenticator, enforcer *authz.Enforcer, svc *product.Service, opts ...grpc.ServerOption) *grpc.Server {
+	srv := grpc.NewServer(append([]grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(
+			TraceUnaryInterceptor(),
+			AuthUnaryInterceptor(auth, enforcer),
+		),
+		grpc.ChainStreamInterceptor(
+			TraceStreamInterceptor(),
+			AuthStreamInterceptor(auth, enforcer),
+		),
+	}, opts...)...)
+
+	productv1.RegisterProductServiceServer(srv, &ProductServer{Service: svc})
+
+	healthSrv := health.NewServer()
+	healthSrv.SetServingStatus(productv1.ProductService_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_SERVING)
+	healthpb.RegisterHealthServer(srv, healthSrv)
+
+	reflection.Register(srv)
+
+	return srv
+}
+
+// Multiplex devolve um http.Handler que encaminha chamadas gRPC (HTTP/2 com
+// Content-Type application/grpc) para o servidor gRPC e o resto para o handler REST.
+// O http.Server precisa aceitar HTTP/2 sem TLS (h2c) para isso funcionar.
+func Multiplex(grpcServer *grpc.Server, rest http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ProtoMajor == 2 && strings.HasPrefix(r.Header.Get("Content-Type"), "application/grpc") {
+			grpcServer.ServeHTTP(w, r)
+			return
+		}
+		rest.ServeHTTP(w, r)
+	})
+}
+
+// Stop tenta um GracefulStop (aguarda RPCs em andamento) e força Stop
+// se o contexto expirar antes.
+func Stop(ctx context.Context, grpcServer *grpc.Server) {
+	done := make(chan struct{})
+	go func() {
+		grpcServer.GracefulStop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		grpcServer.Stop()
+	}
+}
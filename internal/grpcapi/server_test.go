@@ -0,0 +1,269 @@
+package grpcapi_test
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go-api-first-steps/internal/authz"
+	"go-api-first-steps/internal/grpcapi"
+	"go-api-first-steps/internal/middleware"
+	"go-api-first-steps/internal/services/product"
+	storage "go-api-first-steps/internal/storage/sqlite"
+	productv1 "go-api-first-steps/pkg/pb/product/v1"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+const (
+	testIssuer   = "http://issuer.test/realms/test"
+	testClientID = "product-api"
+)
+
+// setupServer sobe o servidor gRPC em memória (bufconn) com um Authenticator
+// que valida tokens assinados pela chave gerada no teste.
+func setupServer(t *testing.T) (productv1.ProductServiceClient, *grpc.ClientConn, *rsa.PrivateKey) {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	keySet := &oidc.StaticKeySet{PublicKeys: []crypto.PublicKey{key.Public()}}
+	auth := &middleware.Authenticator{
+		Verifier: oidc.NewVerifier(testIssuer, keySet, &oidc.Config{ClientID: testClientID}),
+		ClientID: testClientID,
+	}
+
+	svc := product.NewService(storage.NewRepository(":memory:"))
+	enforcer, err := authz.NewEnforcer("")
+	require.NoError(t, err)
+	srv := grpcapi.NewServer(auth, enforcer, svc)
+
+	lis := bufconn.Listen(1024 * 1024)
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return productv1.NewProductServiceClient(conn), conn, key
+}
+
+func withToken(t *testing.T, key *rsa.PrivateKey, roles ...string) context.Context {
+	t.Helper()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss": testIssuer,
+		"aud": testClientID,
+		"sub": "user-123",
+		"exp": time.Now().Add(time.Hour).Unix(),
+		"iat": time.Now().Unix(),
+		"resource_access": map[string]any{
+			testClientID: map[string]any{"roles": roles},
+		},
+	})
+	signed, err := token.SignedString(key)
+	require.NoError(t, err)
+
+	return metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+signed)
+}
+
+func TestProductService_RequiresToken(t *testing.T) {
+	client, _, _ := setupServer(t)
+
+	_, err := client.ListProducts(context.Background(), &productv1.ListProductsRequest{})
+
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
+
+func TestProductService_NilAuthenticatorFailsClosed(t *testing.T) {
+	svc := product.NewService(storage.NewRepository(":memory:"))
+	enforcer, err := authz.NewEnforcer("")
+	require.NoError(t, err)
+	srv := grpcapi.NewServer(nil, enforcer, svc)
+
+	lis := bufconn.Listen(1024 * 1024)
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	_, err = productv1.NewProductServiceClient(conn).CreateProduct(context.Background(), &productv1.CreateProductRequest{Name: "Mouse"})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	// Health continua público
+	_, err = healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{})
+	assert.NoError(t, err)
+}
+
+func TestProductService_CreateAndList(t *testing.T) {
+	client, _, key := setupServer(t)
+	ctx := withToken(t, key, "develop")
+
+	created, err := client.CreateProduct(ctx, &productv1.CreateProductRequest{Name: "Teclado"})
+	require.NoError(t, err)
+	assert.Equal(t, "Teclado", created.GetName())
+
+	var header metadata.MD
+	list, err := client.ListProducts(ctx, &productv1.ListProductsRequest{Page: 1, PageSize: 10}, grpc.Header(&header))
+	require.NoError(t, err)
+	require.Len(t, list.GetProducts(), 1)
+	assert.Equal(t, "Teclado", list.GetProducts()[0].GetName())
+	assert.NotEmpty(t, header.Get("x-trace-id"))
+}
+
+func TestProductService_CategoryAndStatus(t *testing.T) {
+	client, _, key := setupServer(t)
+	develop, admin := withToken(t, key, "develop"), withToken(t, key, "admin")
+
+	_, err := client.CreateProduct(develop, &productv1.CreateProductRequest{Name: "Monitor", Category: "perifericos"})
+	require.NoError(t, err)
+	got, err := client.GetProduct(develop, &productv1.GetProductRequest{Id: 1})
+	require.NoError(t, err)
+	assert.Equal(t, "perifericos", got.GetProduct().GetCategory())
+	assert.Equal(t, "draft", got.GetProduct().GetStatus())
+
+	_, err = client.UpdateProduct(admin, &productv1.UpdateProductRequest{Id: 1, Name: "Monitor", Category: "monitores", Status: "published"})
+	require.NoError(t, err)
+	got, err = client.GetProduct(develop, &productv1.GetProductRequest{Id: 1})
+	require.NoError(t, err)
+	assert.Equal(t, "monitores", got.GetProduct().GetCategory())
+	assert.Equal(t, "published", got.GetProduct().GetStatus())
+
+	_, err = client.CreateProduct(develop, &productv1.CreateProductRequest{Name: "Mouse", Status: "arquivado"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestProductService_RoleChecks(t *testing.T) {
+	client, _, key := setupServer(t)
+
+	_, err := client.DeleteProduct(withToken(t, key, "develop"), &productv1.DeleteProductRequest{Id: 1})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	_, err = client.GetProduct(withToken(t, key, "develop"), &productv1.GetProductRequest{Id: 42})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	_, err = client.CreateProduct(withToken(t, key, "develop"), &productv1.CreateProductRequest{Name: ""})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestProductService_StreamProducts(t *testing.T) {
+	client, _, key := setupServer(t)
+	ctx := withToken(t, key, "develop")
+
+	for _, name := range []string{"A", "B", "C"} {
+		_, err := client.CreateProduct(ctx, &productv1.CreateProductRequest{Name: name})
+		require.NoError(t, err)
+	}
+
+	stream, err := client.StreamProducts(ctx, &productv1.StreamProductsRequest{PageSize: 2})
+	require.NoError(t, err)
+
+	var names []string
+	for {
+		p, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		names = append(names, p.GetName())
+	}
+	assert.Equal(t, []string{"A", "B", "C"}, names)
+}
+
+func TestHealth_NoAuthRequired(t *testing.T) {
+	_, conn, _ := setupServer(t)
+
+	resp, err := healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{})
+
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.GetStatus())
+}
+
+func TestMultiplex_ServesGRPCAndREST(t *testing.T) {
+	svc := product.NewService(storage.NewRepository(":memory:"))
+	enforcer, err := authz.NewEnforcer("")
+	require.NoError(t, err)
+	grpcServer := grpcapi.NewServer(middleware.NewDevAuthenticator(middleware.User{}), enforcer, svc)
+	rest := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("rest"))
+	})
+
+	ts := httptest.NewUnstartedServer(grpcapi.Multiplex(grpcServer, rest))
+	ts.Config.Protocols = new(http.Protocols)
+	ts.Config.Protocols.SetHTTP1(true)
+	ts.Config.Protocols.SetUnencryptedHTTP2(true)
+	ts.Start()
+	t.Cleanup(ts.Close)
+
+	// HTTP/1.1 continua indo para o handler REST
+	resp, err := http.Get(ts.URL)
+	require.NoError(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	assert.Equal(t, "rest", string(body))
+
+	// gRPC (h2c) vai para o servidor gRPC
+	conn, err := grpc.NewClient(ts.Listener.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	_, err = productv1.NewProductServiceClient(conn).CreateProduct(context.Background(), &productv1.CreateProductRequest{Name: "Mux"})
+	require.NoError(t, err)
+}
+
+func TestDevMode_IdentityFromMetadata(t *testing.T) {
+	svc := product.NewService(storage.NewRepository(":memory:"))
+	enforcer, err := authz.NewEnforcer("")
+	require.NoError(t, err)
+	grpcServer := grpcapi.NewServer(middleware.NewDevAuthenticator(middleware.User{}), enforcer, svc)
+
+	lis := bufconn.Listen(1024 * 1024)
+	go func() { _ = grpcServer.Serve(lis) }()
+	t.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	client := productv1.NewProductServiceClient(conn)
+
+	// Sem metadata: usuário padrão (admin)
+	_, err = client.CreateProduct(context.Background(), &productv1.CreateProductRequest{Name: "Dev"})
+	require.NoError(t, err)
+
+	// x-dev-roles rebaixa a identidade e a política continua valendo
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-dev-user", "ana", "x-dev-roles", "develop")
+	_, err = client.DeleteProduct(ctx, &productv1.DeleteProductRequest{Id: 1})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	ctx = metadata.AppendToOutgoingContext(context.Background(), "x-dev-roles", "admin")
+	_, err = client.DeleteProduct(ctx, &productv1.DeleteProductRequest{Id: 1})
+	assert.NoError(t, err)
+}
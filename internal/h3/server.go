@@ -0,0 +1,72 @@
+// Package h3 serve a API também via HTTP/3 (QUIC, em UDP) com o mesmo handler e
+// o mesmo *tls.Config do servidor TCP, e anuncia o endpoint aos clientes HTTP/1.1
+// e HTTP/2 pelo header Alt-Svc (RFC 7838).
+package h3
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+
+	"github.com/quic-go/quic-go/http3"
+)
+
+// Server é o listener HTTP/3. Crie com Listen, rode Serve em goroutine e
+// encerre com Shutdown junto com o http.Server.
+type Server struct {
+	srv  *http3.Server
+	conn net.PacketConn
+}
+
+// Listen abre a porta UDP (ex: ":8443") já na inicialização, para que erros de
+// bind apareçam antes do servidor subir. tlsCfg é obrigatório: QUIC não roda sem TLS.
+func Listen(addr string, handler http.Handler, tlsCfg *tls.Config) (*Server, error) {
+	if tlsCfg == nil {
+		return nil, errors.New("HTTP/3 requer TLS")
+	}
+	conn, err := net.ListenPacket("udp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao abrir porta UDP do HTTP/3: %w", err)
+	}
+	return &Server{
+		srv: &http3.Server{
+			Addr:      addr,
+			Port:      conn.LocalAddr().(*net.UDPAddr).Port,
+			Handler:   handler,
+			TLSConfig: tlsCfg,
+		},
+		conn: conn,
+	}, nil
+}
+
+// Addr devolve o endereço UDP em que o servidor escuta.
+func (s *Server) Addr() net.Addr {
+	return s.conn.LocalAddr()
+}
+
+// Serve atende conexões QUIC até Shutdown. Retorna http.ErrServerClosed após Shutdown.
+func (s *Server) Serve() error {
+	return s.srv.Serve(s.conn)
+}
+
+// Shutdown envia GOAWAY, espera as requisições em andamento até ctx expirar e
+// fecha a porta UDP.
+func (s *Server) Shutdown(ctx context.Context) error {
+	err := s.srv.Shutdown(ctx)
+	return errors.Join(err, s.conn.Close())
+}
+
+// AltSvc adiciona o header Alt-Svc (ex: h3=":8443"; ma=2592000) às respostas
+// servidas por TCP, para que os clientes migrem para HTTP/3 nas próximas requisições.
+func (s *Server) AltSvc(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ProtoMajor < 3 {
+			// Antes do Serve registrar o listener não há o que anunciar; ignoramos o erro
+			_ = s.srv.SetQUICHeaders(w.Header())
+		}
+		next.ServeHTTP(w, r)
+	})
+}
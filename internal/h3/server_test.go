@@ -0,0 +1,78 @@
+package h3_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go-api-first-steps/internal/h3"
+
+	"github.com/quic-go/quic-go/http3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func selfSignedTLS(t *testing.T) *tls.Config {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	return &tls.Config{Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}}}
+}
+
+func TestListen_RequiresTLS(t *testing.T) {
+	_, err := h3.Listen("127.0.0.1:0", http.NotFoundHandler(), nil)
+	assert.Error(t, err)
+}
+
+func TestServer_ServesHTTP3AndAdvertisesAltSvc(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "HTTP/%d", r.ProtoMajor)
+	})
+	srv, err := h3.Listen("127.0.0.1:0", handler, selfSignedTLS(t))
+	require.NoError(t, err)
+
+	served := make(chan error, 1)
+	go func() { served <- srv.Serve() }()
+
+	client := &http.Client{Transport: &http3.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}}
+	resp, err := client.Get("https://" + srv.Addr().String() + "/products")
+	require.NoError(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Equal(t, "HTTP/3", string(body))
+	assert.Empty(t, resp.Header.Get("Alt-Svc"), "não há o que anunciar para quem já está em HTTP/3")
+
+	// Respostas TCP anunciam a porta UDP
+	port := srv.Addr().(*net.UDPAddr).Port
+	w := httptest.NewRecorder()
+	srv.AltSvc(handler).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/products", nil))
+	assert.Equal(t, fmt.Sprintf(`h3=":%d"; ma=2592000`, port), w.Header().Get("Alt-Svc"))
+	assert.Equal(t, "HTTP/1", w.Body.String())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.NoError(t, srv.Shutdown(ctx))
+	assert.ErrorIs(t, <-served, http.ErrServerClosed)
+}
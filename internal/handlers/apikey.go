@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go-api-first-steps/internal/domain"
+	"go-api-first-steps/internal/middleware"
+	"go-api-first-steps/internal/services/apikey"
+
+	"github.com/gin-gonic/gin"
+)
+
+type APIKeyHandler struct {
+	Service *apikey.Service
+}
+
+// Create emite uma API key de serviço
+// @Summary      Cria uma API key
+// @Description  Emite uma chave para acesso serviço-a-serviço. O valor em texto puro só é retornado nesta resposta.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        request body     handlers.CreateAPIKeyRequest true "Dados da chave"
+// @Success      201     {object} handlers.CreateAPIKeyResponse
+// @Failure      400     {object} handlers.ErrorResponse
+// @Failure      500     {object} handlers.ErrorResponse
+// @Security     BearerAuth
+// @Router       /admin/api-keys [post]
+func (h *APIKeyHandler) Create(c *gin.Context) {
+	var req CreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "JSON inválido"})
+		return
+	}
+
+	var expiresAt *time.Time
+	if req.ExpiresIn != "" {
+		ttl, err := time.ParseDuration(req.ExpiresIn)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "expires_in inválido (ex: 720h)"})
+			return
+		}
+		t := time.Now().Add(ttl)
+		expiresAt = &t
+	}
+
+	var createdBy string
+	if user := middleware.GetUser(c); user != nil {
+		createdBy = user.ID
+	}
+
+	plaintext, key, err := h.Service.Create(req.Name, req.Roles, expiresAt, createdBy, middleware.TenantOf(c))
+	switch {
+	case errors.Is(err, apikey.ErrNameRequired), errors.Is(err, apikey.ErrRolesRequired), errors.Is(err, apikey.ErrInvalidExpiry):
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	case err != nil:
+		slog.ErrorContext(c.Request.Context(), "Erro ao criar API key", "error", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Erro ao criar API key"})
+		return
+	}
+
+	slog.InfoContext(c.Request.Context(), "API key criada", "prefix", key.Prefix, "name", key.Name, "created_by", createdBy)
+	c.JSON(http.StatusCreated, CreateAPIKeyResponse{Key: plaintext, APIKey: *key})
+}
+
+// List lista as API keys
+// @Summary      Lista API keys
+// @Description  Retorna as chaves do tenant do usuário (sem os segredos), com roles, expiração, último uso e revogação
+// @Tags         admin
+// @Produce      json
+// @Success      200 {array}  domain.APIKey
+// @Failure      500 {object} handlers.ErrorResponse
+// @Security     BearerAuth
+// @Router       /admin/api-keys [get]
+func (h *APIKeyHandler) List(c *gin.Context) {
+	keys, err := h.Service.List(middleware.TenantOf(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Erro ao listar API keys"})
+		return
+	}
+	c.JSON(http.StatusOK, keys)
+}
+
+// Revoke revoga uma API key
+// @Summary      Revoga uma API key
+// @Description  Invalida a chave imediatamente (idempotente). Só chaves do tenant do usuário.
+// @Tags         admin
+// @Produce      json
+// @Param        id  path     int true "ID da chave"
+// @Success      200 {object} handlers.MessageResponse
+// @Failure      400 {object} handlers.ErrorResponse
+// @Failure      404 {object} handlers.ErrorResponse
+// @Security     BearerAuth
+// @Router       /admin/api-keys/{id} [delete]
+func (h *APIKeyHandler) Revoke(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "ID inválido"})
+		return
+	}
+
+	err = h.Service.Revoke(middleware.TenantOf(c), uint(id))
+	switch {
+	case errors.Is(err, domain.ErrAPIKeyNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: err.Error()})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Erro ao revogar API key"})
+		return
+	}
+
+	slog.InfoContext(c.Request.Context(), "API key revogada", "id", id)
+	c.JSON(http.StatusOK, MessageResponse{Message: "API key revogada"})
+}
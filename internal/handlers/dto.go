@@ -1,19 +1,35 @@
 package handlers
 
+import (
+	"time"
+
+	"go-api-first-steps/internal/domain"
+	"go-api-first-steps/internal/middleware"
+	"go-api-first-steps/pkg/logger"
+)
+
 // CreateProductRequest representa o corpo da requisição POST
 type CreateProductRequest struct {
-	Name string `json:"name" binding:"required" example:"Monitor UltraWide"`
+	Name     string `json:"name" binding:"required" example:"Monitor UltraWide"`
+	Category string `json:"category" example:"monitores"`
+	Status   string `json:"status" example:"draft" enums:"draft,published"` // Vazio = draft
 }
 
 // UpdateProductRequest representa o corpo da requisição PUT
 type UpdateProductRequest struct {
-	Name string `json:"name" binding:"required" example:"Monitor UltraWide Pro"`
+	Name     string `json:"name" binding:"required" example:"Monitor UltraWide Pro"`
+	Category string `json:"category" example:"monitores"`                       // Vazio = mantém
+	Status   string `json:"status" example:"published" enums:"draft,published"` // Vazio = mantém
 }
 
 // ProductResponse representa a resposta de sucesso com dados
 type ProductResponse struct {
 	ID        uint   `json:"id" example:"1"`
 	Name      string `json:"name" example:"Monitor UltraWide"`
+	Category  string `json:"category" example:"monitores"`
+	Status    string `json:"status" example:"draft"`
+	CreatedBy string `json:"created_by" example:"f0c1..."`
+	UpdatedBy string `json:"updated_by" example:"f0c1..."`
 	CreatedAt string `json:"created_at" example:"2023-12-25T15:00:00Z"`
 }
 
@@ -26,3 +42,85 @@ type MessageResponse struct {
 type ErrorResponse struct {
 	Error string `json:"error" example:"Parâmetros inválidos"`
 }
+
+// CreateAPIKeyRequest representa o corpo da criação de API key
+type CreateAPIKeyRequest struct {
+	Name      string   `json:"name" binding:"required" example:"batch-estoque"`
+	Roles     []string `json:"roles" binding:"required" example:"develop"`
+	ExpiresIn string   `json:"expires_in" example:"720h"` // Vazio = sem expiração
+}
+
+// CreateAPIKeyResponse traz a chave em texto puro (única vez em que é exibida)
+type CreateAPIKeyResponse struct {
+	Key string `json:"key" example:"pak_1a2b3c4d5e6f_..."`
+	domain.APIKey
+}
+
+// RevokeTokenRequest identifica o token (jti) e/ou a sessão (sid) a revogar
+type RevokeTokenRequest struct {
+	JTI       string `json:"jti" example:"5f1c9e2a-..."`
+	SID       string `json:"sid" example:"b7d3..."`
+	ExpiresIn string `json:"expires_in" example:"1h"` // Até quando rejeitar (o exp restante do token)
+}
+
+// ReadinessResponse traz o estado da prontidão e da descoberta OIDC de cada emissor
+type ReadinessResponse struct {
+	Status string                 `json:"status" example:"ok"`
+	Auth   []middleware.AuthState `json:"auth"`
+}
+
+// MeResponse descreve o usuário autenticado e o que ele pode chamar
+type MeResponse struct {
+	ID             string      `json:"id" example:"f0c1..."`
+	Name           string      `json:"name" example:"Ana Souza"`
+	Email          string      `json:"email" example:"ana@exemplo.com"`
+	Username       string      `json:"username" example:"ana"`
+	Type           string      `json:"type" example:"user"` // user ou service (API key)
+	Tenant         string      `json:"tenant" example:"varejo"`
+	Roles          []string    `json:"roles" example:"develop"`
+	EffectiveRoles []string    `json:"effective_roles" example:"develop"` // Com a hierarquia da política
+	Permissions    []string    `json:"permissions" example:"products:read"`
+	Operations     []Operation `json:"operations"`
+}
+
+// Operation é uma rota da API permitida ao usuário
+type Operation struct {
+	Method string `json:"method" example:"PUT"`
+	Path   string `json:"path" example:"/api/v1/products/:id"`
+}
+
+// LogLevelResponse traz os níveis de log atuais
+type LogLevelResponse struct {
+	Console     string            `json:"console" example:"INFO"`
+	AppInsights string            `json:"appinsights" example:"WARN"`
+	Overrides   []logger.Override `json:"overrides"`
+}
+
+// SetLogLevelRequest troca o nível global de um ou dos dois destinos
+type SetLogLevelRequest struct {
+	Console     string `json:"console" example:"debug"`
+	AppInsights string `json:"appinsights" example:"info"`
+}
+
+// SetLogOverrideRequest define um nível temporário para um pacote ou rota
+type SetLogOverrideRequest struct {
+	Scope     string `json:"scope" binding:"required" example:"route"` // package ou route
+	Name      string `json:"name" binding:"required" example:"/api/v1/products/:id"`
+	Level     string `json:"level" binding:"required" example:"debug"`
+	ExpiresIn string `json:"expires_in" example:"15m"`
+}
+
+// DebugTokenRequest pede um token de debug para um trace
+type DebugTokenRequest struct {
+	TraceID   string `json:"trace_id" example:"4bf92f3577b34da6a3ce929d0e0e4736"` // Vazio = novo trace
+	ExpiresIn string `json:"expires_in" example:"5m"`
+}
+
+// DebugTokenResponse traz o header a enviar junto com o traceparent do trace
+type DebugTokenResponse struct {
+	TraceID     string    `json:"trace_id" example:"4bf92f3577b34da6a3ce929d0e0e4736"`
+	Traceparent string    `json:"traceparent" example:"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"`
+	Header      string    `json:"header" example:"X-Debug-Log"`
+	Token       string    `json:"token" example:"1767225600.9f86d0..."`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
@@ -3,6 +3,8 @@ package handlers
 import (
 	"net/http"
 
+	"go-api-first-steps/internal/middleware"
+
 	"github.com/gin-gonic/gin"
 )
 
@@ -19,3 +21,40 @@ func HealthCheck(c *gin.Context) {
 		"msg":    "API rodando liso!",
 	})
 }
+
+type ReadinessHandler struct {
+	Discoveries []*middleware.OIDCDiscovery // Uma por emissor; vazio = sem descoberta (DevMode, offline)
+}
+
+// Ready responde se a API está pronta para receber tráfego autenticado
+// @Summary      Verifica prontidão da API
+// @Description  Retorna 503 enquanto a descoberta OIDC de algum emissor não concluiu (sem chaves para validar tokens). Com JWKS em cache, responde 200 com status=cached.
+// @Tags         sistema
+// @Produce      json
+// @Success      200  {object}  handlers.ReadinessResponse
+// @Failure      503  {object}  handlers.ReadinessResponse
+// @Router       /ready [get]
+func (h *ReadinessHandler) Ready(c *gin.Context) {
+	if len(h.Discoveries) == 0 {
+		c.JSON(http.StatusOK, ReadinessResponse{
+			Status: "ok",
+			Auth:   []middleware.AuthState{{Status: middleware.AuthStateDisabled}},
+		})
+		return
+	}
+
+	resp := ReadinessResponse{Status: "ok"}
+	for _, d := range h.Discoveries {
+		state := d.State()
+		if state.Status == middleware.AuthStatePending {
+			resp.Status = "unavailable"
+		}
+		resp.Auth = append(resp.Auth, state)
+	}
+
+	if resp.Status != "ok" {
+		c.JSON(http.StatusServiceUnavailable, resp)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,41 @@
+package handlers_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go-api-first-steps/internal/handlers"
+	"go-api-first-steps/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReady(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	get := func(h *handlers.ReadinessHandler) *httptest.ResponseRecorder {
+		r := gin.New()
+		r.GET("/ready", h.Ready)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ready", nil))
+		return w
+	}
+
+	// Sem descoberta OIDC (DevMode / offline)
+	w := get(&handlers.ReadinessHandler{})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"status":"disabled"`)
+
+	// Provedor inacessível: descoberta pendente
+	auth := &middleware.Authenticator{}
+	discovery := middleware.NewOIDCDiscovery(auth, "http://127.0.0.1:1/realms/x", "api", "", time.Second)
+	_ = discovery.Init(t.Context())
+
+	w = get(&handlers.ReadinessHandler{Discoveries: []*middleware.OIDCDiscovery{discovery}})
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Contains(t, w.Body.String(), `"status":"pending"`)
+	assert.Contains(t, w.Body.String(), `"last_error"`)
+}
@@ -0,0 +1,208 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"go-api-first-steps/internal/middleware"
+	"go-api-first-steps/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// maxLogLevelTTL limita overrides e tokens de debug: um nível esquecido volta sozinho
+const maxLogLevelTTL = 24 * time.Hour
+
+type LogLevelHandler struct {
+	Levels      *logger.LevelController
+	DebugSecret []byte // Chave dos tokens do header X-Debug-Log (vazia = desabilitado)
+}
+
+// Get mostra os níveis atuais
+// @Summary      Níveis de log atuais
+// @Description  Nível mínimo do console e do App Insights e os overrides por pacote/rota ainda válidos
+// @Tags         admin
+// @Produce      json
+// @Success      200 {object} handlers.LogLevelResponse
+// @Security     BearerAuth
+// @Router       /admin/log-level [get]
+func (h *LogLevelHandler) Get(c *gin.Context) {
+	c.JSON(http.StatusOK, h.response())
+}
+
+// Set altera o nível global
+// @Summary      Altera o nível de log global
+// @Description  Troca o nível mínimo do console e/ou do App Insights sem reiniciar. Campos omitidos ficam como estão. Não expira.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        request body     handlers.SetLogLevelRequest true "Novos níveis"
+// @Success      200     {object} handlers.LogLevelResponse
+// @Failure      400     {object} handlers.ErrorResponse
+// @Security     BearerAuth
+// @Router       /admin/log-level [put]
+func (h *LogLevelHandler) Set(c *gin.Context) {
+	var req SetLogLevelRequest
+	if err := c.ShouldBindJSON(&req); err != nil || (req.Console == "" && req.AppInsights == "") {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Informe console e/ou appinsights"})
+		return
+	}
+
+	var console, appInsights slog.Level
+	if (req.Console != "" && console.UnmarshalText([]byte(req.Console)) != nil) ||
+		(req.AppInsights != "" && appInsights.UnmarshalText([]byte(req.AppInsights)) != nil) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Nível inválido (use debug, info, warn ou error)"})
+		return
+	}
+	if req.Console != "" {
+		h.Levels.Console.Set(console)
+	}
+	if req.AppInsights != "" {
+		h.Levels.AppInsights.Set(appInsights)
+	}
+
+	slog.InfoContext(c.Request.Context(), "Nível de log alterado",
+		"console", h.Levels.Console.Level(), "appinsights", h.Levels.AppInsights.Level(), "changed_by", changedBy(c))
+	c.JSON(http.StatusOK, h.response())
+}
+
+// SetOverride cria ou substitui um override
+// @Summary      Override de nível por pacote ou rota
+// @Description  Define o nível mínimo dos logs de um pacote Go (ex: go-api-first-steps/internal/middleware) ou de uma rota (template do Gin, ex: /api/v1/products/:id, ou método gRPC) por expires_in (default 15m, máx 24h). Vale para todos os destinos.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        request body     handlers.SetLogOverrideRequest true "Override"
+// @Success      201     {object} logger.Override
+// @Failure      400     {object} handlers.ErrorResponse
+// @Security     BearerAuth
+// @Router       /admin/log-level/overrides [post]
+func (h *LogLevelHandler) SetOverride(c *gin.Context) {
+	var req SetLogOverrideRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "JSON inválido"})
+		return
+	}
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(req.Level)); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Nível inválido (use debug, info, warn ou error)"})
+		return
+	}
+	ttl, ok := parseLogLevelTTL(c, req.ExpiresIn, 15*time.Minute)
+	if !ok {
+		return
+	}
+
+	override, err := h.Levels.SetOverride(req.Scope, req.Name, level, ttl)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	slog.InfoContext(c.Request.Context(), "Override de nível de log criado",
+		"scope", override.Scope, "name", override.Name, "level", override.Level,
+		"expires_at", override.ExpiresAt, "changed_by", changedBy(c))
+	c.JSON(http.StatusCreated, override)
+}
+
+// RemoveOverride remove um override antes de expirar
+// @Summary      Remove override de nível
+// @Tags         admin
+// @Produce      json
+// @Param        scope query    string true "package ou route" example(route)
+// @Param        name  query    string true "Pacote ou rota do override" example(/api/v1/products)
+// @Success      200   {object} handlers.MessageResponse
+// @Failure      404   {object} handlers.ErrorResponse
+// @Security     BearerAuth
+// @Router       /admin/log-level/overrides [delete]
+func (h *LogLevelHandler) RemoveOverride(c *gin.Context) {
+	scope, name := c.Query("scope"), c.Query("name")
+	if !h.Levels.RemoveOverride(scope, name) {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Override não encontrado"})
+		return
+	}
+
+	slog.InfoContext(c.Request.Context(), "Override de nível de log removido", "scope", scope, "name", name, "changed_by", changedBy(c))
+	c.JSON(http.StatusOK, MessageResponse{Message: "Removido"})
+}
+
+// CreateDebugToken emite o header de debug de um trace
+// @Summary      Token de debug por requisição
+// @Description  Gera o valor do header X-Debug-Log para um trace ID (ou um novo, se omitido). Requisições desse trace (header traceparent devolvido) que enviarem o header têm os logs de debug liberados até expires_in (default 5m, máx 24h).
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        request body     handlers.DebugTokenRequest true "Trace e validade"
+// @Success      201     {object} handlers.DebugTokenResponse
+// @Failure      400     {object} handlers.ErrorResponse
+// @Failure      503     {object} handlers.ErrorResponse
+// @Security     BearerAuth
+// @Router       /admin/log-level/debug-tokens [post]
+func (h *LogLevelHandler) CreateDebugToken(c *gin.Context) {
+	if len(h.DebugSecret) == 0 {
+		c.JSON(http.StatusServiceUnavailable, ErrorResponse{Error: "LOG_DEBUG_SECRET não configurado"})
+		return
+	}
+	var req DebugTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil && c.Request.ContentLength > 0 {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "JSON inválido"})
+		return
+	}
+
+	traceID, err := trace.TraceIDFromHex(req.TraceID)
+	if req.TraceID == "" {
+		_, _ = rand.Read(traceID[:])
+	} else if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "trace_id inválido (32 caracteres hexadecimais)"})
+		return
+	}
+	ttl, ok := parseLogLevelTTL(c, req.ExpiresIn, 5*time.Minute)
+	if !ok {
+		return
+	}
+
+	var spanID trace.SpanID
+	_, _ = rand.Read(spanID[:])
+	expires := time.Now().Add(ttl).Truncate(time.Second)
+	resp := DebugTokenResponse{
+		TraceID:     traceID.String(),
+		Traceparent: "00-" + traceID.String() + "-" + spanID.String() + "-01",
+		Header:      middleware.DebugLogHeader,
+		Token:       middleware.SignDebugToken(h.DebugSecret, traceID.String(), expires),
+		ExpiresAt:   expires,
+	}
+
+	slog.InfoContext(c.Request.Context(), "Token de debug emitido", "debug_trace_id", resp.TraceID, "expires_at", expires, "changed_by", changedBy(c))
+	c.JSON(http.StatusCreated, resp)
+}
+
+func (h *LogLevelHandler) response() LogLevelResponse {
+	return LogLevelResponse{
+		Console:     h.Levels.Console.Level().String(),
+		AppInsights: h.Levels.AppInsights.Level().String(),
+		Overrides:   h.Levels.Overrides(),
+	}
+}
+
+// parseLogLevelTTL lê expires_in (default def); responde 400 se inválido.
+func parseLogLevelTTL(c *gin.Context, expiresIn string, def time.Duration) (time.Duration, bool) {
+	if expiresIn == "" {
+		return def, true
+	}
+	d, err := time.ParseDuration(expiresIn)
+	if err != nil || d <= 0 || d > maxLogLevelTTL {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "expires_in inválido (ex: 15m, máx 24h)"})
+		return 0, false
+	}
+	return d, true
+}
+
+func changedBy(c *gin.Context) string {
+	if user := middleware.GetUser(c); user != nil {
+		return user.ID
+	}
+	return ""
+}
@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+
+	"go-api-first-steps/internal/authz"
+	"go-api-first-steps/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MeHandler descreve o usuário autenticado e o que ele pode chamar na API.
+type MeHandler struct {
+	Enforcer *authz.Enforcer
+	Routes   func() gin.RoutesInfo // Rotas registradas (ex: engine.Routes), lidas a cada chamada
+	BasePath string                // Só rotas sob este prefixo entram em operations (ex: /api/v1)
+}
+
+// Me retorna o usuário autenticado e as operações permitidas
+// @Summary      Usuário autenticado
+// @Description  Retorna a identidade extraída do token e as operações da API que a política permite ao usuário, para a UI esconder ações que dariam 403. Regras por atributos (ex: só os próprios rascunhos) ainda podem negar um recurso específico.
+// @Tags         sistema
+// @Produce      json
+// @Success      200  {object}  handlers.MeResponse
+// @Failure      401  {object}  handlers.ErrorResponse
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Router       /me [get]
+func (h *MeHandler) Me(c *gin.Context) {
+	user := middleware.GetUser(c)
+	if user == nil {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Usuário não autenticado"})
+		return
+	}
+
+	policy := h.Enforcer.Policy()
+	resp := MeResponse{
+		ID:             user.ID,
+		Name:           user.Name,
+		Email:          user.Email,
+		Username:       user.Username,
+		Type:           user.Type,
+		Tenant:         user.Tenant,
+		Roles:          append([]string{}, user.Roles...),
+		EffectiveRoles: policy.EffectiveRoles(user.Roles),
+		Permissions:    policy.Permissions(user.Roles).Sorted(),
+		Operations:     h.operations(policy, user.Roles),
+	}
+	if resp.EffectiveRoles == nil {
+		resp.EffectiveRoles = []string{}
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// operations avalia cada rota registrada sob BasePath contra a política.
+// Rotas sem regra na política (ex: o próprio /me) não entram na lista.
+func (h *MeHandler) operations(policy *authz.Policy, roles []string) []Operation {
+	ops := []Operation{}
+	if h.Routes == nil {
+		return ops
+	}
+
+	for _, route := range h.Routes() {
+		if !strings.HasPrefix(route.Path, h.BasePath) || !policy.HasRule(route.Method, route.Path) {
+			continue
+		}
+		if policy.Decide(roles, route.Method, route.Path).Allowed {
+			ops = append(ops, Operation{Method: route.Method, Path: route.Path})
+		}
+	}
+
+	sort.Slice(ops, func(i, j int) bool {
+		if ops[i].Path != ops[j].Path {
+			return ops[i].Path < ops[j].Path
+		}
+		return ops[i].Method < ops[j].Method
+	})
+	return ops
+}
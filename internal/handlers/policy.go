@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"go-api-first-steps/internal/authz"
+	"go-api-first-steps/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PolicyHandler struct {
+	Enforcer *authz.Enforcer
+}
+
+// Explain explica a decisão de autorização para uma rota
+// @Summary      Explica a política de acesso
+// @Description  Informa se um conjunto de roles (ou o próprio usuário, se roles for omitido) pode acessar a rota, com as roles e permissões efetivas e o motivo
+// @Tags         admin
+// @Produce      json
+// @Param        method query    string  true   "Método HTTP (ou GRPC)" example(PUT)
+// @Param        path   query    string  true   "Caminho ou template da rota" example(/api/v1/products/5)
+// @Param        roles  query    string  false  "Roles separadas por vírgula (default: roles do usuário)" example(develop,manager)
+// @Success      200    {object} authz.Decision
+// @Failure      400    {object} handlers.ErrorResponse
+// @Security     BearerAuth
+// @Router       /admin/policy/explain [get]
+func (h *PolicyHandler) Explain(c *gin.Context) {
+	method := c.Query("method")
+	path := c.Query("path")
+	if method == "" || path == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Parâmetros method e path são obrigatórios"})
+		return
+	}
+
+	var roles []string
+	if rolesParam, ok := c.GetQuery("roles"); ok {
+		for _, r := range strings.Split(rolesParam, ",") {
+			if r = strings.TrimSpace(r); r != "" {
+				roles = append(roles, r)
+			}
+		}
+	} else if user := middleware.GetUser(c); user != nil {
+		roles = user.Roles
+	}
+
+	c.JSON(http.StatusOK, h.Enforcer.Decide(roles, method, path))
+}
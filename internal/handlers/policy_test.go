@@ -0,0 +1,52 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go-api-first-steps/internal/authz"
+	"go-api-first-steps/internal/handlers"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupPolicyRouter(t *testing.T) *gin.Engine {
+	enforcer, err := authz.NewEnforcer("")
+	require.NoError(t, err)
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/admin/policy/explain", (&handlers.PolicyHandler{Enforcer: enforcer}).Explain)
+	return r
+}
+
+func TestPolicyExplain(t *testing.T) {
+	router := setupPolicyRouter(t)
+
+	req, _ := http.NewRequest("GET", "/admin/policy/explain?method=DELETE&path=/api/v1/products/5&roles=develop", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var decision authz.Decision
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &decision))
+	assert.False(t, decision.Allowed)
+	assert.Equal(t, "/api/v1/products/:id", decision.Route)
+	assert.Equal(t, "products:delete", decision.Expression)
+	assert.NotContains(t, decision.Permissions, "products:delete")
+}
+
+func TestPolicyExplain_MissingParams(t *testing.T) {
+	router := setupPolicyRouter(t)
+
+	req, _ := http.NewRequest("GET", "/admin/policy/explain?method=GET", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
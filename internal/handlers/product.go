@@ -1,10 +1,12 @@
 package handlers
 
 import (
+	"errors"
 	"log/slog"
 	"net/http"
 	"strconv"
 
+	"go-api-first-steps/internal/domain"
 	"go-api-first-steps/internal/middleware"
 	"go-api-first-steps/internal/services/product"
 
@@ -15,17 +17,28 @@ type ProductHandler struct {
 	Service *product.Service
 }
 
+// service devolve o Service que opera em nome do usuário autenticado (tenant, autoria e regras ABAC)
+// e no contexto da requisição (trace).
+func (h *ProductHandler) service(c *gin.Context) *product.Service {
+	return h.Service.ForActor(middleware.GetUser(c).Actor()).WithContext(c.Request.Context())
+}
+
 // Create cria um novo produto
 // @Summary      Cria um produto
 // @Description  Cria um novo produto no banco de dados
 // @Tags         produtos
 // @Accept       json
 // @Produce      json
-// @Param        request body     handlers.CreateProductRequest true "Dados do Produto"
+// @Param        request         body     handlers.CreateProductRequest true  "Dados do Produto"
+// @Param        Idempotency-Key header   string                        false "Chave para retries seguros (replay da resposta original)"
 // @Success      201     {object} handlers.MessageResponse
 // @Failure      400     {object} handlers.ErrorResponse
+// @Failure      403     {object} handlers.ErrorResponse
+// @Failure      409     {object} handlers.ErrorResponse
+// @Failure      422     {object} handlers.ErrorResponse
 // @Failure      500     {object} handlers.ErrorResponse
 // @Security     BearerAuth
+// @Security     ApiKeyAuth
 // @Router       /products [post]
 func (h *ProductHandler) Create(c *gin.Context) {
 	var req CreateProductRequest
@@ -38,7 +51,18 @@ func (h *ProductHandler) Create(c *gin.Context) {
 
 	slog.InfoContext(c.Request.Context(), "Criando produto", "name", req.Name)
 
-	name, err := h.Service.CreateProduct(req.Name)
+	name, err := h.service(c).CreateProduct(product.ProductInput{Name: req.Name, Category: req.Category, Status: req.Status})
+	switch {
+	case errors.Is(err, domain.ErrInvalidProductStatus):
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	case errors.Is(err, domain.ErrForbidden):
+		c.JSON(http.StatusForbidden, ErrorResponse{Error: domain.ErrForbidden.Error()})
+		return
+	case errors.Is(err, domain.ErrDuplicateProductName):
+		c.JSON(http.StatusConflict, ErrorResponse{Error: err.Error()})
+		return
+	}
 	if err != nil {
 		slog.ErrorContext(c.Request.Context(), "Erro ao criar", "error", err)
 		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
@@ -58,6 +82,7 @@ func (h *ProductHandler) Create(c *gin.Context) {
 // @Success      200  {array}   handlers.ProductResponse
 // @Failure      500  {object}  handlers.ErrorResponse
 // @Security     BearerAuth
+// @Security     ApiKeyAuth
 // @Router       /products [get]
 func (h *ProductHandler) List(c *gin.Context) {
 	// Exemplo: Recuperando o usuário autenticado do contexto
@@ -73,7 +98,7 @@ func (h *ProductHandler) List(c *gin.Context) {
 	page, _ := strconv.Atoi(c.Query("page"))
 	pageSize, _ := strconv.Atoi(c.Query("page_size"))
 
-	products, err := h.Service.ListProducts(page, pageSize)
+	products, err := h.service(c).ListProducts(page, pageSize)
 	if err != nil {
 		slog.ErrorContext(c.Request.Context(), "Erro ao listar", "error", err)
 		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Erro ao listar"})
@@ -84,7 +109,7 @@ func (h *ProductHandler) List(c *gin.Context) {
 
 // Update atualiza um produto
 // @Summary      Atualiza um produto
-// @Description  Atualiza o nome de um produto existente pelo ID
+// @Description  Atualiza nome, categoria e status de um produto pelo ID. Além da rota, valem as regras por atributos (ex: o autor só edita os próprios rascunhos).
 // @Tags         produtos
 // @Accept       json
 // @Produce      json
@@ -92,8 +117,12 @@ func (h *ProductHandler) List(c *gin.Context) {
 // @Param        request body     handlers.UpdateProductRequest true "Novos dados"
 // @Success      200     {object} handlers.MessageResponse
 // @Failure      400     {object} handlers.ErrorResponse
+// @Failure      403     {object} handlers.ErrorResponse
+// @Failure      404     {object} handlers.ErrorResponse
+// @Failure      409     {object} handlers.ErrorResponse
 // @Failure      500     {object} handlers.ErrorResponse
 // @Security     BearerAuth
+// @Security     ApiKeyAuth
 // @Router       /products/{id} [put]
 func (h *ProductHandler) Update(c *gin.Context) {
 	idStr := c.Param("id")
@@ -113,7 +142,23 @@ func (h *ProductHandler) Update(c *gin.Context) {
 		return
 	}
 
-	if err := h.Service.UpdateProduct(uint(id), req.Name); err != nil {
+	err = h.service(c).UpdateProduct(uint(id), product.ProductInput{Name: req.Name, Category: req.Category, Status: req.Status})
+	switch {
+	case errors.Is(err, domain.ErrInvalidProductStatus):
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	case errors.Is(err, domain.ErrForbidden):
+		slog.WarnContext(c.Request.Context(), "Atualização negada pelas regras do produto", "id", id, "error", err)
+		c.JSON(http.StatusForbidden, ErrorResponse{Error: domain.ErrForbidden.Error()})
+		return
+	case errors.Is(err, domain.ErrProductNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: err.Error()})
+		return
+	case errors.Is(err, domain.ErrDuplicateProductName):
+		c.JSON(http.StatusConflict, ErrorResponse{Error: err.Error()})
+		return
+	}
+	if err != nil {
 		slog.ErrorContext(c.Request.Context(), "Erro ao atualizar", "id", id, "error", err)
 		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Erro ao atualizar"})
 		return
@@ -130,8 +175,11 @@ func (h *ProductHandler) Update(c *gin.Context) {
 // @Param        id   path      int  true  "ID do Produto"
 // @Success      200  {object}  handlers.MessageResponse
 // @Failure      400  {object}  handlers.ErrorResponse
+// @Failure      403  {object}  handlers.ErrorResponse
+// @Failure      404  {object}  handlers.ErrorResponse
 // @Failure      500  {object}  handlers.ErrorResponse
 // @Security     BearerAuth
+// @Security     ApiKeyAuth
 // @Router       /products/{id} [delete]
 func (h *ProductHandler) Delete(c *gin.Context) {
 	idStr := c.Param("id")
@@ -145,7 +193,16 @@ func (h *ProductHandler) Delete(c *gin.Context) {
 		return
 	}
 
-	if err := h.Service.DeleteProduct(uint(id)); err != nil {
+	err = h.service(c).DeleteProduct(uint(id))
+	switch {
+	case errors.Is(err, domain.ErrForbidden):
+		c.JSON(http.StatusForbidden, ErrorResponse{Error: domain.ErrForbidden.Error()})
+		return
+	case errors.Is(err, domain.ErrProductNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: err.Error()})
+		return
+	}
+	if err != nil {
 		slog.ErrorContext(c.Request.Context(), "Erro ao deletar", "id", id, "error", err)
 		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Erro ao deletar"})
 		return
@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"go-api-first-steps/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxRevocationTTL limita por quanto tempo uma entrada fica na lista (tokens não vivem mais que isso)
+const maxRevocationTTL = 24 * time.Hour
+
+type TokenHandler struct {
+	Revocations middleware.RevocationList
+}
+
+// Revoke revoga um token (jti) ou uma sessão (sid) antes do exp
+// @Summary      Revoga token ou sessão
+// @Description  Adiciona o jti e/ou sid à lista de revogação. Tokens com esses identificadores passam a receber 401 até expires_in (default 1h, máx 24h).
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        request body     handlers.RevokeTokenRequest true "Identificadores a revogar"
+// @Success      200     {object} handlers.MessageResponse
+// @Failure      400     {object} handlers.ErrorResponse
+// @Security     BearerAuth
+// @Router       /admin/tokens/revocations [post]
+func (h *TokenHandler) Revoke(c *gin.Context) {
+	var req RevokeTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil || (req.JTI == "" && req.SID == "") {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Informe jti e/ou sid"})
+		return
+	}
+
+	ttl := time.Hour
+	if req.ExpiresIn != "" {
+		d, err := time.ParseDuration(req.ExpiresIn)
+		if err != nil || d <= 0 || d > maxRevocationTTL {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "expires_in inválido (ex: 1h, máx 24h)"})
+			return
+		}
+		ttl = d
+	}
+	until := time.Now().Add(ttl)
+
+	if req.JTI != "" {
+		h.Revocations.Revoke(middleware.RevokeJTI, req.JTI, until)
+	}
+	if req.SID != "" {
+		h.Revocations.Revoke(middleware.RevokeSID, req.SID, until)
+	}
+
+	var revokedBy string
+	if user := middleware.GetUser(c); user != nil {
+		revokedBy = user.ID
+	}
+	slog.InfoContext(c.Request.Context(), "Token revogado", "jti", req.JTI, "sid", req.SID, "revoked_by", revokedBy)
+	c.JSON(http.StatusOK, MessageResponse{Message: "Revogado"})
+}
@@ -0,0 +1,37 @@
+package metrics
+
+import "go-api-first-steps/pkg/logger"
+
+// Default é o registry exposto em GET /metrics.
+var Default = NewRegistry()
+
+// Métricas da API. Rotas usam o template do Gin (ex: /api/v1/products/:id), nunca
+// o path bruto, para que IDs não multipliquem as séries.
+var (
+	HTTPRequests = Default.NewCounter("http_requests_total",
+		"Requisições HTTP finalizadas.", "method", "route", "status")
+	HTTPRequestDuration = Default.NewHistogram("http_request_duration_seconds",
+		"Latência das requisições HTTP.", DefaultBuckets, "method", "route", "status")
+	HTTPRequestsInFlight = Default.NewGauge("http_requests_in_flight",
+		"Requisições HTTP em andamento.", "method", "route")
+
+	DBQueryDuration = Default.NewHistogram("db_query_duration_seconds",
+		"Duração das operações no banco (callbacks do GORM).",
+		[]float64{0.0005, 0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1}, "operation", "table")
+	DBQueryErrors = Default.NewCounter("db_query_errors_total",
+		"Operações no banco que falharam (registro não encontrado não conta).", "operation", "table")
+
+	// transport: http ou grpc; credential: token, api_key, client_cert, dev ou none
+	AuthAttempts = Default.NewCounter("auth_attempts_total",
+		"Tentativas de autenticação por tipo de credencial e resultado.", "transport", "credential", "outcome")
+	AuthzDecisions = Default.NewCounter("authz_decisions_total",
+		"Decisões de autorização (roles/política) para usuários autenticados.", "transport", "decision")
+
+	// reason: origem do limite excedido (role, group ou default)
+	RateLimitRejections = Default.NewCounter("rate_limit_rejections_total",
+		"Requisições rejeitadas (429) pelo rate limit, por grupo de rotas.", "group", "reason")
+)
+
+func init() {
+	Default.MustRegister(NewRuntimeCollector(), NewLogCollector(logger.Stats))
+}
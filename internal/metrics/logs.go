@@ -0,0 +1,40 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+
+	"go-api-first-steps/pkg/logger"
+)
+
+// logCollector lê os contadores das filas de log (logger.Stats) a cada coleta.
+type logCollector struct {
+	stats func() []logger.AsyncStats
+}
+
+// NewLogCollector expõe, por destino de log, a fila atual e os logs descartados
+// (fila cheia) ou que falharam no destino.
+func NewLogCollector(stats func() []logger.AsyncStats) Collector {
+	return &logCollector{stats: stats}
+}
+
+func (c *logCollector) Name() string { return "log_pipeline" }
+
+func (c *logCollector) Write(w io.Writer) {
+	stats := c.stats()
+	if len(stats) == 0 {
+		return
+	}
+	family := func(name, help, kind string, value func(logger.AsyncStats) float64) {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+		for _, s := range stats {
+			fmt.Fprintf(w, "%s%s %s\n", name, formatLabels([]string{"sink"}, []string{s.Sink}), formatFloat(value(s)))
+		}
+	}
+	family("log_queue_length", "Logs aguardando entrega, por destino.", "gauge",
+		func(s logger.AsyncStats) float64 { return float64(s.Queued) })
+	family("log_records_dropped_total", "Logs descartados por fila cheia, por destino.", "counter",
+		func(s logger.AsyncStats) float64 { return float64(s.Dropped) })
+	family("log_sink_errors_total", "Logs que o destino não conseguiu gravar.", "counter",
+		func(s logger.AsyncStats) float64 { return float64(s.Failed) })
+}
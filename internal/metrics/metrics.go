@@ -0,0 +1,341 @@
+// Package metrics implementa contadores, gauges e histogramas com rótulos e os
+// expõe no formato texto do Prometheus (exposition format 0.0.4), sem depender
+// do client_golang. Cobre só o que a API usa; não há summaries nem exemplars.
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultBuckets são os limites (em segundos) dos histogramas de latência HTTP.
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// Collector escreve uma ou mais famílias de métricas no formato texto.
+type Collector interface {
+	Name() string
+	Write(w io.Writer)
+}
+
+// Registry agrupa os collectors expostos em /metrics.
+type Registry struct {
+	mu         sync.RWMutex
+	collectors []Collector
+}
+
+// NewRegistry cria um registry vazio.
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// MustRegister adiciona collectors. Nomes repetidos são erro de programação (panic).
+func (r *Registry) MustRegister(cs ...Collector) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, c := range cs {
+		if slices.ContainsFunc(r.collectors, func(e Collector) bool { return e.Name() == c.Name() }) {
+			panic("métrica registrada duas vezes: " + c.Name())
+		}
+		r.collectors = append(r.collectors, c)
+	}
+	slices.SortFunc(r.collectors, func(a, b Collector) int { return strings.Compare(a.Name(), b.Name()) })
+}
+
+// WriteText escreve todas as métricas, ordenadas por nome.
+func (r *Registry) WriteText(w io.Writer) error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	bw := bufio.NewWriter(w)
+	for _, c := range r.collectors {
+		c.Write(bw)
+	}
+	return bw.Flush()
+}
+
+// Handler expõe o registry via HTTP (GET /metrics).
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		_ = r.WriteText(w)
+	})
+}
+
+// NewCounter cria e registra um contador com os rótulos informados.
+func (r *Registry) NewCounter(name, help string, labels ...string) *Counter {
+	c := &Counter{vec: newVec[float64](name, help, "counter", labels)}
+	r.MustRegister(c)
+	return c
+}
+
+// NewGauge cria e registra um gauge com os rótulos informados.
+func (r *Registry) NewGauge(name, help string, labels ...string) *Gauge {
+	g := &Gauge{vec: newVec[float64](name, help, "gauge", labels)}
+	r.MustRegister(g)
+	return g
+}
+
+// NewHistogram cria e registra um histograma com os limites (crescentes) e rótulos informados.
+func (r *Registry) NewHistogram(name, help string, buckets []float64, labels ...string) *Histogram {
+	if !slices.IsSorted(buckets) {
+		panic("buckets fora de ordem em " + name)
+	}
+	h := &Histogram{vec: newVec[*histogramValue](name, help, "histogram", labels), buckets: buckets}
+	r.MustRegister(h)
+	return h
+}
+
+// vec guarda uma série por combinação de valores de rótulos.
+type vec[T any] struct {
+	name, help, kind string
+	labels           []string
+
+	mu     sync.Mutex
+	series map[string]*series[T]
+}
+
+type series[T any] struct {
+	labelValues []string
+	value       T
+}
+
+func newVec[T any](name, help, kind string, labels []string) vec[T] {
+	return vec[T]{name: name, help: help, kind: kind, labels: labels, series: make(map[string]*series[T])}
+}
+
+func (v *vec[T]) Name() string { return v.name }
+
+// get devolve a série dos valores (criando-a); deve ser chamado com v.mu travado.
+func (v *vec[T]) get(values []string, init func() T) *series[T] {
+	if len(values) != len(v.labels) {
+		panic(fmt.Sprintf("%s espera %d rótulos, recebeu %d", v.name, len(v.labels), len(values)))
+	}
+	key := strings.Join(values, "\xff")
+	s, ok := v.series[key]
+	if !ok {
+		s = &series[T]{labelValues: slices.Clone(values), value: init()}
+		v.series[key] = s
+	}
+	return s
+}
+
+// lookup devolve a série dos valores sem criá-la; deve ser chamado com v.mu travado.
+func (v *vec[T]) lookup(values []string) (*series[T], bool) {
+	s, ok := v.series[strings.Join(values, "\xff")]
+	return s, ok
+}
+
+// sorted devolve as séries em ordem estável; deve ser chamado com v.mu travado.
+func (v *vec[T]) sorted() []*series[T] {
+	out := make([]*series[T], 0, len(v.series))
+	for _, s := range v.series {
+		out = append(out, s)
+	}
+	slices.SortFunc(out, func(a, b *series[T]) int { return slices.Compare(a.labelValues, b.labelValues) })
+	return out
+}
+
+func (v *vec[T]) writeHeader(w io.Writer) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", v.name, escapeHelp(v.help), v.name, v.kind)
+}
+
+// Counter só cresce (requisições, erros, tentativas de login...).
+type Counter struct {
+	vec[float64]
+}
+
+// Inc soma 1 à série dos valores de rótulos.
+func (c *Counter) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Add soma delta (>= 0) à série dos valores de rótulos.
+func (c *Counter) Add(delta float64, labelValues ...string) {
+	if delta < 0 {
+		panic("contador não pode diminuir: " + c.name)
+	}
+	c.mu.Lock()
+	c.get(labelValues, zero).value += delta
+	c.mu.Unlock()
+}
+
+// Value devolve o valor atual da série (0 se não existir).
+func (c *Counter) Value(labelValues ...string) float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if s, ok := c.lookup(labelValues); ok {
+		return s.value
+	}
+	return 0
+}
+
+func (c *Counter) Write(w io.Writer) {
+	writeScalar(w, &c.vec)
+}
+
+// Gauge sobe e desce (requisições em andamento, conexões abertas...).
+type Gauge struct {
+	vec[float64]
+}
+
+// Add soma delta (pode ser negativo) à série dos valores de rótulos.
+func (g *Gauge) Add(delta float64, labelValues ...string) {
+	g.mu.Lock()
+	g.get(labelValues, zero).value += delta
+	g.mu.Unlock()
+}
+
+// Inc soma 1.
+func (g *Gauge) Inc(labelValues ...string) { g.Add(1, labelValues...) }
+
+// Dec subtrai 1.
+func (g *Gauge) Dec(labelValues ...string) { g.Add(-1, labelValues...) }
+
+// Set define o valor da série.
+func (g *Gauge) Set(value float64, labelValues ...string) {
+	g.mu.Lock()
+	g.get(labelValues, zero).value = value
+	g.mu.Unlock()
+}
+
+// Value devolve o valor atual da série (0 se não existir).
+func (g *Gauge) Value(labelValues ...string) float64 {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if s, ok := g.lookup(labelValues); ok {
+		return s.value
+	}
+	return 0
+}
+
+func (g *Gauge) Write(w io.Writer) {
+	writeScalar(w, &g.vec)
+}
+
+func zero() float64 { return 0 }
+
+func writeScalar(w io.Writer, v *vec[float64]) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if len(v.series) == 0 && len(v.labels) > 0 {
+		return
+	}
+	v.writeHeader(w)
+	if len(v.labels) == 0 {
+		fmt.Fprintf(w, "%s %s\n", v.name, formatFloat(v.get(nil, zero).value))
+		return
+	}
+	for _, s := range v.sorted() {
+		fmt.Fprintf(w, "%s%s %s\n", v.name, formatLabels(v.labels, s.labelValues), formatFloat(s.value))
+	}
+}
+
+// Histogram distribui observações (ex: latências em segundos) em buckets cumulativos.
+type Histogram struct {
+	vec[*histogramValue]
+	buckets []float64
+}
+
+type histogramValue struct {
+	counts []uint64 // Por bucket (não cumulativo); o último é o +Inf
+	sum    float64
+	count  uint64
+}
+
+// Observe registra um valor na série dos valores de rótulos.
+func (h *Histogram) Observe(value float64, labelValues ...string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	s := h.get(labelValues, h.newValue)
+	i, _ := slices.BinarySearch(h.buckets, value) // primeiro limite >= value
+	s.value.counts[i]++
+	s.value.sum += value
+	s.value.count++
+}
+
+// Count devolve quantas observações a série recebeu.
+func (h *Histogram) Count(labelValues ...string) uint64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if s, ok := h.lookup(labelValues); ok {
+		return s.value.count
+	}
+	return 0
+}
+
+func (h *Histogram) newValue() *histogramValue {
+	return &histogramValue{counts: make([]uint64, len(h.buckets)+1)}
+}
+
+func (h *Histogram) Write(w io.Writer) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if len(h.series) == 0 {
+		return
+	}
+	h.writeHeader(w)
+
+	bucketLabels := append(slices.Clone(h.labels), "le")
+	for _, s := range h.sorted() {
+		var cumulative uint64
+		for i, count := range s.value.counts {
+			cumulative += count
+			le := "+Inf"
+			if i < len(h.buckets) {
+				le = formatFloat(h.buckets[i])
+			}
+			fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, formatLabels(bucketLabels, append(slices.Clone(s.labelValues), le)), cumulative)
+		}
+		labels := formatLabels(h.labels, s.labelValues)
+		fmt.Fprintf(w, "%s_sum%s %s\n", h.name, labels, formatFloat(s.value.sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", h.name, labels, s.value.count)
+	}
+}
+
+func formatLabels(names, values []string) string {
+	if len(names) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, name := range names {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(name)
+		b.WriteString(`="`)
+		b.WriteString(labelEscaper.Replace(values[i]))
+		b.WriteByte('"')
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+var (
+	labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+)
+
+func escapeHelp(s string) string {
+	return helpEscaper.Replace(s)
+}
@@ -0,0 +1,85 @@
+package metrics_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go-api-first-steps/internal/metrics"
+	"go-api-first-steps/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistry_WriteText(t *testing.T) {
+	reg := metrics.NewRegistry()
+	requests := reg.NewCounter("requests_total", "Requisições.", "method", "route")
+	inFlight := reg.NewGauge("in_flight", "Em andamento.")
+	latency := reg.NewHistogram("latency_seconds", "Latência.", []float64{0.1, 1}, "route")
+	reg.NewCounter("unused_total", "Sem séries ainda.", "route")
+
+	requests.Inc("GET", "/products/:id")
+	requests.Add(2, "GET", "/products/:id")
+	requests.Inc("POST", `/a"b\c`)
+	inFlight.Inc()
+	inFlight.Inc()
+	inFlight.Dec()
+	latency.Observe(0.05, "/products")
+	latency.Observe(0.1, "/products")
+	latency.Observe(3, "/products")
+
+	var b strings.Builder
+	assert.NoError(t, reg.WriteText(&b))
+	assert.Equal(t, `# HELP in_flight Em andamento.
+# TYPE in_flight gauge
+in_flight 1
+# HELP latency_seconds Latência.
+# TYPE latency_seconds histogram
+latency_seconds_bucket{route="/products",le="0.1"} 2
+latency_seconds_bucket{route="/products",le="1"} 2
+latency_seconds_bucket{route="/products",le="+Inf"} 3
+latency_seconds_sum{route="/products"} 3.15
+latency_seconds_count{route="/products"} 3
+# HELP requests_total Requisições.
+# TYPE requests_total counter
+requests_total{method="GET",route="/products/:id"} 3
+requests_total{method="POST",route="/a\"b\\c"} 1
+`, b.String())
+
+	assert.Equal(t, float64(3), requests.Value("GET", "/products/:id"))
+	assert.Equal(t, float64(0), requests.Value("DELETE", "/products/:id"))
+	assert.Equal(t, uint64(3), latency.Count("/products"))
+}
+
+func TestRegistry_Misuse(t *testing.T) {
+	reg := metrics.NewRegistry()
+	c := reg.NewCounter("dup_total", "Duplicada.", "route")
+
+	assert.Panics(t, func() { reg.NewGauge("dup_total", "Outra.") }, "nome repetido")
+	assert.Panics(t, func() { c.Inc() }, "quantidade de rótulos errada")
+	assert.Panics(t, func() { c.Add(-1, "/x") }, "contador não diminui")
+	assert.Panics(t, func() { reg.NewHistogram("h", "H.", []float64{1, 0.5}) }, "buckets fora de ordem")
+}
+
+func TestDefault_Handler(t *testing.T) {
+	w := httptest.NewRecorder()
+	metrics.Default.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	assert.Equal(t, "text/plain; version=0.0.4; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Body.String(), "# TYPE go_goroutines gauge")
+	assert.Contains(t, w.Body.String(), `go_info{version="go`)
+}
+
+func TestLogCollector(t *testing.T) {
+	reg := metrics.NewRegistry()
+	reg.MustRegister(metrics.NewLogCollector(func() []logger.AsyncStats {
+		return []logger.AsyncStats{{Sink: "appinsights", Queued: 3, Dropped: 7, Failed: 1}}
+	}))
+
+	var out strings.Builder
+	assert.NoError(t, reg.WriteText(&out))
+	assert.Contains(t, out.String(), `log_queue_length{sink="appinsights"} 3`)
+	assert.Contains(t, out.String(), `log_records_dropped_total{sink="appinsights"} 7`)
+	assert.Contains(t, out.String(), "# TYPE log_sink_errors_total counter")
+}
@@ -0,0 +1,44 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+	"time"
+)
+
+// runtimeCollector lê as estatísticas do runtime do Go a cada coleta.
+type runtimeCollector struct {
+	start time.Time
+}
+
+// NewRuntimeCollector expõe goroutines, memória, GC e a versão do Go (go_*),
+// além do horário de início do processo.
+func NewRuntimeCollector() Collector {
+	return &runtimeCollector{start: time.Now()}
+}
+
+func (c *runtimeCollector) Name() string { return "go_runtime" }
+
+func (c *runtimeCollector) Write(w io.Writer) {
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+
+	gauge := func(name, help string, value float64) {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s gauge\n%s %s\n", name, help, name, name, formatFloat(value))
+	}
+	counter := func(name, help string, value float64) {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n%s %s\n", name, help, name, name, formatFloat(value))
+	}
+
+	gauge("go_goroutines", "Goroutines em execução.", float64(runtime.NumGoroutine()))
+	fmt.Fprintf(w, "# HELP go_info Versão do Go.\n# TYPE go_info gauge\ngo_info%s 1\n", formatLabels([]string{"version"}, []string{runtime.Version()}))
+	gauge("go_memstats_alloc_bytes", "Bytes alocados no heap e ainda em uso.", float64(ms.Alloc))
+	counter("go_memstats_alloc_bytes_total", "Bytes alocados no heap desde o início.", float64(ms.TotalAlloc))
+	gauge("go_memstats_heap_inuse_bytes", "Bytes em spans do heap em uso.", float64(ms.HeapInuse))
+	gauge("go_memstats_heap_objects", "Objetos alocados no heap.", float64(ms.HeapObjects))
+	gauge("go_memstats_sys_bytes", "Bytes obtidos do sistema operacional.", float64(ms.Sys))
+	counter("go_gc_cycles_total", "Ciclos de GC concluídos.", float64(ms.NumGC))
+	counter("go_gc_pause_seconds_total", "Tempo total de pausa do GC.", float64(ms.PauseTotalNs)/1e9)
+	gauge("process_start_time_seconds", "Início do processo (Unix, segundos).", float64(c.start.UnixNano())/1e9)
+}
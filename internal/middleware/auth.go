@@ -2,12 +2,19 @@ package middleware
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
 	"strings"
+	"sync"
+	"time"
 
+	"go-api-first-steps/internal/authz"
 	"go-api-first-steps/internal/config"
+	"go-api-first-steps/internal/domain"
 
 	"github.com/coreos/go-oidc/v3/oidc"
 	"github.com/gin-gonic/gin"
@@ -19,47 +26,445 @@ type User struct {
 	Name     string   `json:"name"`
 	Email    string   `json:"email"`
 	Username string   `json:"preferred_username"` // ou "sub" se não tiver
+	ClientID string   `json:"azp"`                // Client OIDC que emitiu o token (authorized party)
 	Roles    []string `json:"-"`
+	Type     string   `json:"-"` // PrincipalUser ou PrincipalService
+	Tenant   string   `json:"-"` // Da claim TenantClaim ou do emissor (multi-issuer); vazio = tenant padrão
+
+	// Attributes vêm das claims em AttributeClaims (ex: categories) e alimentam as regras ABAC
+	Attributes map[string][]string `json:"-"`
+}
+
+// Actor converte o usuário no domain.Actor usado pelos serviços (nil = anônimo).
+func (u *User) Actor() domain.Actor {
+	if u == nil {
+		return domain.Actor{}
+	}
+	return domain.Actor{ID: u.ID, Tenant: u.Tenant, Roles: u.Roles, Attributes: u.Attributes}
+}
+
+// Tipos de principal: pessoas (token OIDC) ou serviços (API key)
+const (
+	PrincipalUser    = "user"
+	PrincipalService = "service"
+)
+
+// APIKeyHeader é o header das API keys de serviço (alternativa: "Authorization: ApiKey <chave>")
+const APIKeyHeader = "X-API-Key"
+
+// APIKeyVerifier valida API keys de serviço (implementado por services/apikey.Service).
+type APIKeyVerifier interface {
+	Verify(ctx context.Context, raw string) (*domain.APIKey, error)
 }
 
 const userContextKey = "user_context"
 
+// userCtxKey é a chave tipada usada em context.Context (fora do Gin)
+type userCtxKey struct{}
+
 // Authenticator gerencia a verificação de tokens OIDC.
 // Ele mantém uma referência ao Verifier do go-oidc para validar tokens JWT.
+// O Verifier pode ser definido depois da inicialização (SetVerifier), quando a
+// descoberta OIDC termina em background (veja OIDCDiscovery).
 type Authenticator struct {
 	Verifier *oidc.IDTokenVerifier
-	ClientID string // ClientID usado para validar resource_access
-	DevMode  bool   // Se true, bypassa a autenticação (apenas para desenvolvimento)
+	ClientID string         // ClientID usado para validar resource_access
+	Audience string         // aud esperado nos tokens introspectados (vazio = ClientID)
+	Roles    *RoleMapping   // De onde vêm as roles no token (nil = client roles do ClientID)
+	DevMode  bool           // Se true, não valida token: a identidade vem de DevUser ou dos headers X-Dev-*
+	DevUser  User           // Identidade padrão em DevMode
+	APIKeys  APIKeyVerifier // nil = API keys não aceitas
+
+	// Introspecção RFC 7662 (nil = desativada). Tokens opacos sempre usam a
+	// introspecção; JWTs só se IntrospectAll (detecta logout antes do exp).
+	Introspector  *Introspector
+	IntrospectAll bool
+	Revocations   RevocationList // jti/sid revogados (nil = sem checagem)
+
+	// Descoberta OIDC em background; enquanto não termina, Authenticate devolve ErrAuthUnavailable
+	Discovery *OIDCDiscovery
+
+	// Multi-issuer: um Authenticator por emissor confiável, indexado pelo iss.
+	// O emissor é escolhido pelo iss (ainda não verificado) e o token é validado
+	// com o Verifier, o audience e o mapeamento de roles daquele emissor.
+	Tenants map[string]*Authenticator
+	Tenant  string // Nome do tenant deste emissor (nos Authenticators de Tenants)
+
+	// TenantClaim é o caminho da claim com o tenant do usuário (nil = tenant do emissor).
+	// Em multi-issuer o tenant do emissor prevalece e a claim só é conferida; veja SetTenantClaim.
+	TenantClaim []string
+
+	// AttributeClaims: atributo do User -> caminho da claim (veja ParseAttributeClaims)
+	AttributeClaims map[string][]string
+
+	// ClientCerts mapeia certificados de cliente (mTLS) para identidades de serviço.
+	// nil = certificados não autenticam. Usado só sem Authorization/API key.
+	ClientCerts *ClientCertMapper
+
+	mu sync.RWMutex // Protege Verifier após a inicialização
 }
 
+// Headers (e metadata gRPC, em minúsculas) aceitos em DevMode para trocar a identidade por requisição
+const (
+	DevUserHeader   = "X-Dev-User"
+	DevRolesHeader  = "X-Dev-Roles"  // Roles separadas por vírgula; vazio = sem roles
+	DevTenantHeader = "X-Dev-Tenant" // Tenant da identidade; vazio = tenant padrão
+)
+
+// DefaultDevUser é a identidade de DevMode quando DEV_USER/DEV_ROLES não são configurados.
+var DefaultDevUser = User{ID: "dev-user", Username: "dev-user", Roles: []string{"admin"}}
+
 // NewAuthenticator inicializa o Provider OIDC e configura o Verifier.
 // Esta função deve ser chamada apenas uma vez na inicialização da aplicação (Singleton).
+// Falha se o provedor estiver inacessível; para subir mesmo assim, use NewOIDCAuthenticator.
 func NewAuthenticator(cfg *config.Config) (*Authenticator, error) {
-	// 1. Configura o Provider (Auto Discovery das chaves)
-	provider, err := oidc.NewProvider(context.Background(), cfg.KeycloakURL)
+	auth, err := NewOIDCAuthenticator(cfg)
 	if err != nil {
-		return nil, fmt.Errorf("falha ao inicializar OIDC Provider: %w", err)
+		return nil, err
+	}
+	if err := auth.Discovery.Init(context.Background()); err != nil {
+		return nil, err
 	}
+	return auth, nil
+}
 
-	oidcConfig := &oidc.Config{
+// NewOIDCAuthenticator cria o Authenticator com uma OIDCDiscovery ainda não executada
+// (sem acesso à rede). Chame Discovery.Init e, se não ficar pronto, Discovery.Run em background.
+func NewOIDCAuthenticator(cfg *config.Config) (*Authenticator, error) {
+	roles, err := ParseRoleMapping(cfg.RoleSources, cfg.RoleMerge, cfg.ClientID)
+	if err != nil {
+		return nil, fmt.Errorf("mapeamento de roles inválido: %w", err)
+	}
+
+	auth := &Authenticator{
 		ClientID: cfg.ClientID,
+		Roles:    roles,
+		DevMode:  false,
+	}
+	NewOIDCDiscovery(auth, cfg.KeycloakURL, cfg.ClientID, cfg.OIDCJWKSCacheFile, cfg.OIDCRetryMaxInterval)
+	return auth, nil
+}
+
+// NewOfflineAuthenticator valida tokens com chaves locais (keys), sem discovery
+// no Keycloak. Issuer e audience vazios desativam a respectiva checagem.
+func NewOfflineAuthenticator(cfg *config.Config, keys oidc.KeySet) (*Authenticator, error) {
+	roles, err := ParseRoleMapping(cfg.RoleSources, cfg.RoleMerge, cfg.ClientID)
+	if err != nil {
+		return nil, fmt.Errorf("mapeamento de roles inválido: %w", err)
+	}
+
+	algs := make([]string, 0, len(offlineSigningAlgs))
+	for _, alg := range offlineSigningAlgs {
+		algs = append(algs, string(alg))
+	}
+
+	leeway := cfg.JWTLeeway
+	oidcConfig := &oidc.Config{
+		ClientID:             cfg.JWTAudience,
+		SkipClientIDCheck:    cfg.JWTAudience == "",
+		SkipIssuerCheck:      cfg.JWTIssuer == "",
+		SupportedSigningAlgs: algs,
+		// O go-oidc não tem leeway para exp; atrasar o relógio tem o mesmo efeito
+		Now: func() time.Time { return time.Now().Add(-leeway) },
 	}
-	verifier := provider.Verifier(oidcConfig)
 
 	return &Authenticator{
-		Verifier: verifier,
+		Verifier: oidc.NewVerifier(cfg.JWTIssuer, keys, oidcConfig),
 		ClientID: cfg.ClientID,
+		Roles:    roles,
 		DevMode:  false,
 	}, nil
 }
 
-// NewDevAuthenticator cria um autenticador para desenvolvimento que bypassa a validação.
-// NUNCA use em produção!
-func NewDevAuthenticator() *Authenticator {
+// NewDevAuthenticator cria um autenticador para desenvolvimento que não valida tokens:
+// toda requisição é feita por defaultUser (ou DefaultDevUser, se ID vazio), a menos
+// que os headers X-Dev-User/X-Dev-Roles digam outra coisa. As regras de roles e da
+// política continuam valendo. NUNCA use em produção!
+func NewDevAuthenticator(defaultUser User) *Authenticator {
+	if defaultUser.ID == "" {
+		defaultUser = DefaultDevUser
+	}
 	return &Authenticator{
 		Verifier: nil,
 		DevMode:  true,
+		DevUser:  defaultUser,
+	}
+}
+
+// DevIdentity monta o User de DevMode. userID vazio usa o DevUser; roles nil mantém
+// as roles do DevUser (se userID também for vazio) e roles vazio significa sem roles.
+func (a *Authenticator) DevIdentity(userID string, roles []string) *User {
+	user := a.DevUser
+	if userID != "" && userID != user.ID {
+		user = User{ID: userID, Username: userID}
+	}
+	if roles != nil {
+		user.Roles = roles
+	}
+	user.Roles = append([]string(nil), user.Roles...)
+	user.Type = PrincipalUser
+	return &user
+}
+
+// ParseDevRoles interpreta "admin, manager" (valor de X-Dev-Roles / DEV_ROLES).
+// Sempre devolve um slice não nil, para que "" signifique "sem roles".
+func ParseDevRoles(s string) []string {
+	roles := []string{}
+	for _, r := range strings.Split(s, ",") {
+		if r = strings.TrimSpace(r); r != "" {
+			roles = append(roles, r)
+		}
+	}
+	return roles
+}
+
+// Erros retornados por Authenticate. Os transportes (REST, gRPC) os traduzem
+// para o status adequado (500, 401).
+var (
+	ErrAuthNotConfigured = errors.New("autenticação não configurada")
+	ErrInvalidToken      = errors.New("token inválido")
+	ErrInvalidClaims     = errors.New("erro ao ler claims")
+	ErrInvalidAPIKey     = errors.New("API key inválida")
+	ErrAuthUnavailable   = errors.New("autenticação indisponível (provedor OIDC inacessível)")
+)
+
+// SetVerifier troca o Verifier em runtime (descoberta em background, JWKS do cache).
+func (a *Authenticator) SetVerifier(v *oidc.IDTokenVerifier) {
+	a.mu.Lock()
+	a.Verifier = v
+	a.mu.Unlock()
+}
+
+func (a *Authenticator) verifier() *oidc.IDTokenVerifier {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.Verifier
+}
+
+// ExpectsToken indica se a ausência de credencial deve ser 401 (e não 500/503):
+// há alguma forma de validar tokens, já pronta ou a caminho.
+func (a *Authenticator) ExpectsToken() bool {
+	return a.verifier() != nil || a.Introspector != nil || a.Discovery != nil || len(a.Tenants) > 0
+}
+
+// Authenticate valida o token (sem o prefixo "Bearer ") e monta o User a partir das claims.
+// É compartilhado entre o middleware HTTP e o interceptor gRPC.
+//
+// Com Introspector configurado, tokens opacos (e todos os tokens, se IntrospectAll)
+// são validados no provedor em vez de localmente. Em ambos os casos, tokens cujo
+// jti ou sid estejam na RevocationList são rejeitados.
+func (a *Authenticator) Authenticate(ctx context.Context, rawToken string) (*User, error) {
+	ctx, span := startAuthSpan(ctx, CredentialToken)
+	user, err := a.authenticateToken(ctx, rawToken)
+	endAuthSpan(span, user, err)
+	return user, err
+}
+
+func (a *Authenticator) authenticateToken(ctx context.Context, rawToken string) (*User, error) {
+	var rawClaims map[string]any
+	var err error
+
+	introspected := a.Introspector != nil && (a.IntrospectAll || !looksLikeJWT(rawToken))
+	if introspected {
+		rawClaims, err = a.Introspector.Introspect(ctx, rawToken)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		issuer, err := a.issuerFor(unverifiedIssuer(rawToken))
+		if err != nil {
+			return nil, err
+		}
+		if rawClaims, err = issuer.verify(ctx, rawToken); err != nil {
+			return nil, err
+		}
+	}
+
+	jti, sid := stringClaim(rawClaims, "jti"), stringClaim(rawClaims, "sid")
+	if a.Revocations != nil && a.Revocations.IsRevoked(jti, sid) {
+		return nil, fmt.Errorf("%w: token revogado (jti=%q, sid=%q)", ErrInvalidToken, jti, sid)
+	}
+
+	// Roles, tenant e (na introspecção) audience vêm da configuração do emissor
+	issuer, err := a.issuerFor(stringClaim(rawClaims, "iss"))
+	if err != nil {
+		return nil, err
+	}
+	if introspected {
+		if err := issuer.checkIntrospected(rawClaims); err != nil {
+			return nil, err
+		}
+	}
+
+	// Roles vêm das fontes configuradas (client, realm, scope, groups, claims).
+	// Sem roles nas fontes, assume vazio (sem permissão).
+	mapping := issuer.Roles
+	if mapping == nil {
+		mapping = DefaultRoleMapping(issuer.ClientID)
+	}
+
+	tenant, err := a.tenantOf(issuer, rawClaims)
+	if err != nil {
+		return nil, err
+	}
+
+	// azp vem no JWT; na introspecção, o Keycloak também devolve client_id
+	clientID := stringClaim(rawClaims, "azp")
+	if clientID == "" {
+		clientID = stringClaim(rawClaims, "client_id")
+	}
+
+	return &User{
+		ID:       stringClaim(rawClaims, "sub"),
+		Name:     stringClaim(rawClaims, "name"),
+		Email:    stringClaim(rawClaims, "email"),
+		Username: stringClaim(rawClaims, "preferred_username"),
+		ClientID: clientID,
+		Roles:    mapping.Roles(rawClaims),
+		Type:     PrincipalUser,
+		Tenant:   tenant,
+
+		Attributes: a.attributesOf(rawClaims),
+	}, nil
+}
+
+// issuerFor devolve o Authenticator do emissor iss. Sem multi-issuer, é o próprio a.
+func (a *Authenticator) issuerFor(iss string) (*Authenticator, error) {
+	if len(a.Tenants) == 0 {
+		return a, nil
+	}
+	issuer, ok := a.Tenants[iss]
+	if !ok {
+		return nil, fmt.Errorf("%w: emissor não confiável %q", ErrInvalidToken, iss)
+	}
+	return issuer, nil
+}
+
+// verify valida o JWT com o Verifier deste Authenticator e devolve as claims.
+func (a *Authenticator) verify(ctx context.Context, rawToken string) (map[string]any, error) {
+	verifier := a.verifier()
+	if verifier == nil {
+		if a.Discovery != nil {
+			return nil, ErrAuthUnavailable
+		}
+		return nil, ErrAuthNotConfigured
 	}
+
+	// Valida o Token
+	idToken, err := verifier.Verify(ctx, rawToken)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	var rawClaims map[string]any
+	if err := idToken.Claims(&rawClaims); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidClaims, err)
+	}
+	return rawClaims, nil
+}
+
+// unverifiedIssuer lê o iss do payload sem validar a assinatura; serve só para
+// escolher o emissor, que depois valida o token (inclusive o próprio iss).
+func unverifiedIssuer(token string) string {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return ""
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return ""
+	}
+	var claims struct {
+		Issuer string `json:"iss"`
+	}
+	_ = json.Unmarshal(payload, &claims)
+	return claims.Issuer
+}
+
+// looksLikeJWT diferencia JWTs (header.payload.assinatura) de tokens opacos.
+func looksLikeJWT(token string) bool {
+	return strings.Count(token, ".") == 2
+}
+
+func stringClaim(claims map[string]any, name string) string {
+	s, _ := claims[name].(string)
+	return s
+}
+
+// AuthenticateAPIKey valida uma API key e monta o User do serviço (Type=PrincipalService).
+// O ID é "apikey:<prefixo>", para distinguir serviços de usuários em logs e rate limit.
+func (a *Authenticator) AuthenticateAPIKey(ctx context.Context, rawKey string) (*User, error) {
+	ctx, span := startAuthSpan(ctx, CredentialAPIKey)
+	user, err := a.authenticateAPIKey(ctx, rawKey)
+	endAuthSpan(span, user, err)
+	return user, err
+}
+
+func (a *Authenticator) authenticateAPIKey(ctx context.Context, rawKey string) (*User, error) {
+	if a.APIKeys == nil {
+		return nil, fmt.Errorf("%w: API keys desabilitadas", ErrInvalidAPIKey)
+	}
+
+	key, err := a.APIKeys.Verify(ctx, rawKey)
+	if errors.Is(err, domain.ErrInvalidAPIKey) {
+		return nil, ErrInvalidAPIKey
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &User{
+		ID:       "apikey:" + key.Prefix,
+		Name:     key.Name,
+		Username: key.Name,
+		ClientID: "apikey:" + key.Prefix,
+		Roles:    append([]string(nil), key.Roles...),
+		Type:     PrincipalService,
+		Tenant:   key.Tenant,
+	}, nil
+}
+
+// Credentials extrai a credencial de um header Authorization e/ou X-API-Key.
+// Devolve apiKey != "" quando a requisição usa API key.
+func Credentials(authorization, apiKeyHeader string) (token, apiKey string) {
+	if apiKeyHeader != "" {
+		return "", apiKeyHeader
+	}
+	if scheme, value, ok := strings.Cut(authorization, " "); ok && strings.EqualFold(scheme, "ApiKey") {
+		return "", strings.TrimSpace(value)
+	}
+	return strings.TrimPrefix(authorization, "Bearer "), ""
+}
+
+// HasRoles aplica a lógica de roles do CheckMiddleware: "AND" exige todas as roles,
+// qualquer outro valor ("OR") exige pelo menos uma. Sem roles requeridas, sempre permite.
+func HasRoles(user *User, mode string, requiredRoles ...string) bool {
+	if len(requiredRoles) == 0 {
+		return true
+	}
+
+	rolesMap := make(map[string]bool)
+	for _, r := range user.Roles {
+		rolesMap[r] = true
+	}
+
+	if strings.ToUpper(mode) == "AND" {
+		// Todas as roles requeridas devem estar presentes
+		for _, req := range requiredRoles {
+			if !rolesMap[req] {
+				return false
+			}
+		}
+		return true
+	}
+
+	// Pelo menos uma role requerida deve estar presente (modo OR)
+	for _, req := range requiredRoles {
+		if rolesMap[req] {
+			return true
+		}
+	}
+	return false
 }
 
 // CheckMiddleware cria um handler do Gin para validar o token JWT presente no header Authorization.
@@ -74,117 +479,138 @@ func NewDevAuthenticator() *Authenticator {
 //	auth.CheckMiddleware("AND", "admin", "finance") // Requer admin E finance
 func (a *Authenticator) CheckMiddleware(mode string, requiredRoles ...string) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// DevMode: Bypassa autenticação (apenas para desenvolvimento)
-		if a.DevMode {
-			slog.DebugContext(c.Request.Context(), "DevMode: Autenticação bypassada")
-			c.Set("user_id", "dev-user")
-			c.Next()
+		user, ok := a.authenticateRequest(c)
+		if !ok {
 			return
 		}
 
-		if a.Verifier == nil {
-			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Autenticação não configurada"})
-			return
-		}
+		// Validação de Roles
+		if len(requiredRoles) > 0 {
+			if len(user.Roles) == 0 {
+				slog.WarnContext(c.Request.Context(), "Nenhuma role encontrada no token", "client_id", a.ClientID)
+			}
 
-		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Token não informado"})
-			return
+			if !HasRoles(user, mode, requiredRoles...) {
+				msg := "Sem permissão"
+				if strings.ToUpper(mode) == "AND" {
+					msg = "Sem permissão (Faltam roles)"
+				}
+				RecordAuthz("http", false)
+				c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": msg})
+				return
+			}
 		}
+		RecordAuthz("http", true)
 
-		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
+		c.Set("user_id", user.ID)
+		c.Next()
+	}
+}
 
-		// Valida o Token
-		idToken, err := a.Verifier.Verify(c.Request.Context(), tokenString)
-		if err != nil {
-			slog.WarnContext(c.Request.Context(), "Token inválido", "error", err)
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Token inválido"})
+// CheckPolicy cria um handler do Gin que autentica o token e autoriza a rota
+// pela política declarativa (hierarquia de roles + expressões de permissão),
+// em vez de roles fixas no código. A rota é identificada por método + c.FullPath().
+//
+// Exemplo:
+//
+//	products.GET("", auth.CheckPolicy(enforcer), h.List)
+func (a *Authenticator) CheckPolicy(enforcer *authz.Enforcer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, ok := a.authenticateRequest(c)
+		if !ok {
 			return
 		}
 
-		// Validação de Roles
-		if len(requiredRoles) > 0 {
-			var claims struct {
-				ResourceAccess map[string]struct {
-					Roles []string `json:"roles"`
-				} `json:"resource_access"`
-				Name              string `json:"name"`
-				Email             string `json:"email"`
-				PreferredUsername string `json:"preferred_username"`
-			}
-			if err := idToken.Claims(&claims); err != nil {
-				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Erro ao ler claims"})
-				return
-			}
+		decision := enforcer.Decide(user.Roles, c.Request.Method, c.FullPath())
+		RecordAuthz("http", decision.Allowed)
+		if !decision.Allowed {
+			slog.WarnContext(c.Request.Context(), "Acesso negado pela política",
+				"user_id", user.ID,
+				"principal", user.Type,
+				"route", c.Request.Method+" "+c.FullPath(),
+				"reason", decision.Reason,
+			)
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Sem permissão"})
+			return
+		}
 
-			// Busca as roles específicas do ClientID configurado
-			var userRoles []string
-			if clientRoles, ok := claims.ResourceAccess[a.ClientID]; ok {
-				userRoles = clientRoles.Roles
-			} else {
-				// Fallback: Se não achar as roles do cliente, assume vazio (sem permissão)
-				slog.WarnContext(c.Request.Context(), "Nenhuma role encontrada para o ClientID", "client_id", a.ClientID)
-				userRoles = []string{}
-			}
+		c.Set("user_id", user.ID)
+		c.Next()
+	}
+}
 
-			// Injeta usuário Rico no Contexto
-			user := &User{
-				ID:       idToken.Subject,
-				Name:     claims.Name,
-				Email:    claims.Email,
-				Username: claims.PreferredUsername,
-				Roles:    userRoles,
-			}
-			c.Set(userContextKey, user)
+// authenticateRequest valida o header Authorization e grava o User no contexto.
+// Em caso de falha, aborta a requisição com o status adequado e devolve ok=false.
+func (a *Authenticator) authenticateRequest(c *gin.Context) (*User, bool) {
+	// DevMode: identidade sintética (sem token), mas as roles continuam sendo checadas
+	if a.DevMode {
+		var roles []string
+		if values, ok := c.Request.Header[DevRolesHeader]; ok && len(values) > 0 {
+			roles = ParseDevRoles(values[0])
+		}
+		user := a.DevIdentity(c.GetHeader(DevUserHeader), roles)
+		user.Tenant = c.GetHeader(DevTenantHeader)
+		slog.DebugContext(c.Request.Context(), "DevMode: identidade sintética", "user_id", user.ID, "roles", user.Roles)
+		RecordAuth("http", CredentialDev, nil)
+		SetUser(c, user)
+		return user, true
+	}
 
-			rolesMap := make(map[string]bool)
-			for _, r := range userRoles {
-				rolesMap[r] = true
-			}
+	authHeader := c.GetHeader("Authorization")
+	tokenString, apiKey := Credentials(authHeader, c.GetHeader(APIKeyHeader))
 
-			if strings.ToUpper(mode) == "AND" {
-				// Todas as roles requeridas devem estar presentes
-				for _, req := range requiredRoles {
-					if !rolesMap[req] {
-						c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Sem permissão (Faltam roles)"})
-						return
-					}
-				}
-			} else {
-				// Pelo menos uma role requerida deve estar presente (modo OR)
-				hasAtLeastOne := false
-				for _, req := range requiredRoles {
-					if rolesMap[req] {
-						hasAtLeastOne = true
-						break
-					}
-				}
-				if !hasAtLeastOne {
-					c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Sem permissão"})
-					return
-				}
-			}
-		} else {
-			// Mesmo sem roles requeridas, precisamos parsear o usuário básico
-			var claims struct {
-				Name              string `json:"name"`
-				Email             string `json:"email"`
-				PreferredUsername string `json:"preferred_username"`
-			}
-			_ = idToken.Claims(&claims) // Ignora erro pois verificamos token antes
-
-			c.Set(userContextKey, &User{
-				ID:       idToken.Subject,
-				Name:     claims.Name,
-				Email:    claims.Email,
-				Username: claims.PreferredUsername,
-			})
+	var user *User
+	var err error
+	credential := CredentialToken
+	if apiKey != "" {
+		credential = CredentialAPIKey
+		user, err = a.AuthenticateAPIKey(c.Request.Context(), apiKey)
+	} else if authHeader == "" && a.ClientCerts != nil && HasClientCert(c.Request.TLS) {
+		credential = CredentialClientCert
+		user, err = a.AuthenticateClientCert(c.Request.TLS)
+	} else {
+		if authHeader == "" && a.ExpectsToken() {
+			RecordAuth("http", CredentialNone, nil)
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Token não informado"})
+			return nil, false
 		}
+		user, err = a.Authenticate(c.Request.Context(), tokenString)
+	}
+	RecordAuth("http", credential, err)
 
-		c.Set("user_id", idToken.Subject)
-		c.Next()
+	switch {
+	case errors.Is(err, ErrAuthNotConfigured):
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Autenticação não configurada"})
+		return nil, false
+	case errors.Is(err, ErrAuthUnavailable):
+		c.Header("Retry-After", "5")
+		c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": "Autenticação indisponível, tente novamente"})
+		return nil, false
+	case errors.Is(err, ErrInvalidToken):
+		slog.WarnContext(c.Request.Context(), "Token inválido", "error", err)
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Token inválido"})
+		return nil, false
+	case errors.Is(err, ErrInvalidAPIKey):
+		slog.WarnContext(c.Request.Context(), "API key inválida", "error", err)
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "API key inválida"})
+		return nil, false
+	case errors.Is(err, ErrInvalidClientCert):
+		slog.WarnContext(c.Request.Context(), "Certificado de cliente não autorizado", "error", err)
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Certificado de cliente não autorizado"})
+		return nil, false
+	case err != nil:
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Erro ao ler claims"})
+		return nil, false
 	}
+
+	// Injeta usuário Rico no Contexto
+	SetUser(c, user)
+	return user, true
+}
+
+// SetUser grava o usuário autenticado no contexto do Gin (lido por GetUser).
+func SetUser(c *gin.Context, user *User) {
+	c.Set(userContextKey, user)
 }
 
 // GetUser recupera o usuário autenticado do contexto
@@ -198,3 +624,15 @@ func GetUser(c *gin.Context) *User {
 	}
 	return nil
 }
+
+// WithUser devolve um context.Context carregando o usuário autenticado.
+// Usado por transportes que não passam pelo gin.Context (ex: gRPC).
+func WithUser(ctx context.Context, user *User) context.Context {
+	return context.WithValue(ctx, userCtxKey{}, user)
+}
+
+// UserFromContext recupera o usuário gravado por WithUser.
+func UserFromContext(ctx context.Context) *User {
+	user, _ := ctx.Value(userCtxKey{}).(*User)
+	return user
+}
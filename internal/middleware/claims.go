@@ -0,0 +1,232 @@
+package middleware
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Fontes de roles suportadas em RoleSource.Kind
+const (
+	RoleSourceRealm  = "realm"  // realm_access.roles
+	RoleSourceClient = "client" // resource_access[<client>].roles
+	RoleSourceScope  = "scope"  // scope (string separada por espaço) ou scp (lista)
+	RoleSourceGroups = "groups" // groups (o "/" inicial dos grupos do Keycloak é removido)
+	RoleSourceClaim  = "claim"  // caminho arbitrário, ex: claim:custom.permissions
+)
+
+// Estratégias de combinação entre fontes
+const (
+	RoleMergeUnion = "union" // junta as roles de todas as fontes
+	RoleMergeFirst = "first" // usa a primeira fonte (na ordem configurada) que tiver roles
+)
+
+// RoleSource é uma fonte de roles no token, com prefixo opcional
+// (ex: "scope:") para evitar colisão de nomes entre fontes.
+type RoleSource struct {
+	Kind   string
+	Path   []string
+	Prefix string
+}
+
+// RoleMapping extrai as roles do usuário das claims do token.
+type RoleMapping struct {
+	Sources []RoleSource
+	Merge   string
+}
+
+// DefaultRoleMapping lê apenas as client roles de clientID (comportamento original).
+func DefaultRoleMapping(clientID string) *RoleMapping {
+	return &RoleMapping{
+		Sources: []RoleSource{{Kind: RoleSourceClient, Path: []string{"resource_access", clientID, "roles"}}},
+		Merge:   RoleMergeUnion,
+	}
+}
+
+// ParseRoleMapping monta o RoleMapping a partir da configuração.
+//
+// sources é uma lista separada por vírgula de "fonte[=prefixo]", onde fonte é:
+//
+//	realm              -> realm_access.roles
+//	client             -> resource_access.<clientID>.roles
+//	client:<id>        -> resource_access.<id>.roles
+//	scope              -> scope / scp
+//	groups             -> groups
+//	claim:<caminho>    -> caminho com "." e ["chave"] (ex: claim:resource_access["app.web"].roles)
+//
+// Exemplo: "client,realm=realm:,scope=scope:"
+func ParseRoleMapping(sources, merge, clientID string) (*RoleMapping, error) {
+	m := &RoleMapping{Merge: strings.ToLower(strings.TrimSpace(merge))}
+	if m.Merge == "" {
+		m.Merge = RoleMergeUnion
+	}
+	if m.Merge != RoleMergeUnion && m.Merge != RoleMergeFirst {
+		return nil, fmt.Errorf("estratégia de merge inválida %q (use union ou first)", merge)
+	}
+
+	for _, entry := range strings.Split(sources, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		spec, prefix, _ := strings.Cut(entry, "=")
+		kind, arg, _ := strings.Cut(spec, ":")
+		src := RoleSource{Kind: strings.ToLower(kind), Prefix: prefix}
+
+		switch src.Kind {
+		case RoleSourceRealm:
+			src.Path = []string{"realm_access", "roles"}
+		case RoleSourceClient:
+			id := clientID
+			if arg != "" {
+				id = arg
+			}
+			src.Path = []string{"resource_access", id, "roles"}
+		case RoleSourceScope, RoleSourceGroups:
+		case RoleSourceClaim:
+			path, err := parseClaimPath(arg)
+			if err != nil {
+				return nil, fmt.Errorf("fonte de roles %q: %w", entry, err)
+			}
+			src.Path = path
+		default:
+			return nil, fmt.Errorf("fonte de roles desconhecida %q", entry)
+		}
+
+		m.Sources = append(m.Sources, src)
+	}
+
+	if len(m.Sources) == 0 {
+		return nil, fmt.Errorf("nenhuma fonte de roles configurada")
+	}
+	return m, nil
+}
+
+// Roles extrai as roles das claims (já decodificadas do JWT) conforme o mapeamento.
+// O resultado é ordenado e sem duplicatas.
+func (m *RoleMapping) Roles(claims map[string]any) []string {
+	seen := make(map[string]bool)
+	roles := []string{}
+
+	for _, src := range m.Sources {
+		values := src.extract(claims)
+		if len(values) == 0 {
+			continue
+		}
+
+		for _, v := range values {
+			role := src.Prefix + v
+			if !seen[role] {
+				seen[role] = true
+				roles = append(roles, role)
+			}
+		}
+
+		if m.Merge == RoleMergeFirst {
+			break
+		}
+	}
+
+	sort.Strings(roles)
+	return roles
+}
+
+func (s RoleSource) extract(claims map[string]any) []string {
+	switch s.Kind {
+	case RoleSourceScope:
+		// OAuth2 usa "scope" (string separada por espaço); Azure AD/Okta usam "scp" (lista ou string)
+		values := toStrings(claims["scope"], true)
+		return append(values, toStrings(claims["scp"], true)...)
+	case RoleSourceGroups:
+		values := toStrings(claims["groups"], false)
+		for i, g := range values {
+			values[i] = strings.TrimPrefix(g, "/")
+		}
+		return values
+	default:
+		return toStrings(lookupClaim(claims, s.Path), false)
+	}
+}
+
+// lookupClaim percorre objetos aninhados seguindo path.
+func lookupClaim(claims map[string]any, path []string) any {
+	var current any = claims
+	for _, key := range path {
+		obj, ok := current.(map[string]any)
+		if !ok {
+			return nil
+		}
+		current = obj[key]
+	}
+	return current
+}
+
+// toStrings aceita lista de strings ou string (separada por espaço se splitSpaces).
+func toStrings(v any, splitSpaces bool) []string {
+	switch val := v.(type) {
+	case string:
+		if splitSpaces {
+			return strings.Fields(val)
+		}
+		if val == "" {
+			return nil
+		}
+		return []string{val}
+	case []any:
+		out := make([]string, 0, len(val))
+		for _, item := range val {
+			if s, ok := item.(string); ok && s != "" {
+				out = append(out, s)
+			}
+		}
+		return out
+	case []string:
+		return val
+	}
+	return nil
+}
+
+// parseClaimPath interpreta caminhos no estilo JSONPath simplificado:
+// "a.b.c", "a[\"b.c\"].d" ou "$.a.b". Índices ("a[0]") e aspas simples não são suportados.
+func parseClaimPath(s string) ([]string, error) {
+	orig := s
+	s = strings.TrimPrefix(strings.TrimSpace(s), "$.")
+	if s == "" {
+		return nil, fmt.Errorf("caminho vazio")
+	}
+
+	var path []string
+	for {
+		var segment string
+		switch {
+		case strings.HasPrefix(s, `["`):
+			end := strings.Index(s, `"]`)
+			if end < 0 {
+				return nil, fmt.Errorf("colchete não fechado em %q", orig)
+			}
+			segment, s = s[2:end], s[end+2:]
+		case strings.HasPrefix(s, "["):
+			return nil, fmt.Errorf("colchete não suportado em %q (use [\"chave\"])", orig)
+		default:
+			end := strings.IndexAny(s, ".[")
+			if end < 0 {
+				end = len(s)
+			}
+			segment, s = s[:end], s[end:]
+		}
+		if segment == "" {
+			return nil, fmt.Errorf("segmento vazio em %q", orig)
+		}
+		path = append(path, segment)
+
+		switch {
+		case s == "":
+			return path, nil
+		case s[0] == '.':
+			s = s[1:] // Um segmento precisa vir depois do ponto
+		case s[0] != '[':
+			return nil, fmt.Errorf("esperado . ou [ depois de %q em %q", segment, orig)
+		}
+	}
+}
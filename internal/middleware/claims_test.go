@@ -0,0 +1,102 @@
+package middleware_test
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"testing"
+	"time"
+
+	"go-api-first-steps/internal/middleware"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// keycloakClaims imita um token do Keycloak com roles em todas as fontes
+var keycloakClaims = map[string]any{
+	"realm_access":    map[string]any{"roles": []any{"admin", "offline_access"}},
+	"resource_access": map[string]any{"product-api": map[string]any{"roles": []any{"develop"}}, "app.web": map[string]any{"roles": []any{"viewer"}}},
+	"scope":           "openid products:read",
+	"groups":          []any{"/finance", "/ops"},
+	"custom":          map[string]any{"perms": "single"},
+}
+
+func TestRoleMapping_Sources(t *testing.T) {
+	cases := []struct {
+		sources string
+		merge   string
+		want    []string
+	}{
+		{"client", "", []string{"develop"}},
+		{"realm", "", []string{"admin", "offline_access"}},
+		{"client,realm", "union", []string{"admin", "develop", "offline_access"}},
+		{"client,realm", "first", []string{"develop"}},
+		{"client:missing,realm", "first", []string{"admin", "offline_access"}},
+		{"scope=scope:", "", []string{"scope:openid", "scope:products:read"}},
+		{"groups=group:", "", []string{"group:finance", "group:ops"}},
+		{`claim:resource_access["app.web"].roles`, "", []string{"viewer"}},
+		{"claim:$.custom.perms", "", []string{"single"}},
+	}
+
+	for _, tc := range cases {
+		m, err := middleware.ParseRoleMapping(tc.sources, tc.merge, "product-api")
+		require.NoError(t, err, tc.sources)
+		assert.Equal(t, tc.want, m.Roles(keycloakClaims), tc.sources)
+	}
+}
+
+func TestRoleMapping_Invalid(t *testing.T) {
+	_, err := middleware.ParseRoleMapping("ldap", "", "x")
+	assert.Error(t, err)
+
+	_, err = middleware.ParseRoleMapping("realm", "intersection", "x")
+	assert.Error(t, err)
+
+	_, err = middleware.ParseRoleMapping("", "", "x")
+	assert.Error(t, err)
+
+	// Caminhos malformados falham na carga (antes, "[" sem aspas travava em loop)
+	for _, path := range []string{"groups[0]", "org['id']", `a["b"`, "a..b", "a.", ".a", `a[""]`, `a["b"]c`} {
+		_, err = middleware.ParseRoleMapping("claim:"+path, "", "x")
+		assert.Error(t, err, path)
+	}
+	var a middleware.Authenticator
+	assert.Error(t, a.SetTenantClaim("org['id']"))
+	_, err = middleware.ParseAttributeClaims("team=groups[0]")
+	assert.Error(t, err)
+}
+
+// Tokens no formato do cmd/mock_token (roles só em realm_access) devem ter roles
+func TestAuthenticate_RealmRoles(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	mapping, err := middleware.ParseRoleMapping("client,realm", "union", "product-api")
+	require.NoError(t, err)
+
+	auth := &middleware.Authenticator{
+		Verifier: oidc.NewVerifier("http://issuer.test", &oidc.StaticKeySet{PublicKeys: []crypto.PublicKey{key.Public()}},
+			&oidc.Config{ClientID: "product-api"}),
+		ClientID: "product-api",
+		Roles:    mapping,
+	}
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":          "http://issuer.test",
+		"aud":          "product-api",
+		"sub":          "usuario-teste-id-123",
+		"exp":          time.Now().Add(time.Hour).Unix(),
+		"realm_access": map[string]any{"roles": []string{"admin", "manager"}},
+	}).SignedString(key)
+	require.NoError(t, err)
+
+	user, err := auth.Authenticate(context.Background(), token)
+
+	require.NoError(t, err)
+	assert.Equal(t, "usuario-teste-id-123", user.ID)
+	assert.Equal(t, []string{"admin", "manager"}, user.Roles)
+}
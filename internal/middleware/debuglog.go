@@ -0,0 +1,67 @@
+package middleware
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-api-first-steps/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// DebugLogHeader liga os logs de debug de uma única requisição. O valor é
+// "<expires>.<assinatura>", emitido por POST /admin/log-level/debug-tokens para um
+// trace ID: só vale para requisições desse trace (traceparent) até expires (unix).
+const DebugLogHeader = "X-Debug-Log"
+
+// SignDebugToken gera o valor de DebugLogHeader para traceID, válido até expires.
+func SignDebugToken(secret []byte, traceID string, expires time.Time) string {
+	exp := strconv.FormatInt(expires.Unix(), 10)
+	return exp + "." + debugSignature(secret, traceID, exp)
+}
+
+// DebugLog verifica DebugLogHeader e, se a assinatura bater com o trace da
+// requisição, libera os logs de debug dela (logger.WithDebug). Sem secret o header
+// é ignorado. Registre depois de Tracing.
+func DebugLog(secret []byte) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := c.GetHeader(DebugLogHeader)
+		if token == "" || len(secret) == 0 {
+			c.Next()
+			return
+		}
+
+		ctx := c.Request.Context()
+		traceID := trace.SpanContextFromContext(ctx).TraceID().String()
+		if verifyDebugToken(secret, traceID, token, time.Now()) {
+			c.Request = c.Request.WithContext(logger.WithDebug(ctx))
+		} else {
+			slog.WarnContext(ctx, "Header de debug inválido ou expirado", "header", DebugLogHeader)
+		}
+		c.Next()
+	}
+}
+
+func verifyDebugToken(secret []byte, traceID, token string, now time.Time) bool {
+	exp, sig, ok := strings.Cut(token, ".")
+	if !ok {
+		return false
+	}
+	expires, err := strconv.ParseInt(exp, 10, 64)
+	if err != nil || now.Unix() >= expires {
+		return false
+	}
+	return hmac.Equal([]byte(sig), []byte(debugSignature(secret, traceID, exp)))
+}
+
+func debugSignature(secret []byte, traceID, exp string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(traceID + "." + exp))
+	return hex.EncodeToString(mac.Sum(nil))
+}
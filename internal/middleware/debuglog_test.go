@@ -0,0 +1,55 @@
+package middleware_test
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go-api-first-steps/internal/middleware"
+	"go-api-first-steps/internal/tracing"
+	"go-api-first-steps/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDebugLog_SignedHeader(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tracing.StartInMemory(t)
+	secret := []byte("segredo-debug")
+
+	// Logger com console em Info: o debug só aparece se o middleware liberar
+	var buf bytes.Buffer
+	log := slog.New(logger.NewLevelHandler(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}), logger.NewLevelController()))
+
+	r := gin.New()
+	r.Use(middleware.Tracing(), middleware.DebugLog(secret))
+	r.GET("/products", func(c *gin.Context) {
+		log.DebugContext(c.Request.Context(), "detalhe", "req", c.GetHeader("X-Req"))
+		c.Status(http.StatusOK)
+	})
+
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	call := func(name, trace, token string) {
+		req := httptest.NewRequest(http.MethodGet, "/products", nil)
+		req.Header.Set("X-Req", name)
+		req.Header.Set("Traceparent", "00-"+trace+"-00f067aa0ba902b7-01")
+		req.Header.Set(middleware.DebugLogHeader, token)
+		r.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	valid := middleware.SignDebugToken(secret, traceID, time.Now().Add(time.Minute))
+	call("valido", traceID, valid)
+	call("outro-trace", "0af7651916cd43dd8448eb211c80319c", valid)
+	call("expirado", traceID, middleware.SignDebugToken(secret, traceID, time.Now().Add(-time.Second)))
+	call("outra-chave", traceID, middleware.SignDebugToken([]byte("x"), traceID, time.Now().Add(time.Minute)))
+
+	out := buf.String()
+	assert.Contains(t, out, `"req":"valido"`)
+	assert.NotContains(t, out, `"req":"outro-trace"`)
+	assert.NotContains(t, out, `"req":"expirado"`)
+	assert.NotContains(t, out, `"req":"outra-chave"`)
+}
@@ -0,0 +1,77 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go-api-first-steps/internal/authz"
+	"go-api-first-steps/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupDevRouter(t *testing.T, defaultUser middleware.User) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	enforcer, err := authz.NewEnforcer("")
+	require.NoError(t, err)
+	auth := middleware.NewDevAuthenticator(defaultUser)
+
+	r := gin.New()
+	whoami := func(c *gin.Context) {
+		user := middleware.GetUser(c)
+		c.JSON(http.StatusOK, gin.H{"id": user.ID, "roles": user.Roles})
+	}
+	r.GET("/api/v1/products", auth.CheckPolicy(enforcer), whoami)
+	r.DELETE("/api/v1/products/:id", auth.CheckPolicy(enforcer), whoami)
+	r.GET("/finance", auth.CheckMiddleware("AND", "admin", "finance"), whoami)
+	return r
+}
+
+func devRequest(r http.Handler, method, path string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestDevMode_DefaultIdentity(t *testing.T) {
+	r := setupDevRouter(t, middleware.User{})
+
+	w := devRequest(r, http.MethodDelete, "/api/v1/products/1", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"id":"dev-user","roles":["admin"]}`, w.Body.String())
+
+	// Sem a role "finance", o CheckMiddleware continua negando
+	assert.Equal(t, http.StatusForbidden, devRequest(r, http.MethodGet, "/finance", nil).Code)
+}
+
+func TestDevMode_HeadersOverrideIdentity(t *testing.T) {
+	r := setupDevRouter(t, middleware.User{ID: "ana", Roles: []string{"manager"}})
+
+	w := devRequest(r, http.MethodGet, "/api/v1/products", nil)
+	assert.JSONEq(t, `{"id":"ana","roles":["manager"]}`, w.Body.String())
+
+	// Outro usuário via header não herda as roles do padrão
+	w = devRequest(r, http.MethodGet, "/api/v1/products", map[string]string{middleware.DevUserHeader: "bob"})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	headers := map[string]string{middleware.DevUserHeader: "bob", middleware.DevRolesHeader: "develop"}
+	w = devRequest(r, http.MethodGet, "/api/v1/products", headers)
+	assert.JSONEq(t, `{"id":"bob","roles":["develop"]}`, w.Body.String())
+	assert.Equal(t, http.StatusForbidden, devRequest(r, http.MethodDelete, "/api/v1/products/1", headers).Code)
+
+	// X-Dev-Roles vazio = sem roles
+	w = devRequest(r, http.MethodGet, "/api/v1/products", map[string]string{middleware.DevRolesHeader: ""})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w = devRequest(r, http.MethodGet, "/finance", map[string]string{middleware.DevRolesHeader: "admin, finance"})
+	assert.Equal(t, http.StatusOK, w.Code)
+}
@@ -0,0 +1,250 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"go-api-first-steps/internal/tracing"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+)
+
+// Estados da descoberta OIDC (expostos no /ready)
+const (
+	AuthStatePending  = "pending"  // Ainda sem chaves: rotas protegidas respondem 503
+	AuthStateCached   = "cached"   // Validando com o JWKS salvo em disco; descoberta segue em background
+	AuthStateReady    = "ready"    // Descoberta concluída, JWKS remoto
+	AuthStateDisabled = "disabled" // DevMode ou AUTH_MODE=offline
+)
+
+// AuthState é o retrato da descoberta OIDC para health/readiness.
+type AuthState struct {
+	Status    string     `json:"status"`
+	Issuer    string     `json:"issuer,omitempty"`
+	Attempts  int        `json:"attempts"`
+	LastError string     `json:"last_error,omitempty"`
+	ReadyAt   *time.Time `json:"ready_at,omitempty"`
+}
+
+// OIDCDiscovery faz a descoberta OIDC (/.well-known/openid-configuration) com retry
+// e backoff exponencial, instalando o Verifier no Authenticator quando conclui.
+//
+// Se CacheFile estiver definido, o último JWKS obtido é salvo em disco e usado
+// num restart enquanto o provedor estiver fora do ar (warm restart).
+type OIDCDiscovery struct {
+	Auth         *Authenticator
+	Issuer       string
+	ClientID     string
+	CacheFile    string
+	MinBackoff   time.Duration
+	MaxBackoff   time.Duration
+	CacheRefresh time.Duration // Intervalo de atualização do JWKS em disco após pronto
+	HTTPClient   *http.Client
+
+	mu    sync.RWMutex
+	state AuthState
+	jwks  string // jwks_uri obtido na descoberta
+}
+
+// NewOIDCDiscovery cria a descoberta e a associa ao Authenticator (que passa a
+// responder 503, e não 500, enquanto ela não termina).
+func NewOIDCDiscovery(auth *Authenticator, issuer, clientID, cacheFile string, maxBackoff time.Duration) *OIDCDiscovery {
+	d := &OIDCDiscovery{
+		Auth:         auth,
+		Issuer:       issuer,
+		ClientID:     clientID,
+		CacheFile:    cacheFile,
+		MinBackoff:   time.Second,
+		MaxBackoff:   maxBackoff,
+		CacheRefresh: time.Hour,
+		HTTPClient:   &http.Client{Timeout: 10 * time.Second, Transport: tracing.Transport(nil)},
+		state:        AuthState{Status: AuthStatePending, Issuer: issuer},
+	}
+	auth.Discovery = d
+	return d
+}
+
+// State devolve o estado atual da descoberta.
+func (d *OIDCDiscovery) State() AuthState {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.state
+}
+
+// Ready indica se já há chaves para validar tokens (remotas ou do cache).
+func (d *OIDCDiscovery) Ready() bool {
+	status := d.State().Status
+	return status == AuthStateReady || status == AuthStateCached
+}
+
+// Init faz a primeira tentativa de descoberta. Se falhar, tenta carregar o JWKS
+// do cache em disco e devolve o erro da descoberta (para o modo estrito decidir).
+func (d *OIDCDiscovery) Init(ctx context.Context) error {
+	err := d.discover(ctx)
+	if err == nil {
+		return nil
+	}
+
+	if cacheErr := d.loadCache(); cacheErr == nil {
+		slog.WarnContext(ctx, "Provedor OIDC inacessível; usando JWKS em cache até a descoberta concluir",
+			"issuer", d.Issuer, "cache", d.CacheFile, "error", err)
+	} else {
+		slog.WarnContext(ctx, "Provedor OIDC inacessível; rotas protegidas responderão 503 até a descoberta concluir",
+			"issuer", d.Issuer, "error", err)
+	}
+	return err
+}
+
+// Run repete a descoberta com backoff até concluir e depois mantém o JWKS em disco
+// atualizado. Roda até ctx ser cancelado; deve ser chamado em goroutine.
+func (d *OIDCDiscovery) Run(ctx context.Context) {
+	backoff := d.MinBackoff
+	for d.State().Status != AuthStateReady {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+
+		if err := d.discover(ctx); err != nil {
+			backoff = min(backoff*2, d.MaxBackoff)
+			slog.WarnContext(ctx, "Descoberta OIDC falhou, tentando novamente",
+				"issuer", d.Issuer, "retry_in", backoff.String(), "error", err)
+			continue
+		}
+		slog.InfoContext(ctx, "Descoberta OIDC concluída", "issuer", d.Issuer)
+	}
+
+	if d.CacheFile == "" {
+		return
+	}
+	ticker := time.NewTicker(d.CacheRefresh)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := d.saveCache(ctx); err != nil {
+				slog.WarnContext(ctx, "Falha ao atualizar JWKS em cache", "error", err)
+			}
+		}
+	}
+}
+
+func (d *OIDCDiscovery) discover(ctx context.Context) error {
+	ctx = oidc.ClientContext(ctx, d.HTTPClient)
+
+	provider, err := oidc.NewProvider(ctx, d.Issuer)
+	if err != nil {
+		d.setState(func(s *AuthState) {
+			s.Attempts++
+			s.LastError = err.Error()
+		})
+		return fmt.Errorf("falha ao inicializar OIDC Provider: %w", err)
+	}
+
+	var meta struct {
+		JWKSURI string `json:"jwks_uri"`
+	}
+	_ = provider.Claims(&meta)
+
+	// O RemoteKeySet usa o contexto da criação para buscar chaves depois; não pode ser o ctx da tentativa
+	verifier := oidc.NewVerifier(d.Issuer,
+		oidc.NewRemoteKeySet(oidc.ClientContext(context.Background(), d.HTTPClient), meta.JWKSURI),
+		&oidc.Config{ClientID: d.ClientID})
+	d.Auth.SetVerifier(verifier)
+
+	d.mu.Lock()
+	d.jwks = meta.JWKSURI
+	d.mu.Unlock()
+
+	now := time.Now()
+	d.setState(func(s *AuthState) {
+		s.Attempts++
+		s.Status = AuthStateReady
+		s.LastError = ""
+		s.ReadyAt = &now
+	})
+
+	if d.CacheFile != "" {
+		if err := d.saveCache(ctx); err != nil {
+			slog.WarnContext(ctx, "Falha ao salvar JWKS em cache", "error", err)
+		}
+	}
+	return nil
+}
+
+// loadCache instala um Verifier com o JWKS salvo por uma execução anterior.
+func (d *OIDCDiscovery) loadCache() error {
+	if d.CacheFile == "" {
+		return errors.New("cache de JWKS desativado")
+	}
+	keys, err := NewFileKeySet(nil, d.CacheFile, "")
+	if err != nil {
+		return err
+	}
+
+	d.Auth.SetVerifier(oidc.NewVerifier(d.Issuer, keys, &oidc.Config{ClientID: d.ClientID}))
+	now := time.Now()
+	d.setState(func(s *AuthState) {
+		s.Status = AuthStateCached
+		s.ReadyAt = &now
+	})
+	return nil
+}
+
+// saveCache baixa o JWKS atual e grava no CacheFile (escrita atômica via rename).
+func (d *OIDCDiscovery) saveCache(ctx context.Context) error {
+	d.mu.RLock()
+	jwksURI := d.jwks
+	d.mu.RUnlock()
+	if jwksURI == "" {
+		return errors.New("jwks_uri ausente na descoberta")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, jwksURI, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := d.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("JWKS retornou status %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(d.CacheFile), 0o700); err != nil {
+		return err
+	}
+	tmp := d.CacheFile + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	// Valida antes de substituir o cache bom
+	if _, _, err := loadJWKS(tmp); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, d.CacheFile)
+}
+
+func (d *OIDCDiscovery) setState(update func(*AuthState)) {
+	d.mu.Lock()
+	update(&d.state)
+	d.mu.Unlock()
+}
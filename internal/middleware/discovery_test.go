@@ -0,0 +1,122 @@
+package middleware_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go-api-first-steps/internal/config"
+	"go-api-first-steps/internal/middleware"
+	"go-api-first-steps/internal/mockoidc"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// flakyIdP é um emissor mock que pode ser "derrubado" (responde 503 em tudo).
+type flakyIdP struct {
+	*mockoidc.Provider
+	down atomic.Bool
+}
+
+func startFlakyIdP(t *testing.T) *flakyIdP {
+	t.Helper()
+	ts := httptest.NewUnstartedServer(nil)
+	provider, err := mockoidc.New("http://"+ts.Listener.Addr().String(), "")
+	require.NoError(t, err)
+
+	idp := &flakyIdP{Provider: provider}
+	ts.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if idp.down.Load() {
+			http.Error(w, "fora do ar", http.StatusServiceUnavailable)
+			return
+		}
+		provider.Handler().ServeHTTP(w, r)
+	})
+	ts.Start()
+	t.Cleanup(ts.Close)
+	return idp
+}
+
+func oidcConfig(idp *flakyIdP, cacheFile string) *config.Config {
+	return &config.Config{
+		KeycloakURL:          idp.Issuer(),
+		ClientID:             mockoidc.DefaultClientID,
+		RoleSources:          "client,realm",
+		RoleMerge:            "union",
+		OIDCJWKSCacheFile:    cacheFile,
+		OIDCRetryMaxInterval: 20 * time.Millisecond,
+	}
+}
+
+func TestDiscovery_RetriesInBackground(t *testing.T) {
+	idp := startFlakyIdP(t)
+	idp.down.Store(true)
+
+	auth, err := middleware.NewOIDCAuthenticator(oidcConfig(idp, ""))
+	require.NoError(t, err)
+	auth.Discovery.MinBackoff = 5 * time.Millisecond
+
+	require.Error(t, auth.Discovery.Init(context.Background()))
+	assert.Equal(t, middleware.AuthStatePending, auth.Discovery.State().Status)
+
+	token, err := idp.Token(mockoidc.TokenRequest{RealmRoles: []string{"admin"}})
+	require.NoError(t, err)
+	_, err = auth.Authenticate(context.Background(), token)
+	assert.ErrorIs(t, err, middleware.ErrAuthUnavailable, "503 enquanto não há chaves, e não 500 para sempre")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go auth.Discovery.Run(ctx)
+
+	idp.down.Store(false)
+	require.Eventually(t, auth.Discovery.Ready, 2*time.Second, 5*time.Millisecond)
+
+	state := auth.Discovery.State()
+	assert.Equal(t, middleware.AuthStateReady, state.Status)
+	assert.Greater(t, state.Attempts, 1)
+	assert.Empty(t, state.LastError)
+
+	user, err := auth.Authenticate(context.Background(), token)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"admin"}, user.Roles)
+}
+
+func TestDiscovery_WarmRestartFromCache(t *testing.T) {
+	idp := startFlakyIdP(t)
+	cacheFile := filepath.Join(t.TempDir(), "cache", "jwks.json")
+
+	// 1ª execução: descoberta ok, JWKS salvo em disco
+	first, err := middleware.NewAuthenticator(oidcConfig(idp, cacheFile))
+	require.NoError(t, err)
+	assert.Equal(t, middleware.AuthStateReady, first.Discovery.State().Status)
+	_, err = os.Stat(cacheFile)
+	require.NoError(t, err)
+
+	// Restart com o provedor fora: valida com o cache
+	idp.down.Store(true)
+	second, err := middleware.NewOIDCAuthenticator(oidcConfig(idp, cacheFile))
+	require.NoError(t, err)
+	require.Error(t, second.Discovery.Init(context.Background()))
+	assert.Equal(t, middleware.AuthStateCached, second.Discovery.State().Status)
+	assert.True(t, second.Discovery.Ready())
+
+	token, err := idp.Token(mockoidc.TokenRequest{ClientRoles: []string{"develop"}})
+	require.NoError(t, err)
+	user, err := second.Authenticate(context.Background(), token)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"develop"}, user.Roles)
+}
+
+func TestNewAuthenticator_StrictFailsWhenDown(t *testing.T) {
+	idp := startFlakyIdP(t)
+	idp.down.Store(true)
+
+	_, err := middleware.NewAuthenticator(oidcConfig(idp, ""))
+	assert.Error(t, err)
+}
@@ -0,0 +1,267 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// IdempotencyHeader é o header enviado pelo cliente para identificar a operação.
+const IdempotencyHeader = "Idempotency-Key"
+
+// maxIdempotencyKeyLength limita o tamanho da chave aceita.
+const maxIdempotencyKeyLength = 255
+
+// IdempotencyRecord guarda o fingerprint da requisição original e, quando
+// concluída, a resposta completa que será repetida nos retries.
+type IdempotencyRecord struct {
+	Fingerprint string
+	Completed   bool
+	Status      int
+	Header      http.Header
+	Body        []byte
+	ExpiresAt   time.Time
+}
+
+// IdempotencyStore persiste as chaves de idempotência.
+// A implementação em memória serve para uma instância; para várias réplicas,
+// implemente a interface sobre um store compartilhado (ex: Redis, Postgres).
+type IdempotencyStore interface {
+	// Reserve grava a chave como "em andamento" se ela ainda não existir (ou expirou).
+	// Se já existir, devolve o registro atual e reserved=false.
+	Reserve(ctx context.Context, key, fingerprint string, ttl time.Duration) (rec *IdempotencyRecord, reserved bool, err error)
+
+	// Complete grava a resposta final da chave reservada.
+	Complete(ctx context.Context, key string, rec *IdempotencyRecord) error
+
+	// Release remove a reserva (ex: a requisição falhou com 5xx e pode ser tentada de novo).
+	Release(ctx context.Context, key string) error
+}
+
+// MemoryIdempotencyStore é um IdempotencyStore em memória, seguro para uso concorrente.
+type MemoryIdempotencyStore struct {
+	mu        sync.Mutex
+	records   map[string]*IdempotencyRecord
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// NewMemoryIdempotencyStore cria um store em memória vazio.
+func NewMemoryIdempotencyStore() *MemoryIdempotencyStore {
+	return &MemoryIdempotencyStore{
+		records: make(map[string]*IdempotencyRecord),
+		now:     time.Now,
+	}
+}
+
+func (s *MemoryIdempotencyStore) Reserve(_ context.Context, key, fingerprint string, ttl time.Duration) (*IdempotencyRecord, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.sweep(now)
+
+	if rec, ok := s.records[key]; ok && now.Before(rec.ExpiresAt) {
+		copied := *rec
+		return &copied, false, nil
+	}
+
+	s.records[key] = &IdempotencyRecord{Fingerprint: fingerprint, ExpiresAt: now.Add(ttl)}
+	return nil, true, nil
+}
+
+func (s *MemoryIdempotencyStore) Complete(_ context.Context, key string, rec *IdempotencyRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if current, ok := s.records[key]; ok {
+		rec.ExpiresAt = current.ExpiresAt
+	}
+	s.records[key] = rec
+	return nil
+}
+
+func (s *MemoryIdempotencyStore) Release(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.records, key)
+	return nil
+}
+
+// sweep remove chaves expiradas no máximo uma vez por minuto (chamado com o lock).
+func (s *MemoryIdempotencyStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < time.Minute {
+		return
+	}
+	s.lastSweep = now
+	for key, rec := range s.records {
+		if !now.Before(rec.ExpiresAt) {
+			delete(s.records, key)
+		}
+	}
+}
+
+// Idempotency cria um middleware que honra o header Idempotency-Key.
+//
+// Comportamento:
+//   - Sem o header: a requisição segue normalmente.
+//   - Primeira requisição com a chave: processa e guarda a resposta (exceto 5xx, que libera a chave).
+//   - Retry com o mesmo payload: devolve a resposta guardada (header Idempotent-Replayed: true).
+//   - Mesma chave com payload diferente: 422.
+//   - Mesma chave ainda em processamento: 409.
+//   - Body maior que maxBody bytes: 413 (o body é lido inteiro para o fingerprint).
+//
+// A chave é isolada por usuário, então o middleware deve vir depois do CheckMiddleware.
+func Idempotency(store IdempotencyStore, ttl time.Duration, maxBody int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyHeader)
+		if key == "" {
+			c.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Idempotency-Key inválida"})
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxBody))
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Body maior que o limite para Idempotency-Key"})
+				return
+			}
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Erro ao ler body"})
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		ctx := c.Request.Context()
+		storeKey := idempotencyScope(c) + ":" + key
+		fingerprint := requestFingerprint(c.Request.Method, c.Request.URL.Path, body)
+
+		rec, reserved, err := store.Reserve(ctx, storeKey, fingerprint, ttl)
+		if err != nil {
+			slog.ErrorContext(ctx, "Erro no store de idempotência", "error", err)
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Erro ao processar Idempotency-Key"})
+			return
+		}
+
+		if !reserved {
+			switch {
+			case rec.Fingerprint != fingerprint:
+				c.AbortWithStatusJSON(http.StatusUnprocessableEntity, gin.H{"error": "Idempotency-Key já usada com outro payload"})
+			case !rec.Completed:
+				c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": "Requisição com esta Idempotency-Key ainda em processamento"})
+			default:
+				slog.InfoContext(ctx, "Repetindo resposta idempotente", "idempotency_key", key)
+				replayResponse(c, rec)
+			}
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+
+		completed := false
+		defer func() {
+			// Panic ou erro: libera a chave para o cliente poder tentar de novo
+			if !completed {
+				_ = store.Release(ctx, storeKey)
+			}
+		}()
+
+		c.Next()
+
+		status := recorder.Status()
+		if status >= http.StatusInternalServerError {
+			return
+		}
+
+		err = store.Complete(ctx, storeKey, &IdempotencyRecord{
+			Fingerprint: fingerprint,
+			Completed:   true,
+			Status:      status,
+			Header:      recorder.Header().Clone(),
+			Body:        recorder.body.Bytes(),
+		})
+		if err != nil {
+			slog.ErrorContext(ctx, "Erro ao gravar resposta idempotente", "error", err)
+			return
+		}
+		completed = true
+	}
+}
+
+// idempotencyScope isola as chaves por tenant e usuário autenticado (o mesmo sub
+// pode existir em realms diferentes)
+func idempotencyScope(c *gin.Context) string {
+	if user := GetUser(c); user != nil {
+		return user.Tenant + "\x00" + user.ID
+	}
+	if id := c.GetString("user_id"); id != "" {
+		return id
+	}
+	return "anonymous"
+}
+
+func requestFingerprint(method, path string, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(method))
+	h.Write([]byte{0})
+	h.Write([]byte(path))
+	h.Write([]byte{0})
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// requestScopedHeaders descrevem a requisição atual (trace, rate limit, data) e não
+// são repetidos no replay: os middlewares anteriores já os definiram para ela.
+var requestScopedHeaders = []string{
+	"X-Trace-Id", "Traceparent", "Tracestate",
+	"Ratelimit-Limit", "Ratelimit-Remaining", "Ratelimit-Reset", "Retry-After",
+	"Date",
+}
+
+func replayResponse(c *gin.Context, rec *IdempotencyRecord) {
+	for k, values := range rec.Header {
+		// Headers de trace e rate limit devem ser os da requisição atual, não os da original
+		if slices.Contains(requestScopedHeaders, k) {
+			continue
+		}
+		for _, v := range values {
+			c.Writer.Header().Add(k, v)
+		}
+	}
+	c.Writer.Header().Set("Idempotent-Replayed", "true")
+	c.Writer.WriteHeader(rec.Status)
+	_, _ = c.Writer.Write(rec.Body)
+	c.Abort()
+}
+
+// responseRecorder copia o body escrito pelo handler para poder guardá-lo
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,146 @@
+package middleware_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go-api-first-steps/internal/config"
+	"go-api-first-steps/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// setupIdempotencyRouter registra um POST que conta quantas vezes o handler rodou.
+// O handler espera em release (se não for nil) para simular requisições em andamento.
+func setupIdempotencyRouter(store middleware.IdempotencyStore, calls *atomic.Int32, release chan struct{}) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/products", middleware.Idempotency(store, time.Hour, 64), func(c *gin.Context) {
+		n := calls.Add(1)
+		if release != nil {
+			<-release
+		}
+		c.JSON(http.StatusCreated, gin.H{"call": n})
+	})
+	return r
+}
+
+func postWithKey(r http.Handler, key, body string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest("POST", "/products", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	if key != "" {
+		req.Header.Set(middleware.IdempotencyHeader, key)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestIdempotency_ReplaysStoredResponse(t *testing.T) {
+	var calls atomic.Int32
+	r := setupIdempotencyRouter(middleware.NewMemoryIdempotencyStore(), &calls, nil)
+
+	first := postWithKey(r, "key-1", `{"name":"Mouse"}`)
+	retry := postWithKey(r, "key-1", `{"name":"Mouse"}`)
+
+	assert.Equal(t, http.StatusCreated, first.Code)
+	assert.Equal(t, http.StatusCreated, retry.Code)
+	assert.Equal(t, first.Body.String(), retry.Body.String())
+	assert.Equal(t, "true", retry.Header().Get("Idempotent-Replayed"))
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestIdempotency_ReplayKeepsCurrentRateLimitHeaders(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	limiter := middleware.NewRateLimiter(middleware.NewMemoryRateLimitStore(), config.RateLimitConfig{
+		Enabled: true,
+		Default: config.RateLimitRule{RPS: 0.01, Burst: 5},
+	})
+	var calls atomic.Int32
+	r := gin.New()
+	r.POST("/products", limiter.Middleware("products"), middleware.Idempotency(middleware.NewMemoryIdempotencyStore(), time.Hour, 64), func(c *gin.Context) {
+		calls.Add(1)
+		c.JSON(http.StatusCreated, gin.H{"ok": true})
+	})
+
+	first := postWithKey(r, "key-1", `{"name":"Mouse"}`)
+	retry := postWithKey(r, "key-1", `{"name":"Mouse"}`)
+
+	assert.Equal(t, "true", retry.Header().Get("Idempotent-Replayed"))
+	assert.Equal(t, []string{"4"}, first.Header().Values("RateLimit-Remaining"))
+	assert.Equal(t, []string{"3"}, retry.Header().Values("RateLimit-Remaining"), "só o valor desta requisição, sem o guardado")
+	assert.Len(t, retry.Header().Values("RateLimit-Limit"), 1)
+	assert.Len(t, retry.Header().Values("RateLimit-Reset"), 1)
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestIdempotency_BodyTooLarge(t *testing.T) {
+	var calls atomic.Int32
+	r := setupIdempotencyRouter(middleware.NewMemoryIdempotencyStore(), &calls, nil)
+
+	w := postWithKey(r, "key-grande", `{"name":"`+strings.Repeat("x", 64)+`"}`)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	assert.Equal(t, int32(0), calls.Load())
+
+	// A chave não foi reservada: o retry com body menor é processado
+	w = postWithKey(r, "key-grande", `{"name":"Mouse"}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+}
+
+func TestIdempotency_DifferentPayloadIsRejected(t *testing.T) {
+	var calls atomic.Int32
+	r := setupIdempotencyRouter(middleware.NewMemoryIdempotencyStore(), &calls, nil)
+
+	postWithKey(r, "key-1", `{"name":"Mouse"}`)
+	w := postWithKey(r, "key-1", `{"name":"Teclado"}`)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestIdempotency_ConcurrentDuplicateIsConflict(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	r := setupIdempotencyRouter(middleware.NewMemoryIdempotencyStore(), &calls, release)
+
+	done := make(chan *httptest.ResponseRecorder)
+	go func() { done <- postWithKey(r, "key-1", `{"name":"Mouse"}`) }()
+
+	// Espera a primeira requisição entrar no handler
+	assert.Eventually(t, func() bool { return calls.Load() == 1 }, time.Second, time.Millisecond)
+
+	w := postWithKey(r, "key-1", `{"name":"Mouse"}`)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	close(release)
+	assert.Equal(t, http.StatusCreated, (<-done).Code)
+}
+
+func TestIdempotency_WithoutKeyIsNotDeduplicated(t *testing.T) {
+	var calls atomic.Int32
+	r := setupIdempotencyRouter(middleware.NewMemoryIdempotencyStore(), &calls, nil)
+
+	postWithKey(r, "", `{"name":"Mouse"}`)
+	postWithKey(r, "", `{"name":"Mouse"}`)
+
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestMemoryIdempotencyStore_ExpiresKeys(t *testing.T) {
+	store := middleware.NewMemoryIdempotencyStore()
+	ctx := t.Context()
+
+	_, reserved, _ := store.Reserve(ctx, "k", "fp", time.Millisecond)
+	assert.True(t, reserved)
+
+	time.Sleep(5 * time.Millisecond)
+
+	_, reserved, _ = store.Reserve(ctx, "k", "fp", time.Hour)
+	assert.True(t, reserved, "chave expirada deveria poder ser reservada de novo")
+}
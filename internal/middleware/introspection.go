@@ -0,0 +1,216 @@
+package middleware
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"go-api-first-steps/internal/tracing"
+)
+
+// Introspector consulta o endpoint de introspecção do provedor (RFC 7662) para saber
+// se um token ainda está ativo (logout, usuário desabilitado) e para validar tokens
+// opacos. As respostas ficam em cache por até CacheTTL (nunca além do exp do token).
+type Introspector struct {
+	Endpoint     string // Ex: {KEYCLOAK_URL}/protocol/openid-connect/token/introspect
+	ClientID     string
+	ClientSecret string
+	CacheTTL     time.Duration
+	HTTPClient   *http.Client
+	Now          func() time.Time // Relógio (substituível em testes)
+
+	mu    sync.Mutex
+	cache map[string]introspectionEntry
+}
+
+type introspectionEntry struct {
+	claims  map[string]any // nil = token inativo
+	expires time.Time
+}
+
+// NewIntrospector cria um Introspector com cliente HTTP com timeout.
+func NewIntrospector(endpoint, clientID, clientSecret string, cacheTTL time.Duration) *Introspector {
+	return &Introspector{
+		Endpoint:     endpoint,
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		CacheTTL:     cacheTTL,
+		HTTPClient:   &http.Client{Timeout: 5 * time.Second, Transport: tracing.Transport(nil)},
+		Now:          time.Now,
+		cache:        make(map[string]introspectionEntry),
+	}
+}
+
+// Introspect devolve as claims de um token ativo, ou ErrInvalidToken se o provedor
+// o considera inativo. Falhas de rede/HTTP são devolvidas como erro comum (500).
+func (i *Introspector) Introspect(ctx context.Context, token string) (map[string]any, error) {
+	sum := sha256.Sum256([]byte(token))
+	key := hex.EncodeToString(sum[:])
+	now := i.Now()
+
+	i.mu.Lock()
+	entry, ok := i.cache[key]
+	i.mu.Unlock()
+	if ok && now.Before(entry.expires) {
+		return entry.activeClaims()
+	}
+
+	claims, err := i.request(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	entry = introspectionEntry{claims: claims, expires: now.Add(i.CacheTTL)}
+	if exp, ok := numericClaim(claims, "exp"); ok && time.Unix(exp, 0).Before(entry.expires) {
+		entry.expires = time.Unix(exp, 0)
+	}
+
+	i.mu.Lock()
+	i.sweep(now)
+	i.cache[key] = entry
+	i.mu.Unlock()
+
+	return entry.activeClaims()
+}
+
+func (e introspectionEntry) activeClaims() (map[string]any, error) {
+	if e.claims == nil {
+		return nil, fmt.Errorf("%w: token inativo", ErrInvalidToken)
+	}
+	return e.claims, nil
+}
+
+func (i *Introspector) request(ctx context.Context, token string) (map[string]any, error) {
+	form := url.Values{"token": {token}, "token_type_hint": {"access_token"}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, i.Endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(i.ClientID), url.QueryEscape(i.ClientSecret))
+
+	resp, err := i.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("falha na introspecção: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("introspecção retornou status %d", resp.StatusCode)
+	}
+
+	var claims map[string]any
+	if err := json.NewDecoder(resp.Body).Decode(&claims); err != nil {
+		return nil, fmt.Errorf("resposta de introspecção inválida: %w", err)
+	}
+	if active, _ := claims["active"].(bool); !active {
+		return nil, nil
+	}
+	return claims, nil
+}
+
+// checkIntrospected dá ao token introspectado as garantias que o verifier dá ao JWT:
+// o provedor responde active=true também para refresh/ID tokens e para tokens de
+// outros clients do realm. token_type (RFC 7662) e typ (Keycloak), se presentes,
+// precisam indicar access token; aud ou client_id precisam ser o client desta API.
+func (a *Authenticator) checkIntrospected(claims map[string]any) error {
+	for _, name := range []string{"token_type", "typ"} {
+		if t := stringClaim(claims, name); t != "" && !strings.EqualFold(t, "bearer") && !strings.EqualFold(t, "access_token") {
+			return fmt.Errorf("%w: %s %q não é um access token", ErrInvalidToken, name, t)
+		}
+	}
+
+	audience := a.Audience
+	if audience == "" {
+		audience = a.ClientID
+	}
+	if slices.Contains(toStrings(claims["aud"], false), audience) || stringClaim(claims, "client_id") == audience {
+		return nil
+	}
+	return fmt.Errorf("%w: token emitido para outro client (esperado %q)", ErrInvalidToken, audience)
+}
+
+// sweep remove entradas expiradas (chamado com mu travado)
+func (i *Introspector) sweep(now time.Time) {
+	for k, e := range i.cache {
+		if !now.Before(e.expires) {
+			delete(i.cache, k)
+		}
+	}
+}
+
+// RevocationList guarda tokens (jti) e sessões (sid) revogados antes do exp,
+// ex: após um logout no Keycloak. As entradas expiram junto com os tokens.
+type RevocationList interface {
+	Revoke(kind, id string, until time.Time)
+	IsRevoked(jti, sid string) bool
+}
+
+// Tipos de entrada da RevocationList
+const (
+	RevokeJTI = "jti"
+	RevokeSID = "sid"
+)
+
+// MemoryRevocationList é a RevocationList em memória (uma instância da API).
+type MemoryRevocationList struct {
+	mu      sync.RWMutex
+	entries map[string]time.Time
+	now     func() time.Time
+}
+
+// NewMemoryRevocationList cria uma lista de revogação vazia.
+func NewMemoryRevocationList() *MemoryRevocationList {
+	return &MemoryRevocationList{entries: make(map[string]time.Time), now: time.Now}
+}
+
+// Revoke marca o jti ou sid como revogado até until (normalmente o exp do token).
+func (l *MemoryRevocationList) Revoke(kind, id string, until time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	for k, exp := range l.entries {
+		if !now.Before(exp) {
+			delete(l.entries, k)
+		}
+	}
+	l.entries[kind+":"+id] = until
+}
+
+// IsRevoked indica se o token (jti) ou a sessão (sid) foram revogados.
+func (l *MemoryRevocationList) IsRevoked(jti, sid string) bool {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	now := l.now()
+	for _, key := range []string{RevokeJTI + ":" + jti, RevokeSID + ":" + sid} {
+		if strings.HasSuffix(key, ":") {
+			continue
+		}
+		if until, ok := l.entries[key]; ok && now.Before(until) {
+			return true
+		}
+	}
+	return false
+}
+
+func numericClaim(claims map[string]any, name string) (int64, bool) {
+	switch v := claims[name].(type) {
+	case float64:
+		return int64(v), true
+	case json.Number:
+		n, err := v.Int64()
+		return n, err == nil
+	}
+	return 0, false
+}
@@ -0,0 +1,173 @@
+package middleware_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go-api-first-steps/internal/middleware"
+	"go-api-first-steps/internal/mockoidc"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newIntrospector(idp *mockoidc.TestServer, ttl time.Duration) *middleware.Introspector {
+	return middleware.NewIntrospector(
+		idp.Issuer()+"/protocol/openid-connect/token/introspect", mockoidc.DefaultClientID, "secret", ttl)
+}
+
+func TestIntrospection_OpaqueToken(t *testing.T) {
+	idp := mockoidc.Start(t)
+	introspector := newIntrospector(idp, time.Minute)
+	now := time.Now()
+	introspector.Now = func() time.Time { return now }
+
+	auth := &middleware.Authenticator{ClientID: mockoidc.DefaultClientID, Introspector: introspector}
+	ctx := context.Background()
+
+	token := idp.MustToken(t, mockoidc.TokenRequest{Subject: "ana", ClientRoles: []string{"develop"}, Opaque: true})
+	user, err := auth.Authenticate(ctx, token)
+	require.NoError(t, err)
+	assert.Equal(t, "ana", user.ID)
+	assert.Equal(t, mockoidc.DefaultClientID, user.ClientID)
+	assert.Equal(t, []string{"develop"}, user.Roles)
+
+	// Logout no provedor: o cache ainda responde até o TTL
+	idp.RevokeToken(token)
+	_, err = auth.Authenticate(ctx, token)
+	assert.NoError(t, err)
+
+	now = now.Add(2 * time.Minute)
+	_, err = auth.Authenticate(ctx, token)
+	assert.ErrorIs(t, err, middleware.ErrInvalidToken)
+
+	_, err = auth.Authenticate(ctx, "token-que-nao-existe")
+	assert.ErrorIs(t, err, middleware.ErrInvalidToken)
+
+	// Sem Verifier, JWTs continuam exigindo validação local
+	jwtToken := idp.MustToken(t, mockoidc.TokenRequest{})
+	_, err = auth.Authenticate(ctx, jwtToken)
+	assert.ErrorIs(t, err, middleware.ErrAuthNotConfigured)
+}
+
+func TestIntrospection_AllTokensDetectLogout(t *testing.T) {
+	idp := mockoidc.Start(t)
+	provider, err := oidc.NewProvider(context.Background(), idp.Issuer())
+	require.NoError(t, err)
+
+	auth := &middleware.Authenticator{
+		Verifier:      provider.Verifier(&oidc.Config{ClientID: mockoidc.DefaultClientID}),
+		ClientID:      mockoidc.DefaultClientID,
+		Introspector:  newIntrospector(idp, 0),
+		IntrospectAll: true,
+	}
+
+	token := idp.MustToken(t, mockoidc.TokenRequest{SessionID: "sessao-1", RealmRoles: []string{"admin"}})
+	_, err = auth.Authenticate(context.Background(), token)
+	require.NoError(t, err)
+
+	idp.EndSession("sessao-1")
+	_, err = auth.Authenticate(context.Background(), token)
+	assert.ErrorIs(t, err, middleware.ErrInvalidToken)
+}
+
+func TestIntrospection_CacheAndFailures(t *testing.T) {
+	var calls atomic.Int32
+	status := http.StatusOK
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		user, pass, _ := r.BasicAuth()
+		assert.Equal(t, "api", user)
+		assert.Equal(t, "s3cr3t", pass)
+		w.WriteHeader(status)
+		_, _ = fmt.Fprintf(w, `{"active":true,"sub":"svc","exp":%d}`, time.Now().Add(time.Hour).Unix())
+	}))
+	t.Cleanup(srv.Close)
+
+	introspector := middleware.NewIntrospector(srv.URL, "api", "s3cr3t", time.Minute)
+	ctx := context.Background()
+
+	for range 3 {
+		claims, err := introspector.Introspect(ctx, "opaco")
+		require.NoError(t, err)
+		assert.Equal(t, "svc", claims["sub"])
+	}
+	assert.Equal(t, int32(1), calls.Load(), "respostas ficam em cache")
+
+	// Falha do provedor não é "token inválido" (vira 500, não 401)
+	status = http.StatusBadGateway
+	_, err := introspector.Introspect(ctx, "outro")
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, middleware.ErrInvalidToken)
+}
+
+func TestIntrospection_OnlyAccessTokensForThisClient(t *testing.T) {
+	// O provedor responde active=true para qualquer token do realm; quem filtra é a API
+	responses := map[string]string{
+		"access":       `{"active":true,"sub":"ana","token_type":"Bearer","aud":["account","api"]}`,
+		"client-id":    `{"active":true,"sub":"svc","client_id":"api"}`,
+		"refresh":      `{"active":true,"sub":"ana","token_type":"refresh_token","aud":"api"}`,
+		"keycloak-id":  `{"active":true,"sub":"ana","typ":"ID","aud":"api"}`,
+		"outro-client": `{"active":true,"sub":"ana","token_type":"Bearer","aud":"outro","client_id":"outro"}`,
+		"sem-aud":      `{"active":true,"sub":"ana"}`,
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, responses[r.FormValue("token")])
+	}))
+	t.Cleanup(srv.Close)
+
+	auth := &middleware.Authenticator{ClientID: "api", Introspector: middleware.NewIntrospector(srv.URL, "api", "s3cr3t", 0)}
+	ctx := context.Background()
+
+	for _, token := range []string{"access", "client-id"} {
+		_, err := auth.Authenticate(ctx, token)
+		assert.NoError(t, err, token)
+	}
+	for _, token := range []string{"refresh", "keycloak-id", "outro-client", "sem-aud"} {
+		_, err := auth.Authenticate(ctx, token)
+		assert.ErrorIs(t, err, middleware.ErrInvalidToken, token)
+	}
+
+	// Audience configurado explicitamente (ex: IssuerConfig.Audience) vale no lugar do ClientID
+	auth.Audience = "outro"
+	_, err := auth.Authenticate(ctx, "outro-client")
+	assert.NoError(t, err)
+}
+
+func TestRevocationList(t *testing.T) {
+	idp := mockoidc.Start(t)
+	provider, err := oidc.NewProvider(context.Background(), idp.Issuer())
+	require.NoError(t, err)
+
+	revocations := middleware.NewMemoryRevocationList()
+	auth := &middleware.Authenticator{
+		Verifier:    provider.Verifier(&oidc.Config{ClientID: mockoidc.DefaultClientID}),
+		ClientID:    mockoidc.DefaultClientID,
+		Revocations: revocations,
+	}
+	ctx := context.Background()
+
+	byJTI := idp.MustToken(t, mockoidc.TokenRequest{Extra: map[string]any{"jti": "token-1"}})
+	bySID := idp.MustToken(t, mockoidc.TokenRequest{SessionID: "sessao-2"})
+	other := idp.MustToken(t, mockoidc.TokenRequest{})
+
+	revocations.Revoke(middleware.RevokeJTI, "token-1", time.Now().Add(time.Hour))
+	revocations.Revoke(middleware.RevokeSID, "sessao-2", time.Now().Add(time.Hour))
+	revocations.Revoke(middleware.RevokeJTI, "ja-expirou", time.Now().Add(-time.Second))
+
+	_, err = auth.Authenticate(ctx, byJTI)
+	assert.ErrorIs(t, err, middleware.ErrInvalidToken)
+	_, err = auth.Authenticate(ctx, bySID)
+	assert.ErrorIs(t, err, middleware.ErrInvalidToken)
+	_, err = auth.Authenticate(ctx, other)
+	assert.NoError(t, err)
+
+	assert.False(t, revocations.IsRevoked("ja-expirou", ""))
+	assert.False(t, revocations.IsRevoked("", ""))
+}
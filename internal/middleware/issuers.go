@@ -0,0 +1,127 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// IssuerConfig descreve um emissor confiável (ex: um realm do Keycloak por unidade de negócio).
+type IssuerConfig struct {
+	Tenant      string `yaml:"tenant" json:"tenant"`
+	Issuer      string `yaml:"issuer" json:"issuer"`
+	ClientID    string `yaml:"client_id" json:"client_id"`       // Usado nas client roles (resource_access)
+	Audience    string `yaml:"audience" json:"audience"`         // aud esperado; default: ClientID
+	RoleSources string `yaml:"role_sources" json:"role_sources"` // Default: client
+	RoleMerge   string `yaml:"role_merge" json:"role_merge"`     // Default: union
+}
+
+// LoadIssuers lê o arquivo de emissores (YAML ou JSON):
+//
+//	issuers:
+//	  - tenant: varejo
+//	    issuer: https://sso.exemplo.com/realms/varejo
+//	    client_id: product-api
+//	  - tenant: atacado
+//	    issuer: https://sso.exemplo.com/realms/atacado
+//	    client_id: product-api-atacado
+//	    role_sources: realm
+func LoadIssuers(path string) ([]IssuerConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao ler emissores: %w", err)
+	}
+
+	var file struct {
+		Issuers []IssuerConfig `yaml:"issuers" json:"issuers"`
+	}
+	// YAML é superconjunto de JSON, então o mesmo parser atende os dois formatos
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("arquivo de emissores inválido: %w", err)
+	}
+	if len(file.Issuers) == 0 {
+		return nil, errors.New("arquivo de emissores sem nenhum emissor")
+	}
+
+	tenants := make(map[string]bool)
+	issuers := make(map[string]bool)
+	for i := range file.Issuers {
+		ic := &file.Issuers[i]
+		if ic.Tenant == "" || ic.Issuer == "" || ic.ClientID == "" {
+			return nil, fmt.Errorf("emissor #%d: tenant, issuer e client_id são obrigatórios", i+1)
+		}
+		if tenants[ic.Tenant] || issuers[ic.Issuer] {
+			return nil, fmt.Errorf("emissor #%d: tenant ou issuer duplicado", i+1)
+		}
+		tenants[ic.Tenant], issuers[ic.Issuer] = true, true
+
+		if ic.Audience == "" {
+			ic.Audience = ic.ClientID
+		}
+		if ic.RoleSources == "" {
+			ic.RoleSources = "client"
+		}
+	}
+	return file.Issuers, nil
+}
+
+// NewMultiIssuerAuthenticator cria um Authenticator que aceita tokens de vários emissores.
+// Cada emissor tem sua própria OIDCDiscovery (ainda não executada): use InitDiscoveries
+// e Run em cada item de Discoveries. Com cacheFile, cada emissor guarda o JWKS em
+// "<cacheFile sem extensão>.<tenant><extensão>".
+func NewMultiIssuerAuthenticator(issuers []IssuerConfig, cacheFile string, retryMax time.Duration) (*Authenticator, error) {
+	auth := &Authenticator{Tenants: make(map[string]*Authenticator, len(issuers))}
+
+	for _, ic := range issuers {
+		roles, err := ParseRoleMapping(ic.RoleSources, ic.RoleMerge, ic.ClientID)
+		if err != nil {
+			return nil, fmt.Errorf("emissor %s: mapeamento de roles inválido: %w", ic.Tenant, err)
+		}
+
+		tenant := &Authenticator{ClientID: ic.ClientID, Audience: ic.Audience, Roles: roles, Tenant: ic.Tenant}
+		NewOIDCDiscovery(tenant, ic.Issuer, ic.Audience, tenantCacheFile(cacheFile, ic.Tenant), retryMax)
+		auth.Tenants[ic.Issuer] = tenant
+	}
+	return auth, nil
+}
+
+// Discoveries devolve as descobertas OIDC deste Authenticator e de seus emissores.
+func (a *Authenticator) Discoveries() []*OIDCDiscovery {
+	var out []*OIDCDiscovery
+	if a.Discovery != nil {
+		out = append(out, a.Discovery)
+	}
+	for _, tenant := range a.Tenants {
+		if tenant.Discovery != nil {
+			out = append(out, tenant.Discovery)
+		}
+	}
+	slices.SortFunc(out, func(x, y *OIDCDiscovery) int { return strings.Compare(x.Issuer, y.Issuer) })
+	return out
+}
+
+// InitDiscoveries faz a primeira tentativa de todas as descobertas e devolve o primeiro erro.
+func (a *Authenticator) InitDiscoveries(ctx context.Context) error {
+	var errs []error
+	for _, d := range a.Discoveries() {
+		if err := d.Init(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func tenantCacheFile(cacheFile, tenant string) string {
+	if cacheFile == "" {
+		return ""
+	}
+	ext := filepath.Ext(cacheFile)
+	return strings.TrimSuffix(cacheFile, ext) + "." + tenant + ext
+}
@@ -0,0 +1,153 @@
+package middleware_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go-api-first-steps/internal/middleware"
+	"go-api-first-steps/internal/mockoidc"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadIssuers(t *testing.T) {
+	write := func(content string) string {
+		path := filepath.Join(t.TempDir(), "issuers.yaml")
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+		return path
+	}
+
+	issuers, err := middleware.LoadIssuers(write(`
+issuers:
+  - tenant: varejo
+    issuer: https://sso.test/realms/varejo
+    client_id: product-api
+  - tenant: atacado
+    issuer: https://sso.test/realms/atacado
+    client_id: product-api-atacado
+    audience: atacado-api
+    role_sources: realm
+`))
+	require.NoError(t, err)
+	require.Len(t, issuers, 2)
+	assert.Equal(t, "product-api", issuers[0].Audience, "audience padrão é o client_id")
+	assert.Equal(t, "client", issuers[0].RoleSources, "só client roles, como sem ISSUERS_FILE")
+	assert.Equal(t, "atacado-api", issuers[1].Audience)
+
+	// JSON também é aceito
+	_, err = middleware.LoadIssuers(write(`{"issuers":[{"tenant":"a","issuer":"https://a","client_id":"x"}]}`))
+	assert.NoError(t, err)
+
+	_, err = middleware.LoadIssuers(write("issuers: []"))
+	assert.Error(t, err)
+
+	_, err = middleware.LoadIssuers(write(`
+issuers:
+  - tenant: a
+    issuer: https://sso.test/realms/a
+`))
+	assert.Error(t, err, "client_id obrigatório")
+
+	_, err = middleware.LoadIssuers(write(`
+issuers:
+  - {tenant: a, issuer: https://sso.test/realms/a, client_id: x}
+  - {tenant: b, issuer: https://sso.test/realms/a, client_id: y}
+`))
+	assert.Error(t, err, "issuer duplicado")
+}
+
+func TestMultiIssuer_RoutesByIssuer(t *testing.T) {
+	varejo := mockoidc.Start(t)
+	atacado := mockoidc.Start(t)
+
+	auth, err := middleware.NewMultiIssuerAuthenticator([]middleware.IssuerConfig{
+		{Tenant: "varejo", Issuer: varejo.Issuer(), ClientID: mockoidc.DefaultClientID, Audience: mockoidc.DefaultClientID, RoleSources: "client"},
+		{Tenant: "atacado", Issuer: atacado.Issuer(), ClientID: "atacado-api", Audience: "atacado-api", RoleSources: "realm"},
+	}, "", time.Second)
+	require.NoError(t, err)
+	require.NoError(t, auth.InitDiscoveries(context.Background()))
+	assert.Len(t, auth.Discoveries(), 2)
+
+	// Varejo: só client roles contam
+	user, err := auth.Authenticate(context.Background(), varejo.MustToken(t, mockoidc.TokenRequest{
+		Subject: "ana", ClientRoles: []string{"develop"}, RealmRoles: []string{"admin"},
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, "varejo", user.Tenant)
+	assert.Equal(t, []string{"develop"}, user.Roles)
+
+	// Atacado: audiência e roles próprias
+	user, err = auth.Authenticate(context.Background(), atacado.MustToken(t, mockoidc.TokenRequest{
+		Subject: "bia", ClientID: "atacado-api", RealmRoles: []string{"admin"},
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, "atacado", user.Tenant)
+	assert.Equal(t, []string{"admin"}, user.Roles)
+
+	// Token do atacado com a audiência do varejo não passa
+	_, err = auth.Authenticate(context.Background(), atacado.MustToken(t, mockoidc.TokenRequest{
+		ClientID: mockoidc.DefaultClientID, RealmRoles: []string{"admin"},
+	}))
+	assert.ErrorIs(t, err, middleware.ErrInvalidToken)
+}
+
+func TestMultiIssuer_RejectsUnknownIssuerAndForeignKey(t *testing.T) {
+	varejo := mockoidc.Start(t)
+	outro := mockoidc.Start(t)
+
+	auth, err := middleware.NewMultiIssuerAuthenticator([]middleware.IssuerConfig{
+		{Tenant: "varejo", Issuer: varejo.Issuer(), ClientID: mockoidc.DefaultClientID, Audience: mockoidc.DefaultClientID, RoleSources: "client,realm"},
+	}, "", time.Second)
+	require.NoError(t, err)
+	require.NoError(t, auth.InitDiscoveries(context.Background()))
+
+	// Emissor fora da lista
+	_, err = auth.Authenticate(context.Background(), outro.MustToken(t, mockoidc.TokenRequest{RealmRoles: []string{"admin"}}))
+	assert.ErrorIs(t, err, middleware.ErrInvalidToken)
+
+	// iss do varejo, mas assinado por outra chave
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	forged, err := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss": varejo.Issuer(),
+		"aud": mockoidc.DefaultClientID,
+		"sub": "intruso",
+		"exp": time.Now().Add(time.Hour).Unix(),
+		"iat": time.Now().Unix(),
+	}).SignedString(key)
+	require.NoError(t, err)
+	_, err = auth.Authenticate(context.Background(), forged)
+	assert.ErrorIs(t, err, middleware.ErrInvalidToken)
+}
+
+// Em multi-issuer o tenant vem do emissor: a claim não escolhe outro tenant.
+func TestMultiIssuer_TenantClaimCannotOverrideIssuer(t *testing.T) {
+	varejo := mockoidc.Start(t)
+
+	auth, err := middleware.NewMultiIssuerAuthenticator([]middleware.IssuerConfig{
+		{Tenant: "varejo", Issuer: varejo.Issuer(), ClientID: mockoidc.DefaultClientID, Audience: mockoidc.DefaultClientID, RoleSources: "realm"},
+	}, "", time.Second)
+	require.NoError(t, err)
+	require.NoError(t, auth.SetTenantClaim("tenant"))
+	require.NoError(t, auth.InitDiscoveries(context.Background()))
+
+	_, err = auth.Authenticate(context.Background(), varejo.MustToken(t, mockoidc.TokenRequest{
+		RealmRoles: []string{"admin"}, Extra: map[string]any{"tenant": "atacado"},
+	}))
+	assert.ErrorIs(t, err, middleware.ErrInvalidToken)
+
+	for _, extra := range []map[string]any{{"tenant": "varejo"}, nil} {
+		user, err := auth.Authenticate(context.Background(), varejo.MustToken(t, mockoidc.TokenRequest{
+			RealmRoles: []string{"admin"}, Extra: extra,
+		}))
+		require.NoError(t, err)
+		assert.Equal(t, "varejo", user.Tenant)
+	}
+}
@@ -0,0 +1,288 @@
+package middleware
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+)
+
+// offlineSigningAlgs são os algoritmos aceitos na verificação offline
+var offlineSigningAlgs = []jose.SignatureAlgorithm{jose.RS256, jose.ES256, jose.EdDSA}
+
+// KeyFile é um arquivo PEM com a chave pública; KeyID (opcional) associa a chave a um "kid".
+type KeyFile struct {
+	KeyID string
+	Path  string
+}
+
+// FileKeySet implementa oidc.KeySet com chaves locais (arquivos PEM e/ou JWKS),
+// permitindo validar tokens sem acesso ao Keycloak (ambientes air-gapped, testes).
+//
+// Tokens com "kid" usam a chave de mesmo kid (rotação); tokens sem kid, ou com
+// kid desconhecido, são verificados contra as chaves sem kid (ex: PEM avulso).
+type FileKeySet struct {
+	pemFiles  []KeyFile
+	jwksFile  string
+	inlineKey crypto.PublicKey
+
+	mu      sync.RWMutex
+	byKid   map[string]crypto.PublicKey
+	noKid   []crypto.PublicKey
+	modTime map[string]time.Time
+}
+
+// NewFileKeySet carrega as chaves. inlineKey aceita a chave em base64 (DER, sem
+// cabeçalhos PEM) como impressa pelo cmd/mock_token em KEYCLOAK_PUBLIC_KEY.
+func NewFileKeySet(pemFiles []KeyFile, jwksFile, inlineKey string) (*FileKeySet, error) {
+	ks := &FileKeySet{pemFiles: pemFiles, jwksFile: jwksFile}
+
+	if inlineKey != "" {
+		key, err := parseInlineKey(inlineKey)
+		if err != nil {
+			return nil, err
+		}
+		ks.inlineKey = key
+	}
+
+	if err := ks.Reload(); err != nil {
+		return nil, err
+	}
+	return ks, nil
+}
+
+// ParseKeyFiles interpreta "kid=caminho.pem,outro.pem" (kid opcional).
+func ParseKeyFiles(s string) []KeyFile {
+	var files []KeyFile
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if kid, path, ok := strings.Cut(entry, "="); ok {
+			files = append(files, KeyFile{KeyID: kid, Path: path})
+		} else {
+			files = append(files, KeyFile{Path: entry})
+		}
+	}
+	return files
+}
+
+// Reload relê todos os arquivos. Se algum for inválido, as chaves atuais são mantidas.
+func (ks *FileKeySet) Reload() error {
+	byKid := make(map[string]crypto.PublicKey)
+	var noKid []crypto.PublicKey
+	modTime := make(map[string]time.Time)
+
+	if ks.inlineKey != nil {
+		noKid = append(noKid, ks.inlineKey)
+	}
+
+	for _, f := range ks.pemFiles {
+		key, mod, err := loadPEMKey(f.Path)
+		if err != nil {
+			return err
+		}
+		modTime[f.Path] = mod
+		if f.KeyID != "" {
+			byKid[f.KeyID] = key
+		} else {
+			noKid = append(noKid, key)
+		}
+	}
+
+	if ks.jwksFile != "" {
+		keys, mod, err := loadJWKS(ks.jwksFile)
+		if err != nil {
+			return err
+		}
+		modTime[ks.jwksFile] = mod
+		for _, k := range keys {
+			if k.KeyID != "" {
+				byKid[k.KeyID] = k.Key
+			} else {
+				noKid = append(noKid, k.Key)
+			}
+		}
+	}
+
+	if len(byKid) == 0 && len(noKid) == 0 {
+		return errors.New("nenhuma chave pública configurada para verificação offline")
+	}
+
+	ks.mu.Lock()
+	ks.byKid, ks.noKid, ks.modTime = byKid, noKid, modTime
+	ks.mu.Unlock()
+	return nil
+}
+
+// VerifySignature implementa oidc.KeySet. Issuer, audience e expiração são
+// validados pelo oidc.IDTokenVerifier que usa este KeySet.
+func (ks *FileKeySet) VerifySignature(_ context.Context, token string) ([]byte, error) {
+	jws, err := jose.ParseSigned(token, offlineSigningAlgs)
+	if err != nil {
+		return nil, fmt.Errorf("token malformado: %w", err)
+	}
+	if len(jws.Signatures) != 1 {
+		return nil, errors.New("token deve ter exatamente uma assinatura")
+	}
+	kid := jws.Signatures[0].Header.KeyID
+
+	ks.mu.RLock()
+	key, found := ks.byKid[kid]
+	candidates := ks.noKid
+	ks.mu.RUnlock()
+
+	if found {
+		return jws.Verify(key)
+	}
+	for _, k := range candidates {
+		if payload, err := jws.Verify(k); err == nil {
+			return payload, nil
+		}
+	}
+	if kid != "" {
+		return nil, fmt.Errorf("nenhuma chave válida para kid %q", kid)
+	}
+	return nil, errors.New("assinatura não confere com nenhuma chave configurada")
+}
+
+// Watch verifica os arquivos a cada interval e recarrega as chaves quando algum muda.
+// Roda até ctx ser cancelado; deve ser chamado em goroutine.
+func (ks *FileKeySet) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if !ks.changed() {
+				continue
+			}
+			if err := ks.Reload(); err != nil {
+				slog.ErrorContext(ctx, "Chaves JWT inválidas, mantendo as anteriores", "error", err)
+				continue
+			}
+			slog.InfoContext(ctx, "Chaves JWT recarregadas")
+		}
+	}
+}
+
+func (ks *FileKeySet) changed() bool {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	for path, mod := range ks.modTime {
+		info, err := os.Stat(path)
+		if err == nil && !info.ModTime().Equal(mod) {
+			return true
+		}
+	}
+	return false
+}
+
+func loadPEMKey(path string) (crypto.PublicKey, time.Time, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("falha ao ler chave %s: %w", path, err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("falha ao ler chave %s: %w", path, err)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, time.Time{}, fmt.Errorf("arquivo %s não contém PEM", path)
+	}
+
+	var key crypto.PublicKey
+	switch block.Type {
+	case "PUBLIC KEY":
+		key, err = x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		key, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	case "CERTIFICATE":
+		var cert *x509.Certificate
+		if cert, err = x509.ParseCertificate(block.Bytes); err == nil {
+			key = cert.PublicKey
+		}
+	default:
+		err = fmt.Errorf("tipo PEM não suportado %q", block.Type)
+	}
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("chave inválida em %s: %w", path, err)
+	}
+	if err := checkKeyType(key); err != nil {
+		return nil, time.Time{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return key, info.ModTime(), nil
+}
+
+func loadJWKS(path string) ([]jose.JSONWebKey, time.Time, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("falha ao ler JWKS %s: %w", path, err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("falha ao ler JWKS %s: %w", path, err)
+	}
+
+	var set jose.JSONWebKeySet
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, time.Time{}, fmt.Errorf("JWKS inválido em %s: %w", path, err)
+	}
+
+	keys := make([]jose.JSONWebKey, 0, len(set.Keys))
+	for _, k := range set.Keys {
+		// Ignora chaves de criptografia (use=enc); só interessam as de assinatura
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		if !k.IsPublic() {
+			k = k.Public()
+		}
+		if err := checkKeyType(k.Key); err != nil {
+			return nil, time.Time{}, fmt.Errorf("JWKS %s, kid %q: %w", path, k.KeyID, err)
+		}
+		keys = append(keys, k)
+	}
+	return keys, info.ModTime(), nil
+}
+
+func parseInlineKey(s string) (crypto.PublicKey, error) {
+	der, err := base64.StdEncoding.DecodeString(strings.TrimSpace(s))
+	if err != nil {
+		return nil, fmt.Errorf("KEYCLOAK_PUBLIC_KEY inválida: %w", err)
+	}
+	key, err := x509.ParsePKIXPublicKey(der)
+	if err != nil {
+		return nil, fmt.Errorf("KEYCLOAK_PUBLIC_KEY inválida: %w", err)
+	}
+	return key, checkKeyType(key)
+}
+
+func checkKeyType(key crypto.PublicKey) error {
+	switch key.(type) {
+	case *rsa.PublicKey, *ecdsa.PublicKey, ed25519.PublicKey:
+		return nil
+	}
+	return fmt.Errorf("tipo de chave não suportado %T (use RSA, ECDSA ou Ed25519)", key)
+}
@@ -0,0 +1,203 @@
+package middleware_test
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go-api-first-steps/internal/config"
+	"go-api-first-steps/internal/middleware"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testIssuer   = "http://keycloak.local/realms/test"
+	testAudience = "product-api"
+)
+
+func offlineConfig() *config.Config {
+	return &config.Config{
+		ClientID:    testAudience,
+		RoleSources: "client,realm",
+		RoleMerge:   "union",
+		JWTIssuer:   testIssuer,
+		JWTAudience: testAudience,
+		JWTLeeway:   30 * time.Second,
+	}
+}
+
+func writePEM(t *testing.T, dir, name string, pub crypto.PublicKey) string {
+	t.Helper()
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	require.NoError(t, err)
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0o600))
+	return path
+}
+
+func writeJWKS(t *testing.T, path string, keys ...jose.JSONWebKey) {
+	t.Helper()
+	data, err := json.Marshal(jose.JSONWebKeySet{Keys: keys})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+}
+
+func signToken(t *testing.T, method jwt.SigningMethod, key crypto.PrivateKey, kid string, claims jwt.MapClaims) string {
+	t.Helper()
+	base := jwt.MapClaims{
+		"iss":          testIssuer,
+		"aud":          testAudience,
+		"sub":          "user-1",
+		"exp":          time.Now().Add(time.Hour).Unix(),
+		"iat":          time.Now().Unix(),
+		"realm_access": map[string]any{"roles": []any{"develop"}},
+	}
+	for k, v := range claims {
+		base[k] = v
+	}
+	token := jwt.NewWithClaims(method, base)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	signed, err := token.SignedString(key)
+	require.NoError(t, err)
+	return signed
+}
+
+func TestOfflineAuthenticator_Algorithms(t *testing.T) {
+	dir := t.TempDir()
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	edPub, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	// RSA e EC via PEM (com e sem kid), Ed25519 via JWKS
+	jwksPath := filepath.Join(dir, "jwks.json")
+	writeJWKS(t, jwksPath, jose.JSONWebKey{Key: edPub, KeyID: "ed", Algorithm: "EdDSA", Use: "sig"})
+
+	keys := []middleware.KeyFile{
+		{Path: writePEM(t, dir, "rsa.pem", &rsaKey.PublicKey)},
+		{KeyID: "ec", Path: writePEM(t, dir, "ec.pem", &ecKey.PublicKey)},
+	}
+	ks, err := middleware.NewFileKeySet(keys, jwksPath, "")
+	require.NoError(t, err)
+
+	auth, err := middleware.NewOfflineAuthenticator(offlineConfig(), ks)
+	require.NoError(t, err)
+
+	cases := map[string]string{
+		"RS256": signToken(t, jwt.SigningMethodRS256, rsaKey, "", nil),
+		"ES256": signToken(t, jwt.SigningMethodES256, ecKey, "ec", nil),
+		"EdDSA": signToken(t, jwt.SigningMethodEdDSA, edKey, "ed", nil),
+	}
+	for name, token := range cases {
+		user, err := auth.Authenticate(context.Background(), token)
+		require.NoError(t, err, name)
+		assert.Equal(t, "user-1", user.ID, name)
+		assert.Equal(t, []string{"develop"}, user.Roles, name)
+	}
+
+	// Chave desconhecida
+	otherKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	_, err = auth.Authenticate(context.Background(), signToken(t, jwt.SigningMethodRS256, otherKey, "", nil))
+	assert.ErrorIs(t, err, middleware.ErrInvalidToken)
+}
+
+func TestOfflineAuthenticator_Claims(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ks, err := middleware.NewFileKeySet(
+		[]middleware.KeyFile{{Path: writePEM(t, t.TempDir(), "rsa.pem", &rsaKey.PublicKey)}}, "", "")
+	require.NoError(t, err)
+
+	auth, err := middleware.NewOfflineAuthenticator(offlineConfig(), ks)
+	require.NoError(t, err)
+
+	cases := []struct {
+		name   string
+		claims jwt.MapClaims
+		ok     bool
+	}{
+		{"issuer errado", jwt.MapClaims{"iss": "http://outro"}, false},
+		{"audience errada", jwt.MapClaims{"aud": "outra-api"}, false},
+		{"expirado dentro do leeway", jwt.MapClaims{"exp": time.Now().Add(-10 * time.Second).Unix()}, true},
+		{"expirado além do leeway", jwt.MapClaims{"exp": time.Now().Add(-time.Minute).Unix()}, false},
+	}
+	for _, tc := range cases {
+		_, err := auth.Authenticate(context.Background(), signToken(t, jwt.SigningMethodRS256, rsaKey, "", tc.claims))
+		if tc.ok {
+			assert.NoError(t, err, tc.name)
+		} else {
+			assert.ErrorIs(t, err, middleware.ErrInvalidToken, tc.name)
+		}
+	}
+}
+
+func TestFileKeySet_Rotation(t *testing.T) {
+	jwksPath := filepath.Join(t.TempDir(), "jwks.json")
+
+	oldKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	newKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	writeJWKS(t, jwksPath, jose.JSONWebKey{Key: &oldKey.PublicKey, KeyID: "v1", Algorithm: "RS256"})
+	ks, err := middleware.NewFileKeySet(nil, jwksPath, "")
+	require.NoError(t, err)
+	auth, err := middleware.NewOfflineAuthenticator(offlineConfig(), ks)
+	require.NoError(t, err)
+
+	newToken := signToken(t, jwt.SigningMethodRS256, newKey, "v2", nil)
+	_, err = auth.Authenticate(context.Background(), newToken)
+	assert.ErrorIs(t, err, middleware.ErrInvalidToken)
+
+	// Publica a nova chave mantendo a antiga durante a transição
+	writeJWKS(t, jwksPath,
+		jose.JSONWebKey{Key: &oldKey.PublicKey, KeyID: "v1", Algorithm: "RS256"},
+		jose.JSONWebKey{Key: &newKey.PublicKey, KeyID: "v2", Algorithm: "RS256"})
+	require.NoError(t, ks.Reload())
+
+	_, err = auth.Authenticate(context.Background(), newToken)
+	assert.NoError(t, err)
+	_, err = auth.Authenticate(context.Background(), signToken(t, jwt.SigningMethodRS256, oldKey, "v1", nil))
+	assert.NoError(t, err)
+
+	// Arquivo inválido não derruba as chaves carregadas
+	require.NoError(t, os.WriteFile(jwksPath, []byte("{"), 0o600))
+	assert.Error(t, ks.Reload())
+	_, err = auth.Authenticate(context.Background(), newToken)
+	assert.NoError(t, err)
+}
+
+func TestFileKeySet_Invalid(t *testing.T) {
+	_, err := middleware.NewFileKeySet(nil, "", "")
+	assert.Error(t, err)
+
+	_, err = middleware.NewFileKeySet([]middleware.KeyFile{{Path: "/nao/existe.pem"}}, "", "")
+	assert.Error(t, err)
+
+	_, err = middleware.NewFileKeySet(nil, "", "nao-e-base64!")
+	assert.Error(t, err)
+
+	assert.Equal(t,
+		[]middleware.KeyFile{{KeyID: "k1", Path: "/a.pem"}, {Path: "/b.pem"}},
+		middleware.ParseKeyFiles("k1=/a.pem, /b.pem,"))
+}
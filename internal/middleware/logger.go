@@ -1,52 +1,39 @@
 package middleware
 
 import (
-	"context"
 	"log/slog"
 	"time"
 
-	"go-api-first-steps/pkg/logger"
-
 	"github.com/gin-gonic/gin"
-	"github.com/google/uuid"
 )
 
+// RequestLogger registra cada requisição finalizada. O trace_id/span_id dos logs vem
+// do span aberto por Tracing (registre Tracing antes deste middleware).
 func RequestLogger() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		start := time.Now()
 		path := c.Request.URL.Path
 		method := c.Request.Method
 
-		// 1. Rastreamento Distribuído:
-		// Tenta pegar o ID que veio do cliente (ex: Front-end ou outro microsserviço)
-		traceID := c.GetHeader("X-Trace-ID")
-
-		// Se não veio nada, geramos um novo UUID
-		if traceID == "" {
-			traceID = uuid.NewString()
-		}
-
-		// 2. Injeta no Contexto do Go usando a chave tipada do pkg/logger
-		// Isso permite que o slog.InfoContext pegue esse valor depois
-		ctx := context.WithValue(c.Request.Context(), logger.TraceIDKey, traceID)
-		c.Request = c.Request.WithContext(ctx)
-
-		// 3. Devolve no Header para o cliente saber qual é o ID
-		c.Header("X-Trace-ID", traceID)
-
 		// Processa a requisição
 		c.Next()
 
-		// 4. Log final da requisição
+		// Log final da requisição
 		duration := time.Since(start)
 		status := c.Writer.Status()
 
-		slog.InfoContext(ctx, "Requisição finalizada",
+		attrs := []any{
 			slog.Int("status", status),
 			slog.String("method", method),
 			slog.String("path", path),
 			slog.Float64("duration_ms", float64(duration.Nanoseconds())/1e6),
 			slog.String("ip", c.ClientIP()),
-		)
+		}
+		// Quem fez a requisição (usuário OIDC ou serviço via API key), para auditoria
+		if user := GetUser(c); user != nil {
+			attrs = append(attrs, slog.String("user_id", user.ID), slog.String("principal", user.Type))
+		}
+
+		slog.InfoContext(c.Request.Context(), "Requisição finalizada", attrs...)
 	}
 }
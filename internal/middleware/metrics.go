@@ -0,0 +1,78 @@
+package middleware
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"go-api-first-steps/internal/metrics"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Credenciais usadas no rótulo "credential" de auth_attempts_total
+const (
+	CredentialToken      = "token"
+	CredentialAPIKey     = "api_key"
+	CredentialClientCert = "client_cert"
+	CredentialDev        = "dev"
+	CredentialNone       = "none" // Nenhuma credencial enviada: resultado "missing"
+)
+
+// Metrics registra contagem, latência e requisições em andamento por rota (RED).
+// A rota é o template do Gin (c.FullPath()); requisições sem rota viram "unmatched".
+func Metrics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		method := c.Request.Method
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+
+		metrics.HTTPRequestsInFlight.Inc(method, route)
+		defer metrics.HTTPRequestsInFlight.Dec(method, route)
+
+		c.Next()
+
+		status := strconv.Itoa(c.Writer.Status())
+		metrics.HTTPRequests.Inc(method, route, status)
+		metrics.HTTPRequestDuration.Observe(time.Since(start).Seconds(), method, route, status)
+	}
+}
+
+// RecordAuth contabiliza uma tentativa de autenticação (REST ou gRPC) pelo erro devolvido.
+func RecordAuth(transport, credential string, err error) {
+	outcome := authOutcome(err)
+	if credential == CredentialNone {
+		outcome = "missing"
+	}
+	metrics.AuthAttempts.Inc(transport, credential, outcome)
+}
+
+// RecordAuthz contabiliza a decisão de autorização de um usuário autenticado.
+func RecordAuthz(transport string, allowed bool) {
+	decision := "denied"
+	if allowed {
+		decision = "allowed"
+	}
+	metrics.AuthzDecisions.Inc(transport, decision)
+}
+
+func authOutcome(err error) string {
+	switch {
+	case err == nil:
+		return "success"
+	case errors.Is(err, ErrInvalidToken):
+		return "invalid_token"
+	case errors.Is(err, ErrInvalidAPIKey):
+		return "invalid_api_key"
+	case errors.Is(err, ErrInvalidClientCert):
+		return "invalid_client_cert"
+	case errors.Is(err, ErrAuthUnavailable):
+		return "unavailable"
+	case errors.Is(err, ErrAuthNotConfigured):
+		return "not_configured"
+	}
+	return "error"
+}
@@ -0,0 +1,117 @@
+package middleware
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ErrInvalidClientCert indica certificado de cliente não verificado ou sem identidade mapeada.
+var ErrInvalidClientCert = errors.New("certificado de cliente não autorizado")
+
+// ClientCertRule associa certificados de cliente (já verificados contra a CA) a uma
+// identidade. Subject compara o CN; SAN compara qualquer DNS, URI (ex: SPIFFE) ou
+// e-mail do certificado. Com os dois preenchidos, ambos precisam bater.
+type ClientCertRule struct {
+	Subject string   `yaml:"subject" json:"subject"`
+	SAN     string   `yaml:"san" json:"san"`
+	ID      string   `yaml:"id" json:"id"` // Default: "mtls:<CN>"
+	Name    string   `yaml:"name" json:"name"`
+	Roles   []string `yaml:"roles" json:"roles"`
+	Tenant  string   `yaml:"tenant" json:"tenant"`
+}
+
+// ClientCertMapper escolhe a primeira regra que casa com o certificado.
+type ClientCertMapper struct {
+	Rules []ClientCertRule
+}
+
+// LoadClientCertMapper lê o arquivo de identidades mTLS (YAML ou JSON):
+//
+//	clients:
+//	  - subject: batch-estoque
+//	    roles: [develop]
+//	  - san: spiffe://exemplo.com/ns/prod/sa/relatorios
+//	    id: svc-relatorios
+//	    roles: [manager]
+//	    tenant: varejo
+func LoadClientCertMapper(path string) (*ClientCertMapper, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao ler identidades mTLS: %w", err)
+	}
+
+	var file struct {
+		Clients []ClientCertRule `yaml:"clients" json:"clients"`
+	}
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("arquivo de identidades mTLS inválido: %w", err)
+	}
+	for i, rule := range file.Clients {
+		if rule.Subject == "" && rule.SAN == "" {
+			return nil, fmt.Errorf("identidade mTLS #%d: subject ou san obrigatório", i+1)
+		}
+	}
+	return &ClientCertMapper{Rules: file.Clients}, nil
+}
+
+// Map devolve o User do serviço dono do certificado (Type=PrincipalService).
+func (m *ClientCertMapper) Map(cert *x509.Certificate) (*User, bool) {
+	sans := append([]string{}, cert.DNSNames...)
+	sans = append(sans, cert.EmailAddresses...)
+	for _, uri := range cert.URIs {
+		sans = append(sans, uri.String())
+	}
+
+	for _, rule := range m.Rules {
+		if rule.Subject != "" && rule.Subject != cert.Subject.CommonName {
+			continue
+		}
+		if rule.SAN != "" && !slices.Contains(sans, rule.SAN) {
+			continue
+		}
+
+		id := rule.ID
+		if id == "" {
+			id = "mtls:" + cert.Subject.CommonName
+		}
+		name := rule.Name
+		if name == "" {
+			name = cert.Subject.CommonName
+		}
+		return &User{
+			ID:       id,
+			Name:     name,
+			Username: name,
+			ClientID: id,
+			Roles:    append([]string{}, rule.Roles...),
+			Type:     PrincipalService,
+			Tenant:   rule.Tenant,
+		}, true
+	}
+	return nil, false
+}
+
+// AuthenticateClientCert autentica pelo certificado de cliente da conexão TLS.
+// Só aceita certificados verificados pelo servidor (tls.Config.ClientCAs).
+func (a *Authenticator) AuthenticateClientCert(state *tls.ConnectionState) (*User, error) {
+	if a.ClientCerts == nil || state == nil || len(state.VerifiedChains) == 0 || len(state.VerifiedChains[0]) == 0 {
+		return nil, ErrInvalidClientCert
+	}
+	cert := state.VerifiedChains[0][0]
+	user, ok := a.ClientCerts.Map(cert)
+	if !ok {
+		return nil, fmt.Errorf("%w: nenhuma identidade para CN=%q", ErrInvalidClientCert, cert.Subject.CommonName)
+	}
+	return user, nil
+}
+
+// HasClientCert informa se a conexão trouxe um certificado de cliente verificado.
+func HasClientCert(state *tls.ConnectionState) bool {
+	return state != nil && len(state.VerifiedChains) > 0
+}
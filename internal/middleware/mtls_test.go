@@ -0,0 +1,130 @@
+package middleware_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go-api-first-steps/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testClientsFile = `
+clients:
+  - subject: batch-estoque
+    roles: [develop]
+  - san: spiffe://exemplo.com/ns/prod/sa/relatorios
+    id: svc-relatorios
+    roles: [manager]
+    tenant: varejo
+`
+
+func loadTestMapper(t *testing.T) *middleware.ClientCertMapper {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "clients.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(testClientsFile), 0o600))
+	mapper, err := middleware.LoadClientCertMapper(path)
+	require.NoError(t, err)
+	return mapper
+}
+
+func clientCert(cn string, uris ...string) *x509.Certificate {
+	cert := &x509.Certificate{Subject: pkix.Name{CommonName: cn}}
+	for _, raw := range uris {
+		u, _ := url.Parse(raw)
+		cert.URIs = append(cert.URIs, u)
+	}
+	return cert
+}
+
+func TestLoadClientCertMapper_Invalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "clients.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("clients:\n  - roles: [admin]\n"), 0o600))
+
+	_, err := middleware.LoadClientCertMapper(path)
+	assert.ErrorContains(t, err, "subject ou san obrigatório")
+}
+
+func TestClientCertMapper_Map(t *testing.T) {
+	mapper := loadTestMapper(t)
+
+	user, ok := mapper.Map(clientCert("batch-estoque"))
+	require.True(t, ok)
+	assert.Equal(t, "mtls:batch-estoque", user.ID)
+	assert.Equal(t, []string{"develop"}, user.Roles)
+	assert.Equal(t, middleware.PrincipalService, user.Type)
+
+	user, ok = mapper.Map(clientCert("relatorios-7f9c", "spiffe://exemplo.com/ns/prod/sa/relatorios"))
+	require.True(t, ok)
+	assert.Equal(t, "svc-relatorios", user.ID)
+	assert.Equal(t, "varejo", user.Tenant)
+	assert.Equal(t, []string{"manager"}, user.Roles)
+
+	_, ok = mapper.Map(clientCert("intruso"))
+	assert.False(t, ok)
+}
+
+func TestAuthenticator_ClientCert(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ks, err := middleware.NewFileKeySet([]middleware.KeyFile{{Path: writePEM(t, t.TempDir(), "rsa.pem", &rsaKey.PublicKey)}}, "", "")
+	require.NoError(t, err)
+	auth, err := middleware.NewOfflineAuthenticator(offlineConfig(), ks)
+	require.NoError(t, err)
+	auth.ClientCerts = loadTestMapper(t)
+
+	r := gin.New()
+	r.GET("/products", auth.CheckMiddleware("OR", "develop", "manager"), func(c *gin.Context) {
+		user := middleware.GetUser(c)
+		c.JSON(http.StatusOK, gin.H{"id": user.ID, "tenant": user.Tenant})
+	})
+
+	request := func(state *tls.ConnectionState) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/products", nil)
+		req.TLS = state
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	verified := func(cert *x509.Certificate) *tls.ConnectionState {
+		return &tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}, VerifiedChains: [][]*x509.Certificate{{cert}}}
+	}
+
+	w := request(verified(clientCert("relatorios", "spiffe://exemplo.com/ns/prod/sa/relatorios")))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"id":"svc-relatorios","tenant":"varejo"}`, w.Body.String())
+
+	// Certificado verificado, mas sem identidade mapeada
+	w = request(verified(clientCert("intruso")))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Contains(t, w.Body.String(), "Certificado de cliente não autorizado")
+
+	// Certificado não verificado pela CA não vale como credencial: cai na exigência de token
+	cert := clientCert("batch-estoque")
+	w = request(&tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}})
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Contains(t, w.Body.String(), "Token não informado")
+
+	// Token tem precedência sobre o certificado
+	w = request(verified(cert))
+	assert.Equal(t, http.StatusOK, w.Code)
+	req := httptest.NewRequest(http.MethodGet, "/products", nil)
+	req.TLS = verified(cert)
+	req.Header.Set("Authorization", "Bearer invalido")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Contains(t, w.Body.String(), "Token inválido")
+}
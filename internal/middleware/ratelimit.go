@@ -0,0 +1,202 @@
+package middleware
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"go-api-first-steps/internal/config"
+	"go-api-first-steps/internal/metrics"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RateLimitResult é o estado do bucket após uma tentativa de consumo.
+type RateLimitResult struct {
+	Allowed    bool
+	Limit      int           // Capacidade do bucket (burst)
+	Remaining  int           // Tokens restantes
+	Reset      time.Duration // Tempo até o bucket encher de novo
+	RetryAfter time.Duration // Tempo até o próximo token (apenas quando negado)
+}
+
+// RateLimitStore guarda os token buckets.
+// A implementação em memória serve para uma instância; para várias réplicas,
+// implemente a interface sobre um store compartilhado (ex: Redis).
+type RateLimitStore interface {
+	Allow(ctx context.Context, key string, rule config.RateLimitRule) (RateLimitResult, error)
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// MemoryRateLimitStore é um RateLimitStore em memória, seguro para uso concorrente.
+type MemoryRateLimitStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// NewMemoryRateLimitStore cria um store em memória vazio.
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	return &MemoryRateLimitStore{
+		buckets: make(map[string]*tokenBucket),
+		now:     time.Now,
+	}
+}
+
+func (s *MemoryRateLimitStore) Allow(_ context.Context, key string, rule config.RateLimitRule) (RateLimitResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.sweep(now)
+
+	capacity := float64(rule.Burst)
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &tokenBucket{tokens: capacity, last: now}
+		s.buckets[key] = b
+	}
+
+	// Reabastece proporcionalmente ao tempo decorrido
+	b.tokens = math.Min(capacity, b.tokens+now.Sub(b.last).Seconds()*rule.RPS)
+	b.last = now
+
+	res := RateLimitResult{Limit: rule.Burst}
+	if b.tokens >= 1 {
+		b.tokens--
+		res.Allowed = true
+	} else {
+		res.RetryAfter = secondsToDuration((1 - b.tokens) / rule.RPS)
+	}
+	res.Remaining = int(math.Floor(b.tokens))
+	res.Reset = secondsToDuration((capacity - b.tokens) / rule.RPS)
+	return res, nil
+}
+
+// sweep remove buckets cheios há mais de 10 minutos (chamado com o lock).
+func (s *MemoryRateLimitStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < time.Minute {
+		return
+	}
+	s.lastSweep = now
+	for key, b := range s.buckets {
+		if now.Sub(b.last) > 10*time.Minute {
+			delete(s.buckets, key)
+		}
+	}
+}
+
+func secondsToDuration(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
+
+// RateLimiter aplica token buckets por usuário, client ou IP, com limites
+// configuráveis por grupo de rotas e por role.
+type RateLimiter struct {
+	Store  RateLimitStore
+	Config config.RateLimitConfig
+}
+
+// NewRateLimiter cria o RateLimiter com o store informado.
+func NewRateLimiter(store RateLimitStore, cfg config.RateLimitConfig) *RateLimiter {
+	return &RateLimiter{Store: store, Config: cfg}
+}
+
+// Middleware devolve o handler do Gin para o grupo de rotas informado.
+// Deve vir depois do CheckMiddleware para conhecer o usuário e suas roles.
+//
+// Exemplo:
+//
+//	products.GET("", auth.CheckMiddleware("OR", "develop"), limiter.Middleware("products"), h.List)
+func (l *RateLimiter) Middleware(group string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !l.Config.Enabled {
+			c.Next()
+			return
+		}
+
+		user := GetUser(c)
+		rule, reason := l.ruleFor(group, user)
+		key := group + ":" + l.keyFor(c, user)
+
+		res, err := l.Store.Allow(c.Request.Context(), key, rule)
+		if err != nil {
+			// Falha no store não deve derrubar a API: deixa passar
+			slog.ErrorContext(c.Request.Context(), "Erro no store de rate limit", "error", err)
+			c.Next()
+			return
+		}
+
+		c.Header("RateLimit-Limit", strconv.Itoa(res.Limit))
+		c.Header("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+		c.Header("RateLimit-Reset", strconv.Itoa(ceilSeconds(res.Reset)))
+
+		if !res.Allowed {
+			metrics.RateLimitRejections.Inc(group, reason)
+			slog.WarnContext(c.Request.Context(), "Rate limit excedido", "group", group, "key", key, "reason", reason)
+
+			c.Header("Retry-After", strconv.Itoa(ceilSeconds(res.RetryAfter)))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": "Muitas requisições"})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// ruleFor usa o limite do grupo, se configurado; senão, o maior limite entre as
+// roles do usuário ou o default. Roles só substituem o default: um grupo com limite
+// próprio (ex: escrita mais restrita) vale para todos. Devolve também a origem do
+// limite (group, role ou default), o rótulo reason de rate_limit_rejections_total.
+func (l *RateLimiter) ruleFor(group string, user *User) (config.RateLimitRule, string) {
+	if r, ok := l.Config.Groups[group]; ok {
+		return r, "group"
+	}
+	if user == nil {
+		return l.Config.Default, "default"
+	}
+
+	var best *config.RateLimitRule
+	for _, role := range user.Roles {
+		if r, ok := l.Config.Roles[role]; ok && (best == nil || r.RPS > best.RPS) {
+			best = &r
+		}
+	}
+	if best != nil {
+		return *best, "role"
+	}
+	return l.Config.Default, "default"
+}
+
+// keyFor identifica quem está consumindo o bucket. Sem usuário/client, cai para o IP.
+// Usuários e clients são isolados por tenant: emissores diferentes podem repetir sub/azp.
+func (l *RateLimiter) keyFor(c *gin.Context, user *User) string {
+	switch l.Config.KeyBy {
+	case "client":
+		if user != nil && user.ClientID != "" {
+			return "client:" + user.Tenant + "/" + user.ClientID
+		}
+	case "ip":
+	default:
+		if user != nil && user.ID != "" {
+			return "user:" + user.Tenant + "/" + user.ID
+		}
+		if id := c.GetString("user_id"); id != "" {
+			return "user:" + id
+		}
+	}
+	return "ip:" + c.ClientIP()
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,135 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go-api-first-steps/internal/config"
+	"go-api-first-steps/internal/metrics"
+	"go-api-first-steps/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// setupRateLimitRouter simula o CheckMiddleware injetando o usuário dos headers X-Test-*
+func setupRateLimitRouter(limiter *middleware.RateLimiter) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/products", func(c *gin.Context) {
+		if id := c.GetHeader("X-Test-User"); id != "" {
+			middleware.SetUser(c, &middleware.User{ID: id, Roles: c.Request.Header.Values("X-Test-Role"), Tenant: c.GetHeader("X-Test-Tenant")})
+		}
+	}, limiter.Middleware("products"), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	return r
+}
+
+func getAs(r http.Handler, user string, roles ...string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest("GET", "/products", nil)
+	if user != "" {
+		req.Header.Set("X-Test-User", user)
+	}
+	for _, role := range roles {
+		req.Header.Add("X-Test-Role", role)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestRateLimiter_RejectsAfterBurst(t *testing.T) {
+	limiter := middleware.NewRateLimiter(middleware.NewMemoryRateLimitStore(), config.RateLimitConfig{
+		Enabled: true,
+		Default: config.RateLimitRule{RPS: 0.01, Burst: 2},
+	})
+	r := setupRateLimitRouter(limiter)
+	before := metrics.RateLimitRejections.Value("products", "default")
+
+	first := getAs(r, "ana")
+	assert.Equal(t, http.StatusOK, first.Code)
+	assert.Equal(t, "2", first.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, "1", first.Header().Get("RateLimit-Remaining"))
+
+	assert.Equal(t, http.StatusOK, getAs(r, "ana").Code)
+
+	rejected := getAs(r, "ana")
+	assert.Equal(t, http.StatusTooManyRequests, rejected.Code)
+	assert.NotEmpty(t, rejected.Header().Get("Retry-After"))
+	assert.Equal(t, before+1, metrics.RateLimitRejections.Value("products", "default"))
+
+	// Outro usuário tem o próprio bucket
+	assert.Equal(t, http.StatusOK, getAs(r, "bruno").Code)
+}
+
+func TestRateLimiter_RoleGetsHigherLimit(t *testing.T) {
+	limiter := middleware.NewRateLimiter(middleware.NewMemoryRateLimitStore(), config.RateLimitConfig{
+		Enabled: true,
+		Default: config.RateLimitRule{RPS: 0.01, Burst: 1},
+		Roles:   map[string]config.RateLimitRule{"admin": {RPS: 0.01, Burst: 3}},
+	})
+	r := setupRateLimitRouter(limiter)
+	before := metrics.RateLimitRejections.Value("products", "role")
+
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, http.StatusOK, getAs(r, "root", "develop", "admin").Code)
+	}
+	assert.Equal(t, http.StatusTooManyRequests, getAs(r, "root", "develop", "admin").Code)
+	assert.Equal(t, before+1, metrics.RateLimitRejections.Value("products", "role"))
+}
+
+func TestRateLimiter_RoleDoesNotLiftGroupLimit(t *testing.T) {
+	limiter := middleware.NewRateLimiter(middleware.NewMemoryRateLimitStore(), config.RateLimitConfig{
+		Enabled: true,
+		Default: config.RateLimitRule{RPS: 0.01, Burst: 1},
+		Groups:  map[string]config.RateLimitRule{"products": {RPS: 0.01, Burst: 2}},
+		Roles:   map[string]config.RateLimitRule{"admin": {RPS: 0.01, Burst: 10}},
+	})
+	r := setupRateLimitRouter(limiter)
+	before := metrics.RateLimitRejections.Value("products", "group")
+
+	for i := 0; i < 2; i++ {
+		assert.Equal(t, http.StatusOK, getAs(r, "root", "admin").Code)
+	}
+	rejected := getAs(r, "root", "admin")
+	assert.Equal(t, http.StatusTooManyRequests, rejected.Code)
+	assert.Equal(t, "2", rejected.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, before+1, metrics.RateLimitRejections.Value("products", "group"))
+}
+
+func TestRateLimiter_KeysAreScopedByTenant(t *testing.T) {
+	limiter := middleware.NewRateLimiter(middleware.NewMemoryRateLimitStore(), config.RateLimitConfig{
+		Enabled: true,
+		Default: config.RateLimitRule{RPS: 0.01, Burst: 1},
+	})
+	r := setupRateLimitRouter(limiter)
+	getIn := func(tenant string) int {
+		req, _ := http.NewRequest("GET", "/products", nil)
+		req.Header.Set("X-Test-User", "ana")
+		req.Header.Set("X-Test-Tenant", tenant)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	// O mesmo sub em emissores diferentes não divide o bucket
+	assert.Equal(t, http.StatusOK, getIn("varejo"))
+	assert.Equal(t, http.StatusOK, getIn("atacado"))
+	assert.Equal(t, http.StatusTooManyRequests, getIn("varejo"))
+}
+
+func TestRateLimiter_FallsBackToIPAndCanBeDisabled(t *testing.T) {
+	cfg := config.RateLimitConfig{Enabled: true, Default: config.RateLimitRule{RPS: 0.01, Burst: 1}}
+	r := setupRateLimitRouter(middleware.NewRateLimiter(middleware.NewMemoryRateLimitStore(), cfg))
+
+	assert.Equal(t, http.StatusOK, getAs(r, "").Code)
+	assert.Equal(t, http.StatusTooManyRequests, getAs(r, "").Code)
+
+	cfg.Enabled = false
+	r = setupRateLimitRouter(middleware.NewRateLimiter(middleware.NewMemoryRateLimitStore(), cfg))
+	for i := 0; i < 5; i++ {
+		assert.Equal(t, http.StatusOK, getAs(r, "").Code)
+	}
+}
@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Recovery substitui o gin.Recovery: o panic vira um log de erro com o atributo
+// "error" (ExceptionTelemetry no App Insights) e a pilha em "stack", marca o span
+// da requisição com o erro e responde 500. Registre depois de Tracing,
+// RequestLogger e Metrics, para que eles vejam o 500.
+func Recovery() gin.HandlerFunc {
+	return gin.CustomRecoveryWithWriter(io.Discard, func(c *gin.Context, recovered any) {
+		err, ok := recovered.(error)
+		if !ok {
+			err = fmt.Errorf("%v", recovered)
+		}
+		err = fmt.Errorf("panic: %w", err)
+
+		ctx := c.Request.Context()
+		span := trace.SpanFromContext(ctx)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		slog.ErrorContext(ctx, "Panic recuperado",
+			slog.Any("error", err),
+			slog.String("stack", string(debug.Stack())),
+			slog.String("method", c.Request.Method),
+			slog.String("path", c.Request.URL.Path),
+		)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Erro interno"})
+	})
+}
@@ -0,0 +1,35 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go-api-first-steps/internal/middleware"
+	"go-api-first-steps/internal/tracing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+)
+
+func TestRecovery_MarksSpanAndResponds500(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	spans := tracing.StartInMemory(t)
+
+	r := gin.New()
+	r.Use(middleware.Tracing(), middleware.Recovery())
+	r.GET("/boom", func(*gin.Context) { panic("nil map") })
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/boom", nil))
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.JSONEq(t, `{"error":"Erro interno"}`, w.Body.String())
+
+	got := spans.GetSpans()
+	require.Len(t, got, 1)
+	assert.Equal(t, codes.Error, got[0].Status.Code)
+	require.Len(t, got[0].Events, 1, "panic registrado como exceção no span")
+	assert.Equal(t, "exception", got[0].Events[0].Name)
+}
@@ -0,0 +1,95 @@
+package middleware
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetTenantClaim configura a claim do tenant (ex: "tenant", "org.id" ou
+// `attributes["tenant-id"]`). Vazio volta a usar o tenant do emissor.
+func (a *Authenticator) SetTenantClaim(path string) error {
+	if strings.TrimSpace(path) == "" {
+		a.TenantClaim = nil
+		return nil
+	}
+	parsed, err := parseClaimPath(path)
+	if err != nil {
+		return fmt.Errorf("claim de tenant inválida: %w", err)
+	}
+	a.TenantClaim = parsed
+	return nil
+}
+
+// tenantOf resolve o tenant do token. Em multi-issuer vale o tenant do emissor:
+// a claim TenantClaim, se presente, precisa concordar com ele (senão o admin de um
+// realm escolheria o tenant do token). Sem multi-issuer vale a claim TenantClaim;
+// com ela configurada, um token sem tenant algum é rejeitado, para não cair no
+// tenant padrão.
+func (a *Authenticator) tenantOf(issuer *Authenticator, claims map[string]any) (string, error) {
+	var claimed string
+	if a.TenantClaim != nil {
+		if values := toStrings(lookupClaim(claims, a.TenantClaim), false); len(values) > 0 {
+			claimed = values[0]
+		}
+	}
+
+	switch {
+	case issuer.Tenant != "":
+		if claimed != "" && claimed != issuer.Tenant {
+			return "", fmt.Errorf("%w: claim de tenant %q não corresponde ao emissor (%s)", ErrInvalidToken, claimed, issuer.Tenant)
+		}
+		return issuer.Tenant, nil
+	case claimed != "":
+		return claimed, nil
+	case a.TenantClaim != nil:
+		return "", fmt.Errorf("%w: claim de tenant %q ausente", ErrInvalidToken, strings.Join(a.TenantClaim, "."))
+	}
+	return "", nil
+}
+
+// ParseAttributeClaims interpreta "atributo[=caminho],..." (ex:
+// "categories=product_categories,department"). Sem caminho, a claim tem o nome do atributo.
+func ParseAttributeClaims(spec string) (map[string][]string, error) {
+	out := make(map[string][]string)
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		name, path, ok := strings.Cut(item, "=")
+		if !ok {
+			path = name
+		}
+		name = strings.TrimSpace(name)
+		parsed, err := parseClaimPath(path)
+		if err != nil || name == "" {
+			return nil, fmt.Errorf("atributo inválido %q: %v", item, err)
+		}
+		out[name] = parsed
+	}
+	return out, nil
+}
+
+// attributesOf extrai os AttributeClaims das claims (lista ou string única).
+func (a *Authenticator) attributesOf(claims map[string]any) map[string][]string {
+	if len(a.AttributeClaims) == 0 {
+		return nil
+	}
+	attrs := make(map[string][]string, len(a.AttributeClaims))
+	for name, path := range a.AttributeClaims {
+		if values := toStrings(lookupClaim(claims, path), false); len(values) > 0 {
+			attrs[name] = values
+		}
+	}
+	return attrs
+}
+
+// TenantOf devolve o tenant do usuário autenticado na requisição ("" = tenant padrão).
+func TenantOf(c *gin.Context) string {
+	if user := GetUser(c); user != nil {
+		return user.Tenant
+	}
+	return ""
+}
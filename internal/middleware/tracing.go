@@ -0,0 +1,79 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"go-api-first-steps/internal/tracing"
+	"go-api-first-steps/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Tracing abre o span da requisição HTTP, continuando o trace do cliente
+// (traceparent/tracestate ou o X-Trace-ID legado), e devolve os IDs na resposta:
+// traceparent para clientes W3C e X-Trace-ID por compatibilidade (o valor enviado
+// pelo cliente, ou o trace ID W3C se ele não enviou).
+func Tracing() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := tracing.Extract(c.Request.Context(), propagation.HeaderCarrier(c.Request.Header))
+
+		route := c.FullPath()
+		name := c.Request.Method
+		if route != "" {
+			name += " " + route
+		}
+		ctx, span := tracing.Tracer().Start(ctx, name,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(c.Request.Method),
+				semconv.HTTPRoute(route),
+				semconv.URLPath(c.Request.URL.Path),
+				semconv.ClientAddress(c.ClientIP()),
+			),
+		)
+		defer span.End()
+
+		// Quem ainda lê logger.TraceIDKey (ex: pkg/client) recebe o mesmo ID devolvido ao cliente
+		traceID := tracing.LegacyTraceID(ctx, c.GetHeader(tracing.LegacyTraceHeader))
+		ctx = context.WithValue(ctx, logger.TraceIDKey, traceID)
+		ctx = logger.WithRoute(ctx, route) // Overrides de nível por rota
+		c.Request = c.Request.WithContext(ctx)
+
+		tracing.Inject(ctx, propagation.HeaderCarrier(c.Writer.Header()))
+		c.Header(tracing.LegacyTraceHeader, traceID)
+
+		c.Next()
+
+		status := c.Writer.Status()
+		span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+		if status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+		if user := GetUser(c); user != nil {
+			span.SetAttributes(semconv.EnduserID(user.ID))
+		}
+	}
+}
+
+// startAuthSpan abre o span da verificação de uma credencial (assinatura,
+// introspecção, consulta da API key...), filho do span da requisição.
+func startAuthSpan(ctx context.Context, credential string) (context.Context, trace.Span) {
+	return tracing.Tracer().Start(ctx, "auth.verify", trace.WithAttributes(attribute.String("auth.credential", credential)))
+}
+
+// endAuthSpan registra o resultado (mesmo rótulo de auth_attempts_total) e fecha o span.
+func endAuthSpan(span trace.Span, user *User, err error) {
+	span.SetAttributes(attribute.String("auth.outcome", authOutcome(err)))
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+	} else if user != nil {
+		span.SetAttributes(semconv.EnduserID(user.ID))
+	}
+	span.End()
+}
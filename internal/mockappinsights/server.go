@@ -0,0 +1,155 @@
+// Package mockappinsights é um endpoint de ingestão do Application Insights em
+// processo, para testes: recebe os lotes do SDK (POST /v2/track, JSON por linha
+// em gzip) e guarda os envelopes para inspeção.
+package mockappinsights
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/microsoft/ApplicationInsights-Go/appinsights"
+)
+
+// InstrumentationKey é a chave aceita pelo servidor (qualquer outra também é aceita).
+const InstrumentationKey = "00000000-0000-0000-0000-000000000000"
+
+// Envelope é um item de telemetria como recebido pela ingestão.
+type Envelope struct {
+	Name string            `json:"name"`
+	Time string            `json:"time"`
+	IKey string            `json:"iKey"`
+	Tags map[string]string `json:"tags"`
+	Data struct {
+		BaseType string         `json:"baseType"` // RequestData, RemoteDependencyData, ExceptionData, MessageData...
+		BaseData map[string]any `json:"baseData"`
+	} `json:"data"`
+}
+
+// Properties devolve as propriedades customizadas (customDimensions) do item.
+func (e Envelope) Properties() map[string]string {
+	out := map[string]string{}
+	props, _ := e.Data.BaseData["properties"].(map[string]any)
+	for k, v := range props {
+		out[k] = fmt.Sprint(v)
+	}
+	return out
+}
+
+// Field devolve um campo de baseData como texto (ex: "name", "id", "responseCode").
+func (e Envelope) Field(name string) string {
+	v, ok := e.Data.BaseData[name]
+	if !ok {
+		return ""
+	}
+	return fmt.Sprint(v)
+}
+
+// TestServer é o endpoint de ingestão servido por um httptest.Server.
+type TestServer struct {
+	Server *httptest.Server
+
+	mu        sync.Mutex
+	envelopes []Envelope
+}
+
+// Start sobe o endpoint e o encerra no fim do teste. Use srv.ConnectionString()
+// como APPINSIGHTS_CONNECTION_STRING ou srv.NewClient() para um client pronto:
+//
+//	srv := mockappinsights.Start(t)
+//	client := srv.NewClient()
+//	...
+//	srv.Flush(client)
+//	requests := srv.Items("RequestData")
+func Start(t testing.TB) *TestServer {
+	t.Helper()
+	s := &TestServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.track))
+	t.Cleanup(s.Server.Close)
+	return s
+}
+
+// ConnectionString aponta o SDK para este servidor.
+func (s *TestServer) ConnectionString() string {
+	return fmt.Sprintf("InstrumentationKey=%s;IngestionEndpoint=%s/", InstrumentationKey, s.Server.URL)
+}
+
+// NewClient cria um TelemetryClient que envia para este servidor.
+func (s *TestServer) NewClient() appinsights.TelemetryClient {
+	config := appinsights.NewTelemetryConfiguration(InstrumentationKey)
+	config.EndpointUrl = s.Server.URL + "/v2/track"
+	config.MaxBatchInterval = 50 * time.Millisecond
+	return appinsights.NewTelemetryClientFromConfig(config)
+}
+
+// Flush envia o que está pendente no client e o fecha.
+func (s *TestServer) Flush(client appinsights.TelemetryClient) {
+	<-client.Channel().Close(5 * time.Second)
+}
+
+// Items devolve os envelopes recebidos, filtrados por baseType (vazio = todos).
+func (s *TestServer) Items(baseType string) []Envelope {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []Envelope
+	for _, e := range s.envelopes {
+		if baseType == "" || e.Data.BaseType == baseType {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+func (s *TestServer) track(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost || r.URL.Path != "/v2/track" {
+		http.NotFound(w, r)
+		return
+	}
+	var body io.Reader = r.Body
+	if r.Header.Get("Content-Encoding") == "gzip" {
+		gz, err := gzip.NewReader(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		defer gz.Close()
+		body = gz
+	}
+
+	var received []Envelope
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var e Envelope
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		received = append(received, e)
+	}
+	if err := scanner.Err(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	s.envelopes = append(s.envelopes, received...)
+	s.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"itemsReceived": len(received),
+		"itemsAccepted": len(received),
+		"errors":        []any{},
+	})
+}
@@ -0,0 +1,410 @@
+// Package mockoidc implementa um emissor OIDC mínimo, compatível com as rotas do
+// Keycloak (discovery, JWKS e token endpoint), para desenvolvimento local e testes.
+//
+// NUNCA use em produção: qualquer um que alcance o token endpoint obtém um token
+// com as roles que pedir.
+package mockoidc
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Realm usado nas rotas, imitando o layout do Keycloak (/realms/<realm>/...)
+const Realm = "mock"
+
+// DefaultClientID é o audience (aud/azp) dos tokens quando nenhum client é informado
+const DefaultClientID = "go-api-product"
+
+// TokenRequest descreve o token a emitir. Campos vazios recebem valores padrão.
+type TokenRequest struct {
+	Subject     string
+	Name        string
+	Email       string
+	Username    string
+	ClientID    string
+	RealmRoles  []string
+	ClientRoles []string
+	ExpiresIn   time.Duration
+	SessionID   string         // sid; vazio = sessão nova
+	Opaque      bool           // Emite um token opaco (só validável por introspecção)
+	Extra       map[string]any // Claims adicionais (sobrescrevem as padrão)
+}
+
+// Provider guarda a chave de assinatura e emite tokens para o issuer configurado.
+type Provider struct {
+	key    *rsa.PrivateKey
+	keyID  string
+	issuer string
+
+	mu      sync.Mutex
+	opaque  map[string]jwt.MapClaims // token opaco -> claims
+	revoked map[string]bool          // jti revogados e sid encerrados ("jti:..." / "sid:...")
+}
+
+// New cria um Provider para baseURL (ex: http://localhost:8180).
+// Se keyFile não for vazio, a chave é lida dele, ou gerada e persistida na
+// primeira execução, para que os tokens continuem válidos entre reinícios.
+func New(baseURL, keyFile string) (*Provider, error) {
+	key, err := loadOrCreateKey(keyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	jwk := jose.JSONWebKey{Key: &key.PublicKey}
+	thumb, err := jwk.Thumbprint(crypto.SHA256)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao calcular kid: %w", err)
+	}
+
+	return &Provider{
+		key:     key,
+		keyID:   base64.RawURLEncoding.EncodeToString(thumb),
+		issuer:  strings.TrimSuffix(baseURL, "/") + "/realms/" + Realm,
+		opaque:  make(map[string]jwt.MapClaims),
+		revoked: make(map[string]bool),
+	}, nil
+}
+
+// Issuer retorna a URL do realm, que é o KEYCLOAK_URL da API.
+func (p *Provider) Issuer() string {
+	return p.issuer
+}
+
+// PublicKey retorna a chave pública de verificação.
+func (p *Provider) PublicKey() *rsa.PublicKey {
+	return &p.key.PublicKey
+}
+
+// Token emite um access token assinado (RS256) no formato do Keycloak,
+// ou um token opaco se req.Opaque.
+func (p *Provider) Token(req TokenRequest) (string, error) {
+	if req.Subject == "" {
+		req.Subject = "usuario-teste-id-123"
+	}
+	if req.ClientID == "" {
+		req.ClientID = DefaultClientID
+	}
+	if req.Username == "" {
+		req.Username = req.Subject
+	}
+	if req.ExpiresIn <= 0 {
+		req.ExpiresIn = time.Hour
+	}
+
+	if req.SessionID == "" {
+		req.SessionID = randomID()
+	}
+
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"jti":                randomID(),
+		"sid":                req.SessionID,
+		"iss":                p.issuer,
+		"sub":                req.Subject,
+		"aud":                req.ClientID,
+		"azp":                req.ClientID,
+		"iat":                now.Unix(),
+		"exp":                now.Add(req.ExpiresIn).Unix(),
+		"preferred_username": req.Username,
+		"realm_access":       map[string]any{"roles": nonNil(req.RealmRoles)},
+		"resource_access": map[string]any{
+			req.ClientID: map[string]any{"roles": nonNil(req.ClientRoles)},
+		},
+	}
+	if req.Name != "" {
+		claims["name"] = req.Name
+	}
+	if req.Email != "" {
+		claims["email"] = req.Email
+	}
+	for k, v := range req.Extra {
+		claims[k] = v
+	}
+
+	if req.Opaque {
+		// Ida e volta em JSON para que os tipos fiquem iguais aos de um JWT decodificado
+		data, err := json.Marshal(claims)
+		if err != nil {
+			return "", err
+		}
+		var stored jwt.MapClaims
+		if err := json.Unmarshal(data, &stored); err != nil {
+			return "", err
+		}
+
+		token := randomID() + randomID()
+		p.mu.Lock()
+		p.opaque[token] = stored
+		p.mu.Unlock()
+		return token, nil
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = p.keyID
+	return token.SignedString(p.key)
+}
+
+// Handler expõe as rotas do realm:
+//
+//	GET  /realms/mock/.well-known/openid-configuration
+//	GET  /realms/mock/protocol/openid-connect/certs
+//	POST /realms/mock/protocol/openid-connect/token
+//	POST /realms/mock/protocol/openid-connect/token/introspect (RFC 7662)
+//	POST /realms/mock/protocol/openid-connect/revoke (RFC 7009)
+func (p *Provider) Handler() http.Handler {
+	prefix := "/realms/" + Realm
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+prefix+"/.well-known/openid-configuration", p.discovery)
+	mux.HandleFunc("GET "+prefix+"/protocol/openid-connect/certs", p.jwks)
+	mux.HandleFunc("POST "+prefix+"/protocol/openid-connect/token", p.token)
+	mux.HandleFunc("POST "+prefix+"/protocol/openid-connect/token/introspect", p.introspect)
+	mux.HandleFunc("POST "+prefix+"/protocol/openid-connect/revoke", p.revoke)
+	return mux
+}
+
+func (p *Provider) discovery(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{
+		"issuer":                                p.issuer,
+		"jwks_uri":                              p.issuer + "/protocol/openid-connect/certs",
+		"token_endpoint":                        p.issuer + "/protocol/openid-connect/token",
+		"introspection_endpoint":                p.issuer + "/protocol/openid-connect/token/introspect",
+		"revocation_endpoint":                   p.issuer + "/protocol/openid-connect/revoke",
+		"authorization_endpoint":                p.issuer + "/protocol/openid-connect/auth",
+		"grant_types_supported":                 []string{"client_credentials", "password"},
+		"response_types_supported":              []string{"token"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+	})
+}
+
+func (p *Provider) jwks(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, jose.JSONWebKeySet{Keys: []jose.JSONWebKey{{
+		Key:       &p.key.PublicKey,
+		KeyID:     p.keyID,
+		Algorithm: string(jose.RS256),
+		Use:       "sig",
+	}}})
+}
+
+// token aceita um form (como o Keycloak) com os campos:
+// sub, name, email, username, client_id, realm_roles, client_roles (separados por vírgula)
+// e expires_in (segundos).
+func (p *Provider) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+		return
+	}
+
+	req := TokenRequest{
+		Subject:     r.Form.Get("sub"),
+		Name:        r.Form.Get("name"),
+		Email:       r.Form.Get("email"),
+		Username:    firstNonEmpty(r.Form.Get("username"), r.Form.Get("sub")),
+		ClientID:    r.Form.Get("client_id"),
+		RealmRoles:  splitList(r.Form.Get("realm_roles")),
+		ClientRoles: splitList(r.Form.Get("client_roles")),
+	}
+	if s := r.Form.Get("expires_in"); s != "" {
+		secs, err := strconv.Atoi(s)
+		if err != nil || secs <= 0 {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request", "error_description": "expires_in inválido"})
+			return
+		}
+		req.ExpiresIn = time.Duration(secs) * time.Second
+	}
+
+	token, err := p.Token(req)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "server_error"})
+		return
+	}
+
+	expiresIn := req.ExpiresIn
+	if expiresIn <= 0 {
+		expiresIn = time.Hour
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"access_token": token,
+		"token_type":   "Bearer",
+		"expires_in":   int(expiresIn.Seconds()),
+	})
+}
+
+// Claims devolve as claims de um token ativo emitido por este Provider
+// (assinatura e exp válidos, não revogado, sessão não encerrada).
+func (p *Provider) Claims(token string) (jwt.MapClaims, bool) {
+	p.mu.Lock()
+	claims, ok := p.opaque[token]
+	p.mu.Unlock()
+
+	if !ok {
+		parsed, err := jwt.Parse(token, func(*jwt.Token) (any, error) { return &p.key.PublicKey, nil },
+			jwt.WithValidMethods([]string{"RS256"}))
+		if err != nil {
+			return nil, false
+		}
+		claims = parsed.Claims.(jwt.MapClaims)
+	} else if exp, err := claims.GetExpirationTime(); err != nil || exp == nil || time.Now().After(exp.Time) {
+		return nil, false
+	}
+
+	jti, _ := claims["jti"].(string)
+	sid, _ := claims["sid"].(string)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.revoked["jti:"+jti] || p.revoked["sid:"+sid] {
+		return nil, false
+	}
+	return claims, true
+}
+
+// RevokeToken revoga um token emitido por este Provider (como um logout no Keycloak).
+func (p *Provider) RevokeToken(token string) {
+	claims, ok := p.Claims(token)
+	if !ok {
+		return
+	}
+	jti, _ := claims["jti"].(string)
+	p.mu.Lock()
+	p.revoked["jti:"+jti] = true
+	p.mu.Unlock()
+}
+
+// EndSession encerra a sessão sid: todos os tokens dela ficam inativos.
+func (p *Provider) EndSession(sid string) {
+	p.mu.Lock()
+	p.revoked["sid:"+sid] = true
+	p.mu.Unlock()
+}
+
+// introspect responde como o Keycloak: {"active": false} ou as claims com "active": true.
+func (p *Provider) introspect(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+		return
+	}
+
+	claims, ok := p.Claims(r.Form.Get("token"))
+	if !ok {
+		writeJSON(w, http.StatusOK, map[string]any{"active": false})
+		return
+	}
+
+	resp := map[string]any{"active": true, "token_type": "Bearer"}
+	for k, v := range claims {
+		resp[k] = v
+	}
+	resp["client_id"] = claims["azp"]
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (p *Provider) revoke(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+		return
+	}
+	p.RevokeToken(r.Form.Get("token"))
+	w.WriteHeader(http.StatusOK)
+}
+
+func randomID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func loadOrCreateKey(path string) (*rsa.PrivateKey, error) {
+	if path != "" {
+		data, err := os.ReadFile(path)
+		switch {
+		case err == nil:
+			block, _ := pem.Decode(data)
+			if block == nil {
+				return nil, fmt.Errorf("arquivo %s não contém PEM", path)
+			}
+			parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+			if err != nil {
+				return nil, fmt.Errorf("chave inválida em %s: %w", path, err)
+			}
+			key, ok := parsed.(*rsa.PrivateKey)
+			if !ok {
+				return nil, fmt.Errorf("chave em %s não é RSA", path)
+			}
+			return key, nil
+		case !errors.Is(err, fs.ErrNotExist):
+			return nil, fmt.Errorf("falha ao ler chave %s: %w", path, err)
+		}
+	}
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao gerar chave: %w", err)
+	}
+	if path == "" {
+		return key, nil
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600); err != nil {
+		return nil, fmt.Errorf("falha ao salvar chave %s: %w", path, err)
+	}
+	return key, nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func splitList(s string) []string {
+	var out []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+func nonNil(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,69 @@
+package mockoidc_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"testing"
+
+	"go-api-first-steps/internal/mockoidc"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProvider_TokenEndpoint(t *testing.T) {
+	idp := mockoidc.Start(t)
+
+	// Discovery + JWKS pelo próprio go-oidc, como a API faz
+	provider, err := oidc.NewProvider(context.Background(), idp.Issuer())
+	require.NoError(t, err)
+
+	resp, err := http.PostForm(idp.Issuer()+"/protocol/openid-connect/token", url.Values{
+		"sub":          {"ana"},
+		"email":        {"ana@example.com"},
+		"realm_roles":  {"manager"},
+		"client_roles": {"develop, viewer"},
+		"expires_in":   {"60"},
+	})
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var body struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Equal(t, 60, body.ExpiresIn)
+
+	token, err := provider.Verifier(&oidc.Config{ClientID: mockoidc.DefaultClientID}).
+		Verify(context.Background(), body.AccessToken)
+	require.NoError(t, err)
+
+	var claims struct {
+		Email          string                              `json:"email"`
+		RealmAccess    struct{ Roles []string }            `json:"realm_access"`
+		ResourceAccess map[string]struct{ Roles []string } `json:"resource_access"`
+	}
+	require.NoError(t, token.Claims(&claims))
+	assert.Equal(t, "ana", token.Subject)
+	assert.Equal(t, "ana@example.com", claims.Email)
+	assert.Equal(t, []string{"manager"}, claims.RealmAccess.Roles)
+	assert.Equal(t, []string{"develop", "viewer"}, claims.ResourceAccess[mockoidc.DefaultClientID].Roles)
+}
+
+func TestProvider_PersistentKey(t *testing.T) {
+	keyFile := filepath.Join(t.TempDir(), "keys", "key.pem")
+
+	first, err := mockoidc.New("http://localhost:8180", keyFile)
+	require.NoError(t, err)
+	second, err := mockoidc.New("http://localhost:8180", keyFile)
+	require.NoError(t, err)
+
+	assert.True(t, first.PublicKey().Equal(second.PublicKey()))
+	assert.Equal(t, "http://localhost:8180/realms/mock", second.Issuer())
+}
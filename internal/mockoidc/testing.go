@@ -0,0 +1,42 @@
+package mockoidc
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+// TestServer é um Provider servido por um httptest.Server.
+type TestServer struct {
+	*Provider
+	Server *httptest.Server
+}
+
+// Start sobe o Provider em processo e o encerra no fim do teste.
+// Use srv.Issuer() como KEYCLOAK_URL e srv.MustToken para obter tokens:
+//
+//	srv := mockoidc.Start(t)
+//	cfg := &config.Config{KeycloakURL: srv.Issuer(), ClientID: mockoidc.DefaultClientID, ...}
+func Start(t testing.TB) *TestServer {
+	t.Helper()
+
+	ts := httptest.NewUnstartedServer(nil)
+	provider, err := New("http://"+ts.Listener.Addr().String(), "")
+	if err != nil {
+		t.Fatalf("mockoidc: %v", err)
+	}
+	ts.Config.Handler = provider.Handler()
+	ts.Start()
+	t.Cleanup(ts.Close)
+
+	return &TestServer{Provider: provider, Server: ts}
+}
+
+// MustToken emite um token ou falha o teste.
+func (s *TestServer) MustToken(t testing.TB, req TokenRequest) string {
+	t.Helper()
+	token, err := s.Token(req)
+	if err != nil {
+		t.Fatalf("mockoidc: %v", err)
+	}
+	return token
+}
@@ -0,0 +1,159 @@
+package apikey
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"go-api-first-steps/internal/domain"
+)
+
+// KeyPrefix identifica as chaves desta API (facilita secret scanning em repositórios).
+// Formato: pak_<prefixo público>_<segredo>
+const KeyPrefix = "pak_"
+
+// lastUsedResolution evita um UPDATE por requisição: last_used_at é gravado no máximo
+// uma vez por intervalo.
+const lastUsedResolution = time.Minute
+
+// Erros de validação na criação
+var (
+	ErrNameRequired  = errors.New("nome obrigatório")
+	ErrRolesRequired = errors.New("informe ao menos uma role")
+	ErrInvalidExpiry = errors.New("expiração deve ser no futuro")
+)
+
+// Service gerencia o ciclo de vida das API keys: emissão, listagem, revogação e validação.
+type Service struct {
+	Repo domain.APIKeyRepository
+	Now  func() time.Time // Relógio (substituível em testes)
+}
+
+// NewService cria uma nova instância do Service com o repositório injetado.
+func NewService(repo domain.APIKeyRepository) *Service {
+	return &Service{Repo: repo, Now: time.Now}
+}
+
+// Create emite uma nova chave e devolve o valor em texto puro, que só é exibido
+// nesta resposta (no banco fica apenas o hash). expiresAt nil = sem expiração.
+// A chave atua no tenant informado (o de quem a criou).
+func (s *Service) Create(name string, roles []string, expiresAt *time.Time, createdBy, tenant string) (string, *domain.APIKey, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", nil, ErrNameRequired
+	}
+	roles = normalizeRoles(roles)
+	if len(roles) == 0 {
+		return "", nil, ErrRolesRequired
+	}
+	if expiresAt != nil && !expiresAt.After(s.Now()) {
+		return "", nil, ErrInvalidExpiry
+	}
+
+	prefix, err := randomString(6, hex.EncodeToString)
+	if err != nil {
+		return "", nil, err
+	}
+	secret, err := randomString(32, base64.RawURLEncoding.EncodeToString)
+	if err != nil {
+		return "", nil, err
+	}
+
+	key := &domain.APIKey{
+		Name:      name,
+		Prefix:    prefix,
+		Hash:      hashSecret(secret),
+		Roles:     roles,
+		CreatedBy: createdBy,
+		Tenant:    tenant,
+		ExpiresAt: expiresAt,
+	}
+	if err := s.Repo.Save(key); err != nil {
+		return "", nil, err
+	}
+	return KeyPrefix + prefix + "_" + secret, key, nil
+}
+
+// List retorna as chaves do tenant (sem os segredos).
+func (s *Service) List(tenant string) ([]domain.APIKey, error) {
+	return s.Repo.FindAll(tenant)
+}
+
+// Revoke invalida a chave do tenant imediatamente. Revogar de novo não é erro;
+// chave de outro tenant é domain.ErrAPIKeyNotFound.
+func (s *Service) Revoke(tenant string, id uint) error {
+	return s.Repo.Revoke(tenant, id, s.Now())
+}
+
+// Verify valida a chave em texto puro e registra o uso.
+// Qualquer falha (formato, prefixo desconhecido, hash, revogada, expirada) vira ErrInvalidAPIKey.
+func (s *Service) Verify(ctx context.Context, raw string) (*domain.APIKey, error) {
+	prefix, secret, ok := strings.Cut(strings.TrimPrefix(raw, KeyPrefix), "_")
+	if !strings.HasPrefix(raw, KeyPrefix) || !ok || prefix == "" || secret == "" {
+		return nil, domain.ErrInvalidAPIKey
+	}
+
+	key, err := s.Repo.FindByPrefix(prefix)
+	if errors.Is(err, domain.ErrAPIKeyNotFound) {
+		return nil, domain.ErrInvalidAPIKey
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if subtle.ConstantTimeCompare([]byte(key.Hash), []byte(hashSecret(secret))) != 1 {
+		return nil, domain.ErrInvalidAPIKey
+	}
+
+	now := s.Now()
+	if !key.Active(now) {
+		return nil, domain.ErrInvalidAPIKey
+	}
+
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= lastUsedResolution {
+		if err := s.Repo.TouchLastUsed(key.ID, now); err != nil {
+			// Falha no rastreio de uso não deve bloquear a requisição
+			slog.WarnContext(ctx, "Falha ao registrar uso da API key", "prefix", key.Prefix, "error", err)
+		} else {
+			key.LastUsedAt = &now
+		}
+	}
+	return key, nil
+}
+
+// hashSecret usa SHA-256: o segredo tem 256 bits aleatórios, então não precisa
+// de um KDF lento como senhas de usuário.
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+func randomString(n int, encode func([]byte) string) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("falha ao gerar API key: %w", err)
+	}
+	return encode(b), nil
+}
+
+func normalizeRoles(roles []string) []string {
+	seen := make(map[string]bool)
+	out := []string{}
+	for _, r := range roles {
+		r = strings.TrimSpace(r)
+		if r == "" || strings.Contains(r, ",") || seen[r] {
+			continue
+		}
+		seen[r] = true
+		out = append(out, r)
+	}
+	return out
+}
@@ -0,0 +1,94 @@
+package apikey
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"go-api-first-steps/internal/domain"
+	storage "go-api-first-steps/internal/storage/sqlite"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestService(t *testing.T) (*Service, *time.Time) {
+	t.Helper()
+	repo := storage.NewAPIKeyRepository(storage.NewRepository(":memory:").DB)
+	svc := NewService(repo)
+
+	now := time.Date(2026, 1, 10, 12, 0, 0, 0, time.UTC)
+	svc.Now = func() time.Time { return now }
+	return svc, &now
+}
+
+func TestCreateAndVerify(t *testing.T) {
+	svc, now := newTestService(t)
+	ctx := context.Background()
+
+	plaintext, key, err := svc.Create("batch-estoque", []string{"develop", " develop", "manager"}, nil, "admin-1", "")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(plaintext, KeyPrefix+key.Prefix+"_"))
+	assert.Equal(t, []string{"develop", "manager"}, key.Roles)
+	assert.NotContains(t, key.Hash, strings.TrimPrefix(plaintext, KeyPrefix+key.Prefix+"_"), "o segredo não pode ficar no banco")
+
+	got, err := svc.Verify(ctx, plaintext)
+	require.NoError(t, err)
+	assert.Equal(t, key.ID, got.ID)
+	require.NotNil(t, got.LastUsedAt)
+	assert.True(t, got.LastUsedAt.Equal(*now))
+
+	// Segredo errado com prefixo válido
+	_, err = svc.Verify(ctx, KeyPrefix+key.Prefix+"_segredo-errado")
+	assert.ErrorIs(t, err, domain.ErrInvalidAPIKey)
+
+	for _, raw := range []string{"", "pak_", "pak_semsegredo", "outro_formato", "pak_ffffffffffff_x"} {
+		_, err = svc.Verify(ctx, raw)
+		assert.ErrorIs(t, err, domain.ErrInvalidAPIKey, raw)
+	}
+}
+
+func TestExpiryAndRevocation(t *testing.T) {
+	svc, now := newTestService(t)
+	ctx := context.Background()
+
+	expiresAt := now.Add(time.Hour)
+	plaintext, key, err := svc.Create("parceiro", []string{"develop"}, &expiresAt, "", "")
+	require.NoError(t, err)
+
+	_, err = svc.Verify(ctx, plaintext)
+	require.NoError(t, err)
+
+	*now = now.Add(2 * time.Hour)
+	_, err = svc.Verify(ctx, plaintext)
+	assert.ErrorIs(t, err, domain.ErrInvalidAPIKey, "expirada")
+
+	plaintext, key, err = svc.Create("job", []string{"develop"}, nil, "", "")
+	require.NoError(t, err)
+	require.NoError(t, svc.Revoke("", key.ID))
+	require.NoError(t, svc.Revoke("", key.ID), "revogar de novo é idempotente")
+	_, err = svc.Verify(ctx, plaintext)
+	assert.ErrorIs(t, err, domain.ErrInvalidAPIKey, "revogada")
+
+	assert.ErrorIs(t, svc.Revoke("", 999), domain.ErrAPIKeyNotFound)
+
+	keys, err := svc.List("")
+	require.NoError(t, err)
+	require.Len(t, keys, 2)
+	assert.NotNil(t, keys[1].RevokedAt)
+}
+
+func TestCreateValidation(t *testing.T) {
+	svc, now := newTestService(t)
+
+	_, _, err := svc.Create(" ", []string{"develop"}, nil, "", "")
+	assert.ErrorIs(t, err, ErrNameRequired)
+
+	_, _, err = svc.Create("job", []string{" "}, nil, "", "")
+	assert.ErrorIs(t, err, ErrRolesRequired)
+
+	past := now.Add(-time.Minute)
+	_, _, err = svc.Create("job", []string{"develop"}, &past, "", "")
+	assert.ErrorIs(t, err, ErrInvalidExpiry)
+}
@@ -1,15 +1,42 @@
 package product
 
 import (
-	"fmt"
+	"context"
 
 	"go-api-first-steps/internal/domain"
+	"go-api-first-steps/internal/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Ações checadas pelo Authorizer (mesmos nomes das permissões da política)
+const (
+	ActionCreate = "products:create"
+	ActionUpdate = "products:update"
+	ActionDelete = "products:delete"
 )
 
 // Service encapsula a lógica de negócio relacionada a produtos.
 // Ele interage com o Repositório para persistência de dados.
+//
+// As escritas passam pelo Authorizer (regras por atributos do produto), aqui e não
+// no transporte, para que REST e gRPC apliquem as mesmas regras.
 type Service struct {
-	Repo domain.ProductRepository
+	Repo       domain.ProductRepository
+	Authorizer domain.Authorizer // nil = sem regras por atributos
+	Actor      domain.Actor      // Quem executa as operações (veja ForActor)
+
+	ctx context.Context // Contexto da requisição (veja WithContext); nil = context.Background()
+}
+
+// ProductInput são os campos editáveis de um produto.
+// Status vazio vale draft na criação; Category e Status vazios mantêm o valor atual na atualização.
+type ProductInput struct {
+	Name     string
+	Category string
+	Status   string
 }
 
 // NewService cria uma nova instância do Service com o repositório injetado.
@@ -17,17 +44,45 @@ func NewService(repo domain.ProductRepository) *Service {
 	return &Service{Repo: repo}
 }
 
+// ForActor devolve um Service que opera em nome de actor: restrito ao tenant
+// dele, registrando-o em created_by/updated_by e sujeito às regras do Authorizer.
+// Os handlers REST e gRPC chamam com o usuário autenticado.
+func (s *Service) ForActor(actor domain.Actor) *Service {
+	return &Service{Repo: s.Repo.WithTenant(actor.Tenant), Authorizer: s.Authorizer, Actor: actor, ctx: s.ctx}
+}
+
+// WithContext devolve um Service que opera no contexto da requisição: os spans
+// das operações (e das consultas ao banco) ficam dentro do trace dela.
+func (s *Service) WithContext(ctx context.Context) *Service {
+	return &Service{Repo: s.Repo.WithContext(ctx), Authorizer: s.Authorizer, Actor: s.Actor, ctx: ctx}
+}
+
 // CreateProduct valida e cria um novo produto.
 // Retorna erro se o nome estiver vazio.
-func (s *Service) CreateProduct(name string) (string, error) {
-	if name == "" {
-		return "", fmt.Errorf("nome vazio")
+func (s *Service) CreateProduct(in ProductInput) (name string, err error) {
+	s, end := s.traced("CreateProduct")
+	defer func() { end(err) }()
+
+	if in.Name == "" {
+		return "", domain.ErrInvalidProductName
+	}
+	if in.Status == "" {
+		in.Status = domain.ProductStatusDraft
+	}
+	if !validStatus(in.Status) {
+		return "", domain.ErrInvalidProductStatus
+	}
+
+	p := domain.Product{Name: in.Name, Category: in.Category, Status: in.Status, CreatedBy: s.Actor.ID}
+	if err := s.authorize(ActionCreate, &p); err != nil {
+		return "", err
 	}
-	p, err := s.Repo.Save(name)
+
+	created, err := s.Repo.Save(p)
 	if err != nil {
 		return "", err
 	}
-	return p.Name, nil
+	return created.Name, nil
 }
 
 // ListProducts retorna uma lista paginada de produtos.
@@ -35,7 +90,10 @@ func (s *Service) CreateProduct(name string) (string, error) {
 // Parâmetros:
 //   - page: Número da página (inicia em 1).
 //   - pageSize: Quantidade de itens por página (default 10, max 100).
-func (s *Service) ListProducts(page, pageSize int) ([]domain.Product, error) {
+func (s *Service) ListProducts(page, pageSize int) (products []domain.Product, err error) {
+	s, end := s.traced("ListProducts")
+	defer func() { end(err) }()
+
 	if page < 1 {
 		page = 1
 	}
@@ -46,17 +104,92 @@ func (s *Service) ListProducts(page, pageSize int) ([]domain.Product, error) {
 }
 
 // GetProduct busca um produto pelo ID.
-func (s *Service) GetProduct(id uint) (*domain.Product, error) {
+func (s *Service) GetProduct(id uint) (p *domain.Product, err error) {
+	s, end := s.traced("GetProduct")
+	defer func() { end(err) }()
+
 	return s.Repo.FindByID(id)
 }
 
-func (s *Service) UpdateProduct(id uint, name string) error {
-	if name == "" {
-		return fmt.Errorf("nome vazio")
+// UpdateProduct altera o produto se as regras permitirem ao Actor editar o produto
+// como ele está hoje e como ele fica depois da alteração (ex: o dono só edita
+// enquanto for rascunho e não pode publicá-lo; o manager não o tira da sua categoria).
+func (s *Service) UpdateProduct(id uint, in ProductInput) (err error) {
+	s, end := s.traced("UpdateProduct")
+	defer func() { end(err) }()
+
+	if in.Name == "" {
+		return domain.ErrInvalidProductName
 	}
-	return s.Repo.Update(id, name)
+	if in.Status != "" && !validStatus(in.Status) {
+		return domain.ErrInvalidProductStatus
+	}
+
+	p, err := s.Repo.FindByID(id)
+	if err != nil {
+		return err
+	}
+	if err := s.authorize(ActionUpdate, p); err != nil {
+		return err
+	}
+
+	p.Name = in.Name
+	if in.Category != "" {
+		p.Category = in.Category
+	}
+	if in.Status != "" {
+		p.Status = in.Status
+	}
+	p.UpdatedBy = s.Actor.ID
+	if err := s.authorize(ActionUpdate, p); err != nil {
+		return err
+	}
+	return s.Repo.Update(p)
 }
 
-func (s *Service) DeleteProduct(id uint) error {
+func (s *Service) DeleteProduct(id uint) (err error) {
+	s, end := s.traced("DeleteProduct")
+	defer func() { end(err) }()
+
+	if s.Authorizer != nil {
+		p, err := s.Repo.FindByID(id)
+		if err != nil {
+			return err
+		}
+		if err := s.authorize(ActionDelete, p); err != nil {
+			return err
+		}
+	}
 	return s.Repo.Delete(id)
 }
+
+// traced abre o span da operação e devolve uma cópia do Service cujo repositório
+// usa o contexto do span, para que as consultas ao banco fiquem aninhadas nele.
+func (s *Service) traced(op string) (*Service, func(error)) {
+	ctx := s.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, span := tracing.Tracer().Start(ctx, "product."+op,
+		trace.WithAttributes(attribute.String("tenant", s.Actor.Tenant)))
+
+	traced := &Service{Repo: s.Repo.WithContext(ctx), Authorizer: s.Authorizer, Actor: s.Actor, ctx: ctx}
+	return traced, func(err error) {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}
+}
+
+func (s *Service) authorize(action string, p *domain.Product) error {
+	if s.Authorizer == nil {
+		return nil
+	}
+	return s.Authorizer.Authorize(s.Actor, action, p.Attributes())
+}
+
+func validStatus(status string) bool {
+	return status == domain.ProductStatusDraft || status == domain.ProductStatusPublished
+}
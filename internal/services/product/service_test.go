@@ -1,6 +1,9 @@
 package product
 
 import (
+	"errors"
+	"go-api-first-steps/internal/authz"
+	"go-api-first-steps/internal/domain"
 	storage "go-api-first-steps/internal/storage/sqlite"
 	"testing"
 )
@@ -12,7 +15,7 @@ func TestCreateAndListProduct(t *testing.T) {
 	service := NewService(repo)
 
 	// 2. Teste de CRIAÇÃO
-	createdName, err := service.CreateProduct("Mouse Gamer")
+	createdName, err := service.CreateProduct(ProductInput{Name: "Mouse Gamer"})
 
 	// Validações (Asserts)
 	if err != nil {
@@ -41,9 +44,135 @@ func TestValidateEmptyName(t *testing.T) {
 	repo := storage.NewRepository(":memory:")
 	service := NewService(repo)
 
-	_, err := service.CreateProduct("")
+	_, err := service.CreateProduct(ProductInput{Name: ""})
 
 	if err == nil {
 		t.Error("Deveria ter dado erro ao criar produto sem nome, mas não deu.")
 	}
 }
+
+func TestTenantIsolation(t *testing.T) {
+	service := NewService(storage.NewRepository(":memory:"))
+	varejo, atacado := service.ForActor(domain.Actor{Tenant: "varejo"}), service.ForActor(domain.Actor{Tenant: "atacado"})
+
+	if _, err := varejo.CreateProduct(ProductInput{Name: "Teclado"}); err != nil {
+		t.Fatalf("Erro inesperado ao criar: %v", err)
+	}
+	// O mesmo nome em outro tenant é permitido; no mesmo tenant, não
+	if _, err := atacado.CreateProduct(ProductInput{Name: "Teclado"}); err != nil {
+		t.Fatalf("Nome deveria ser único só dentro do tenant: %v", err)
+	}
+	if _, err := varejo.CreateProduct(ProductInput{Name: "Teclado"}); !errors.Is(err, domain.ErrDuplicateProductName) {
+		t.Errorf("Esperava ErrDuplicateProductName, recebeu %v", err)
+	}
+
+	products, err := varejo.ListProducts(1, 10)
+	if err != nil || len(products) != 1 {
+		t.Fatalf("Esperava 1 produto do varejo, encontrou %d (err=%v)", len(products), err)
+	}
+	id := products[0].ID
+
+	// Leituras e escritas de outro tenant não enxergam o produto
+	if _, err := atacado.GetProduct(id); !errors.Is(err, domain.ErrProductNotFound) {
+		t.Errorf("Leitura entre tenants deveria falhar, recebeu %v", err)
+	}
+	if err := atacado.UpdateProduct(id, ProductInput{Name: "Outro"}); !errors.Is(err, domain.ErrProductNotFound) {
+		t.Errorf("Atualização entre tenants deveria falhar, recebeu %v", err)
+	}
+	if err := atacado.DeleteProduct(id); !errors.Is(err, domain.ErrProductNotFound) {
+		t.Errorf("Remoção entre tenants deveria falhar, recebeu %v", err)
+	}
+
+	p, err := varejo.GetProduct(id)
+	if err != nil {
+		t.Fatalf("Produto do varejo sumiu: %v", err)
+	}
+	if p.Name != "Teclado" {
+		t.Errorf("Produto do varejo foi alterado por outro tenant: %s", p.Name)
+	}
+}
+
+// allowOwner é um Authorizer mínimo: só o autor altera o produto.
+type allowOwner struct{}
+
+func (allowOwner) Authorize(actor domain.Actor, action string, resource map[string]string) error {
+	if action == ActionCreate || resource["created_by"] == actor.ID {
+		return nil
+	}
+	return domain.ErrForbidden
+}
+
+func TestOwnershipAndAuthorization(t *testing.T) {
+	service := NewService(storage.NewRepository(":memory:"))
+	service.Authorizer = allowOwner{}
+	ana := service.ForActor(domain.Actor{ID: "ana"})
+	bia := service.ForActor(domain.Actor{ID: "bia"})
+
+	if _, err := ana.CreateProduct(ProductInput{Name: "Monitor", Category: "monitores"}); err != nil {
+		t.Fatalf("Erro inesperado ao criar: %v", err)
+	}
+	products, _ := ana.ListProducts(1, 10)
+	p := products[0]
+	if p.CreatedBy != "ana" || p.UpdatedBy != "ana" || p.Status != domain.ProductStatusDraft {
+		t.Fatalf("Autoria/status errados: %+v", p)
+	}
+
+	if err := bia.UpdateProduct(p.ID, ProductInput{Name: "Outro"}); !errors.Is(err, domain.ErrForbidden) {
+		t.Errorf("Esperava ErrForbidden, recebeu %v", err)
+	}
+	if err := bia.DeleteProduct(p.ID); !errors.Is(err, domain.ErrForbidden) {
+		t.Errorf("Esperava ErrForbidden no delete, recebeu %v", err)
+	}
+
+	if err := ana.UpdateProduct(p.ID, ProductInput{Name: "Monitor 4K", Status: domain.ProductStatusPublished}); err != nil {
+		t.Fatalf("Autor deveria poder editar: %v", err)
+	}
+	updated, _ := ana.GetProduct(p.ID)
+	if updated.Name != "Monitor 4K" || updated.Category != "monitores" || updated.Status != domain.ProductStatusPublished {
+		t.Errorf("Atualização não aplicada (categoria deveria ser mantida): %+v", updated)
+	}
+
+	if err := ana.UpdateProduct(p.ID, ProductInput{Name: "X", Status: "arquivado"}); !errors.Is(err, domain.ErrInvalidProductStatus) {
+		t.Errorf("Esperava ErrInvalidProductStatus, recebeu %v", err)
+	}
+}
+
+// A regra vale também para o produto depois da alteração: com a política padrão,
+// o manager não move o produto para fora da sua categoria e o autor não publica o rascunho.
+func TestUpdateAuthorizesResult(t *testing.T) {
+	enforcer, err := authz.NewEnforcer("")
+	if err != nil {
+		t.Fatalf("Erro ao carregar a política padrão: %v", err)
+	}
+	service := NewService(storage.NewRepository(":memory:"))
+	service.Authorizer = enforcer
+	ana := service.ForActor(domain.Actor{ID: "ana", Roles: []string{"develop"}})
+	manager := service.ForActor(domain.Actor{ID: "gil", Roles: []string{"manager"},
+		Attributes: map[string][]string{"categories": {"monitores"}}})
+
+	if _, err := ana.CreateProduct(ProductInput{Name: "Monitor", Category: "monitores"}); err != nil {
+		t.Fatalf("Erro inesperado ao criar: %v", err)
+	}
+	products, _ := ana.ListProducts(1, 10)
+	id := products[0].ID
+
+	if err := manager.UpdateProduct(id, ProductInput{Name: "Monitor", Category: "teclados"}); !errors.Is(err, domain.ErrForbidden) {
+		t.Errorf("Manager moveu o produto para outra categoria: %v", err)
+	}
+	if err := ana.UpdateProduct(id, ProductInput{Name: "Monitor", Status: domain.ProductStatusPublished}); !errors.Is(err, domain.ErrForbidden) {
+		t.Errorf("Autor publicou o próprio rascunho: %v", err)
+	}
+
+	p, _ := ana.GetProduct(id)
+	if p.Category != "monitores" || p.Status != domain.ProductStatusDraft {
+		t.Errorf("Produto alterado apesar da recusa: %+v", p)
+	}
+
+	// Dentro das regras, as edições continuam permitidas
+	if err := ana.UpdateProduct(id, ProductInput{Name: "Monitor 4K"}); err != nil {
+		t.Errorf("Autor deveria editar o rascunho: %v", err)
+	}
+	if err := manager.UpdateProduct(id, ProductInput{Name: "Monitor 4K", Status: domain.ProductStatusPublished}); err != nil {
+		t.Errorf("Manager deveria publicar na sua categoria: %v", err)
+	}
+}
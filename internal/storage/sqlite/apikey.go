@@ -0,0 +1,139 @@
+package storage
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"go-api-first-steps/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+// APIKeyModel representa a API key no banco. Roles ficam numa coluna texto separada por vírgula.
+type APIKeyModel struct {
+	ID         uint   `gorm:"primaryKey"`
+	Name       string `gorm:"type:text;not null"`
+	Prefix     string `gorm:"type:text;uniqueIndex;not null"`
+	Hash       string `gorm:"type:text;not null"`
+	Roles      string `gorm:"type:text"`
+	CreatedBy  string `gorm:"type:text"`
+	Tenant     string `gorm:"type:text;not null;default:''"`
+	CreatedAt  time.Time
+	ExpiresAt  *time.Time
+	LastUsedAt *time.Time
+	RevokedAt  *time.Time
+}
+
+// TableName define o nome da tabela no banco
+func (APIKeyModel) TableName() string {
+	return "api_keys"
+}
+
+func (m *APIKeyModel) toDomain() *domain.APIKey {
+	roles := []string{}
+	if m.Roles != "" {
+		roles = strings.Split(m.Roles, ",")
+	}
+	return &domain.APIKey{
+		ID:         m.ID,
+		Name:       m.Name,
+		Prefix:     m.Prefix,
+		Hash:       m.Hash,
+		Roles:      roles,
+		CreatedBy:  m.CreatedBy,
+		Tenant:     m.Tenant,
+		CreatedAt:  m.CreatedAt,
+		ExpiresAt:  m.ExpiresAt,
+		LastUsedAt: m.LastUsedAt,
+		RevokedAt:  m.RevokedAt,
+	}
+}
+
+// APIKeyRepository gerencia a persistência de API keys usando GORM.
+// Implementa domain.APIKeyRepository.
+type APIKeyRepository struct {
+	DB *gorm.DB
+}
+
+// Garantia em tempo de compilação que APIKeyRepository implementa a interface
+var _ domain.APIKeyRepository = (*APIKeyRepository)(nil)
+
+// NewAPIKeyRepository reaproveita a conexão do Repository de produtos e migra a tabela api_keys.
+func NewAPIKeyRepository(db *gorm.DB) *APIKeyRepository {
+	if err := db.AutoMigrate(&APIKeyModel{}); err != nil {
+		panic("Falha ao rodar migration: " + err.Error())
+	}
+	return &APIKeyRepository{DB: db}
+}
+
+func (r *APIKeyRepository) Save(key *domain.APIKey) error {
+	m := APIKeyModel{
+		Name:      key.Name,
+		Prefix:    key.Prefix,
+		Hash:      key.Hash,
+		Roles:     strings.Join(key.Roles, ","),
+		CreatedBy: key.CreatedBy,
+		Tenant:    key.Tenant,
+		ExpiresAt: key.ExpiresAt,
+	}
+	if err := r.DB.Create(&m).Error; err != nil {
+		return err
+	}
+	key.ID = m.ID
+	key.CreatedAt = m.CreatedAt
+	return nil
+}
+
+func (r *APIKeyRepository) FindAll(tenant string) ([]domain.APIKey, error) {
+	var models []APIKeyModel
+	if err := r.DB.Where("tenant = ?", tenant).Order("id").Find(&models).Error; err != nil {
+		return nil, err
+	}
+
+	keys := make([]domain.APIKey, len(models))
+	for i, m := range models {
+		keys[i] = *m.toDomain()
+	}
+	return keys, nil
+}
+
+func (r *APIKeyRepository) FindByPrefix(prefix string) (*domain.APIKey, error) {
+	var m APIKeyModel
+	if err := r.DB.Where("prefix = ?", prefix).First(&m).Error; err != nil {
+		return nil, translateAPIKeyError(err)
+	}
+	return m.toDomain(), nil
+}
+
+func (r *APIKeyRepository) Revoke(tenant string, id uint, at time.Time) error {
+	result := r.DB.Model(&APIKeyModel{}).
+		Where("id = ? AND tenant = ? AND revoked_at IS NULL", id, tenant).
+		Update("revoked_at", at)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		// Já revogada é idempotente; só é erro se não existir
+		var count int64
+		if err := r.DB.Model(&APIKeyModel{}).Where("id = ? AND tenant = ?", id, tenant).Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			return domain.ErrAPIKeyNotFound
+		}
+	}
+	return nil
+}
+
+func (r *APIKeyRepository) TouchLastUsed(id uint, at time.Time) error {
+	return r.DB.Model(&APIKeyModel{}).Where("id = ?", id).Update("last_used_at", at).Error
+}
+
+// translateAPIKeyError converte erros do GORM em erros de domínio.
+func translateAPIKeyError(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return domain.ErrAPIKeyNotFound
+	}
+	return err
+}
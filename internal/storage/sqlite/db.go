@@ -1,6 +1,8 @@
 package storage
 
 import (
+	"context"
+	"errors"
 	"time"
 
 	"go-api-first-steps/internal/domain"
@@ -15,11 +17,18 @@ type ProductModel struct {
 	gorm.Model // ID, CreatedAt, UpdatedAt, DeletedAt
 
 	// Tags controlam o comportamento do GORM:
-	// unique: constraint de unicidade.
+	// uniqueIndex: unicidade composta (o nome é único dentro do tenant).
 	// not null: campo obrigatório.
 	// type:text: define o tipo da coluna no SQLite.
-	Name  string  `json:"name" gorm:"type:text;unique;not null"`
-	Price float64 `json:"price" gorm:"default:0"`
+	Tenant string  `json:"-" gorm:"type:text;not null;default:'';uniqueIndex:idx_products_tenant_name,priority:1"`
+	Name   string  `json:"name" gorm:"type:text;not null;uniqueIndex:idx_products_tenant_name,priority:2"`
+	Price  float64 `json:"price" gorm:"default:0"`
+
+	// Atributos usados nas regras ABAC. Produtos anteriores ao status ficam publicados.
+	Category  string `json:"category" gorm:"type:text;not null;default:''"`
+	Status    string `json:"status" gorm:"type:text;not null;default:'published'"`
+	CreatedBy string `json:"created_by" gorm:"type:text"`
+	UpdatedBy string `json:"updated_by" gorm:"type:text"`
 }
 
 // TableName define o nome da tabela no banco (mantém compatibilidade)
@@ -37,6 +46,10 @@ func (p *ProductModel) toDomain() *domain.Product {
 		ID:        p.ID,
 		Name:      p.Name,
 		Price:     p.Price,
+		Category:  p.Category,
+		Status:    p.Status,
+		CreatedBy: p.CreatedBy,
+		UpdatedBy: p.UpdatedBy,
 		CreatedAt: p.CreatedAt,
 		UpdatedAt: p.UpdatedAt,
 		DeletedAt: deletedAt,
@@ -45,8 +58,12 @@ func (p *ProductModel) toDomain() *domain.Product {
 
 // Repository gerencia a persistência de produtos usando GORM.
 // Implementa domain.ProductRepository.
+//
+// Toda consulta e escrita é filtrada por Tenant (vazio = tenant padrão, de
+// instalações com um único tenant). Use WithTenant para obter o repositório de outro tenant.
 type Repository struct {
-	DB *gorm.DB
+	DB     *gorm.DB
+	Tenant string
 }
 
 // Garantia em tempo de compilação que Repository implementa a interface
@@ -58,10 +75,15 @@ var _ domain.ProductRepository = (*Repository)(nil)
 //   - DBPath: Caminho para o arquivo arquivo.db ou ":memory:" para testes.
 func NewRepository(DBPath string) *Repository {
 	// Se DBPath for ":memory:", o banco roda na RAM (para testes)
-	DB, err := gorm.Open(sqlite.Open(DBPath), &gorm.Config{})
+	// TranslateError converte violações de unicidade em gorm.ErrDuplicatedKey
+	DB, err := gorm.Open(sqlite.Open(DBPath), &gorm.Config{TranslateError: true})
 	if err != nil {
 		panic("falha ao conectar no banco")
 	}
+	if err := instrument(DB); err != nil {
+		panic("falha ao registrar métricas do banco: " + err.Error())
+	}
+	// O AutoMigrate também remove a antiga constraint única global de name (pré multi-tenant)
 	if err := DB.AutoMigrate(&ProductModel{}); err != nil {
 		panic("Falha ao rodar migration: " + err.Error())
 	}
@@ -69,11 +91,35 @@ func NewRepository(DBPath string) *Repository {
 	return &Repository{DB: DB}
 }
 
-func (r *Repository) Save(name string) (*domain.Product, error) {
-	p := ProductModel{Name: name}
+// WithTenant devolve o repositório restrito a tenant, compartilhando a conexão.
+func (r *Repository) WithTenant(tenant string) domain.ProductRepository {
+	return &Repository{DB: r.DB, Tenant: tenant}
+}
+
+// WithContext devolve o repositório cujas consultas usam ctx: o span da requisição
+// vira pai dos spans do banco e o cancelamento do cliente interrompe a consulta.
+func (r *Repository) WithContext(ctx context.Context) domain.ProductRepository {
+	return &Repository{DB: r.DB.WithContext(ctx), Tenant: r.Tenant}
+}
+
+// scoped aplica o filtro de tenant; todos os métodos partem daqui.
+func (r *Repository) scoped() *gorm.DB {
+	return r.DB.Where("tenant = ?", r.Tenant)
+}
+
+func (r *Repository) Save(product domain.Product) (*domain.Product, error) {
+	p := ProductModel{
+		Tenant:    r.Tenant,
+		Name:      product.Name,
+		Price:     product.Price,
+		Category:  product.Category,
+		Status:    product.Status,
+		CreatedBy: product.CreatedBy,
+		UpdatedBy: product.CreatedBy,
+	}
 	result := r.DB.Create(&p)
 	if result.Error != nil {
-		return nil, result.Error
+		return nil, translateError(result.Error)
 	}
 	return p.toDomain(), nil
 }
@@ -82,7 +128,7 @@ func (r *Repository) Save(name string) (*domain.Product, error) {
 func (r *Repository) FindAll(page, pageSize int) ([]domain.Product, error) {
 	var models []ProductModel
 	offset := (page - 1) * pageSize
-	result := r.DB.Offset(offset).Limit(pageSize).Find(&models)
+	result := r.scoped().Offset(offset).Limit(pageSize).Find(&models)
 	if result.Error != nil {
 		return nil, result.Error
 	}
@@ -97,22 +143,45 @@ func (r *Repository) FindAll(page, pageSize int) ([]domain.Product, error) {
 // FindByID busca um produto pelo ID.
 func (r *Repository) FindByID(id uint) (*domain.Product, error) {
 	var p ProductModel
-	if err := r.DB.First(&p, id).Error; err != nil {
-		return nil, err
+	if err := r.scoped().First(&p, id).Error; err != nil {
+		return nil, translateError(err)
 	}
 	return p.toDomain(), nil
 }
 
-func (r *Repository) Update(id uint, newName string) error {
+func (r *Repository) Update(product *domain.Product) error {
 	var p ProductModel
-	// Primeiro busca, depois atualiza
-	if err := r.DB.First(&p, id).Error; err != nil {
-		return err
+	// Primeiro busca (no tenant), depois atualiza
+	if err := r.scoped().First(&p, product.ID).Error; err != nil {
+		return translateError(err)
 	}
-	p.Name = newName
-	return r.DB.Save(&p).Error
+	return translateError(r.scoped().Model(&p).Updates(map[string]any{
+		"name":       product.Name,
+		"category":   product.Category,
+		"status":     product.Status,
+		"updated_by": product.UpdatedBy,
+	}).Error)
 }
 
 func (r *Repository) Delete(id uint) error {
-	return r.DB.Delete(&ProductModel{}, id).Error
+	result := r.scoped().Delete(&ProductModel{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	// Nenhuma linha: o produto não existe, já foi removido ou é de outro tenant
+	if result.RowsAffected == 0 {
+		return domain.ErrProductNotFound
+	}
+	return nil
+}
+
+// translateError converte erros do GORM em erros de domínio.
+func translateError(err error) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return domain.ErrProductNotFound
+	case errors.Is(err, gorm.ErrDuplicatedKey):
+		return domain.ErrDuplicateProductName
+	}
+	return err
 }
@@ -0,0 +1,88 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go-api-first-steps/internal/metrics"
+	"go-api-first-steps/internal/tracing"
+
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+const (
+	metricsStartKey = "metrics:start"
+	traceSpanKey    = "tracing:span"
+)
+
+// instrument registra callbacks do GORM que medem cada operação no banco
+// (db_query_duration_seconds e db_query_errors_total por operação e tabela) e
+// abrem um span por consulta, filho do span em Statement.Context (veja WithContext).
+func instrument(db *gorm.DB) error {
+	cb := db.Callback()
+	return errors.Join(
+		cb.Create().Before("gorm:create").Register("instrument:before_create", start("create")),
+		cb.Create().After("gorm:create").Register("instrument:after_create", observe("create")),
+		cb.Query().Before("gorm:query").Register("instrument:before_query", start("query")),
+		cb.Query().After("gorm:query").Register("instrument:after_query", observe("query")),
+		cb.Update().Before("gorm:update").Register("instrument:before_update", start("update")),
+		cb.Update().After("gorm:update").Register("instrument:after_update", observe("update")),
+		cb.Delete().Before("gorm:delete").Register("instrument:before_delete", start("delete")),
+		cb.Delete().After("gorm:delete").Register("instrument:after_delete", observe("delete")),
+		cb.Row().Before("gorm:row").Register("instrument:before_row", start("row")),
+		cb.Row().After("gorm:row").Register("instrument:after_row", observe("row")),
+		cb.Raw().Before("gorm:raw").Register("instrument:before_raw", start("raw")),
+		cb.Raw().After("gorm:raw").Register("instrument:after_raw", observe("raw")),
+	)
+}
+
+func start(operation string) func(*gorm.DB) {
+	return func(tx *gorm.DB) {
+		tx.InstanceSet(metricsStartKey, time.Now())
+
+		ctx := tx.Statement.Context
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		_, span := tracing.Tracer().Start(ctx, "db."+operation,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(semconv.DBSystemNameSQLite, semconv.DBOperationName(operation)),
+		)
+		tx.InstanceSet(traceSpanKey, span)
+	}
+}
+
+func observe(operation string) func(*gorm.DB) {
+	return func(tx *gorm.DB) {
+		began, ok := tx.InstanceGet(metricsStartKey)
+		if !ok {
+			return
+		}
+		table := tx.Statement.Table
+		if table == "" {
+			table = "unknown"
+		}
+		if v, ok := tx.InstanceGet(traceSpanKey); ok {
+			endSpan(v.(trace.Span), tx, table)
+		}
+		metrics.DBQueryDuration.Observe(time.Since(began.(time.Time)).Seconds(), operation, table)
+		// Registro não encontrado é resposta válida (404), não falha do banco
+		if tx.Error != nil && !errors.Is(tx.Error, gorm.ErrRecordNotFound) {
+			metrics.DBQueryErrors.Inc(operation, table)
+		}
+	}
+}
+
+func endSpan(span trace.Span, tx *gorm.DB, table string) {
+	// SQL com placeholders: os valores (tx.Statement.Vars) não vão para o trace
+	span.SetAttributes(semconv.DBCollectionName(table), semconv.DBQueryText(tx.Statement.SQL.String()))
+	if tx.Error != nil && !errors.Is(tx.Error, gorm.ErrRecordNotFound) {
+		span.RecordError(tx.Error)
+		span.SetStatus(codes.Error, tx.Error.Error())
+	}
+	span.End()
+}
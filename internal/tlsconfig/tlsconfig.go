@@ -0,0 +1,218 @@
+// Package tlsconfig monta o *tls.Config do servidor (HTTP e gRPC) a partir de
+// arquivos PEM, com recarga do certificado e da CA de clientes quando os arquivos
+// mudam (rotação sem restart) e verificação opcional de certificados de cliente (mTLS).
+package tlsconfig
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Modos de verificação do certificado de cliente (Options.ClientAuth)
+const (
+	ClientAuthNone     = "none"     // Não pede certificado
+	ClientAuthOptional = "optional" // Verifica se o cliente enviar (tokens continuam aceitos)
+	ClientAuthRequire  = "require"  // Exige certificado válido em toda conexão
+)
+
+// Options descreve a configuração TLS do servidor.
+type Options struct {
+	CertFile     string
+	KeyFile      string
+	ClientCAFile string // Bundle PEM das CAs aceitas para certificados de cliente
+	ClientAuth   string // none, optional ou require (default: optional com ClientCAFile, senão none)
+	MinVersion   string // "1.2" (default) ou "1.3"
+	CipherSuites string // Nomes separados por vírgula (só TLS 1.2; vazio = padrão do Go)
+}
+
+// Reloader guarda o certificado do servidor e a CA de clientes atuais e os
+// entrega a cada handshake, para que a rotação dos arquivos valha sem restart.
+type Reloader struct {
+	opts       Options
+	clientAuth tls.ClientAuthType
+	minVersion uint16
+	ciphers    []uint16
+
+	mu        sync.RWMutex
+	cert      *tls.Certificate
+	clientCAs *x509.CertPool
+	modTime   map[string]time.Time
+}
+
+// New valida as opções e carrega os arquivos.
+func New(opts Options) (*Reloader, error) {
+	if opts.CertFile == "" || opts.KeyFile == "" {
+		return nil, errors.New("TLS requer arquivo de certificado e de chave")
+	}
+
+	r := &Reloader{opts: opts}
+
+	mode := strings.ToLower(strings.TrimSpace(opts.ClientAuth))
+	if mode == "" {
+		mode = ClientAuthNone
+		if opts.ClientCAFile != "" {
+			mode = ClientAuthOptional
+		}
+	}
+	switch mode {
+	case ClientAuthNone:
+		r.clientAuth = tls.NoClientCert
+	case ClientAuthOptional:
+		r.clientAuth = tls.VerifyClientCertIfGiven
+	case ClientAuthRequire:
+		r.clientAuth = tls.RequireAndVerifyClientCert
+	default:
+		return nil, fmt.Errorf("modo de certificado de cliente inválido %q (use none, optional ou require)", opts.ClientAuth)
+	}
+	if r.clientAuth != tls.NoClientCert && opts.ClientCAFile == "" {
+		return nil, fmt.Errorf("certificado de cliente %q requer o bundle da CA de clientes", mode)
+	}
+
+	switch strings.TrimSpace(opts.MinVersion) {
+	case "", "1.2":
+		r.minVersion = tls.VersionTLS12
+	case "1.3":
+		r.minVersion = tls.VersionTLS13
+	default:
+		return nil, fmt.Errorf("versão mínima de TLS inválida %q (use 1.2 ou 1.3)", opts.MinVersion)
+	}
+
+	ciphers, err := ParseCipherSuites(opts.CipherSuites)
+	if err != nil {
+		return nil, err
+	}
+	r.ciphers = ciphers
+
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// ParseCipherSuites converte nomes (ex: TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256) nos IDs
+// do crypto/tls. Suites inseguras não são aceitas.
+func ParseCipherSuites(s string) ([]uint16, error) {
+	byName := make(map[string]uint16)
+	for _, suite := range tls.CipherSuites() {
+		byName[suite.Name] = suite.ID
+	}
+
+	var ids []uint16
+	for _, name := range strings.Split(s, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		id, ok := byName[name]
+		if !ok {
+			return nil, fmt.Errorf("cipher suite desconhecida ou insegura %q", name)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// Reload relê certificado, chave e CA de clientes. Se algum for inválido, os atuais são mantidos.
+func (r *Reloader) Reload() error {
+	modTime := make(map[string]time.Time)
+
+	cert, err := tls.LoadX509KeyPair(r.opts.CertFile, r.opts.KeyFile)
+	if err != nil {
+		return fmt.Errorf("falha ao carregar certificado TLS: %w", err)
+	}
+	for _, path := range []string{r.opts.CertFile, r.opts.KeyFile} {
+		if info, err := os.Stat(path); err == nil {
+			modTime[path] = info.ModTime()
+		}
+	}
+
+	var pool *x509.CertPool
+	if r.opts.ClientCAFile != "" {
+		data, err := os.ReadFile(r.opts.ClientCAFile)
+		if err != nil {
+			return fmt.Errorf("falha ao ler CA de clientes: %w", err)
+		}
+		pool = x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(data) {
+			return fmt.Errorf("CA de clientes sem certificados válidos: %s", r.opts.ClientCAFile)
+		}
+		if info, err := os.Stat(r.opts.ClientCAFile); err == nil {
+			modTime[r.opts.ClientCAFile] = info.ModTime()
+		}
+	}
+
+	r.mu.Lock()
+	r.cert, r.clientCAs, r.modTime = &cert, pool, modTime
+	r.mu.Unlock()
+	return nil
+}
+
+// Config devolve o *tls.Config do servidor. Certificado e CA de clientes são
+// lidos a cada handshake, então a mesma instância continua válida após Reload.
+func (r *Reloader) Config() *tls.Config {
+	base := &tls.Config{
+		MinVersion:   r.minVersion,
+		CipherSuites: r.ciphers,
+		ClientAuth:   r.clientAuth,
+		NextProtos:   []string{"h2", "http/1.1"}, // HTTP/2 (inclusive gRPC) e HTTP/1.1
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			r.mu.RLock()
+			defer r.mu.RUnlock()
+			return r.cert, nil
+		},
+	}
+
+	cfg := base.Clone()
+	cfg.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		perConn := base.Clone()
+		r.mu.RLock()
+		perConn.ClientCAs = r.clientCAs
+		r.mu.RUnlock()
+		return perConn, nil
+	}
+	return cfg
+}
+
+// Watch verifica os arquivos a cada interval e recarrega quando algum muda.
+// Roda até ctx ser cancelado; deve ser chamado em goroutine.
+func (r *Reloader) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if !r.changed() {
+				continue
+			}
+			if err := r.Reload(); err != nil {
+				slog.ErrorContext(ctx, "Certificados TLS inválidos, mantendo os anteriores", "error", err)
+				continue
+			}
+			slog.InfoContext(ctx, "Certificados TLS recarregados")
+		}
+	}
+}
+
+func (r *Reloader) changed() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for path, mod := range r.modTime {
+		info, err := os.Stat(path)
+		if err == nil && !info.ModTime().Equal(mod) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,250 @@
+package tlsconfig_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"io"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go-api-first-steps/internal/tlsconfig"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testCert struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+// issue gera um certificado assinado por parent (nil = autoassinado, vira CA).
+func issue(t *testing.T, cn string, parent *testCert, usage x509.ExtKeyUsage) *testCert {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+		DNSNames:     []string{cn},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	signer, signerKey := tmpl, key
+	if parent == nil {
+		tmpl.IsCA = true
+		tmpl.BasicConstraintsValid = true
+		tmpl.KeyUsage |= x509.KeyUsageCertSign
+	} else {
+		signer, signerKey = parent.cert, parent.key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, signer, &key.PublicKey, signerKey)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return &testCert{cert: cert, key: key}
+}
+
+func (c *testCert) write(t *testing.T, certPath, keyPath string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: c.cert.Raw}), 0o600))
+	if keyPath == "" {
+		return
+	}
+	der, err := x509.MarshalECPrivateKey(c.key)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}), 0o600))
+}
+
+func (c *testCert) tlsCert() tls.Certificate {
+	return tls.Certificate{Certificate: [][]byte{c.cert.Raw}, PrivateKey: c.key}
+}
+
+type fixture struct {
+	dir, certFile, keyFile, caFile string
+	ca                             *testCert
+}
+
+func newFixture(t *testing.T) *fixture {
+	t.Helper()
+	dir := t.TempDir()
+	f := &fixture{
+		dir:      dir,
+		certFile: filepath.Join(dir, "server.crt"),
+		keyFile:  filepath.Join(dir, "server.key"),
+		caFile:   filepath.Join(dir, "clients-ca.crt"),
+		ca:       issue(t, "test-ca", nil, x509.ExtKeyUsageAny),
+	}
+	issue(t, "localhost", f.ca, x509.ExtKeyUsageServerAuth).write(t, f.certFile, f.keyFile)
+	f.ca.write(t, f.caFile, "")
+	return f
+}
+
+// handshake abre um listener com cfg e devolve o certificado do servidor e o
+// estado da conexão visto pelo servidor.
+func handshake(t *testing.T, cfg *tls.Config, clientCert *tls.Certificate) (*x509.Certificate, tls.ConnectionState, error) {
+	t.Helper()
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", cfg)
+	require.NoError(t, err)
+	defer ln.Close()
+
+	serverState := make(chan tls.ConnectionState, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			close(serverState)
+			return
+		}
+		defer conn.Close()
+		tlsConn := conn.(*tls.Conn)
+		if tlsConn.Handshake() != nil {
+			close(serverState)
+			return
+		}
+		serverState <- tlsConn.ConnectionState()
+	}()
+
+	clientCfg := &tls.Config{InsecureSkipVerify: true}
+	if clientCert != nil {
+		// Envia o certificado mesmo se a CA dele não estiver entre as anunciadas pelo servidor
+		clientCfg.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return clientCert, nil
+		}
+	}
+	conn, err := tls.Dial("tcp", ln.Addr().String(), clientCfg)
+	if err != nil {
+		return nil, tls.ConnectionState{}, err
+	}
+	defer conn.Close()
+	// No TLS 1.3 o servidor só rejeita o certificado do cliente após o handshake do cliente
+	if _, err := conn.Read(make([]byte, 1)); err != nil && !errors.Is(err, io.EOF) {
+		return nil, tls.ConnectionState{}, err
+	}
+
+	state, ok := <-serverState
+	if !ok {
+		return nil, tls.ConnectionState{}, assert.AnError
+	}
+	return conn.ConnectionState().PeerCertificates[0], state, nil
+}
+
+func TestNew_InvalidOptions(t *testing.T) {
+	f := newFixture(t)
+
+	tests := map[string]tlsconfig.Options{
+		"sem chave":           {CertFile: f.certFile},
+		"modo desconhecido":   {CertFile: f.certFile, KeyFile: f.keyFile, ClientCAFile: f.caFile, ClientAuth: "sometimes"},
+		"require sem CA":      {CertFile: f.certFile, KeyFile: f.keyFile, ClientAuth: tlsconfig.ClientAuthRequire},
+		"versão inválida":     {CertFile: f.certFile, KeyFile: f.keyFile, MinVersion: "1.0"},
+		"cipher desconhecida": {CertFile: f.certFile, KeyFile: f.keyFile, CipherSuites: "TLS_RSA_WITH_RC4_128_SHA"},
+		"arquivo inexistente": {CertFile: filepath.Join(f.dir, "nope.crt"), KeyFile: f.keyFile},
+		"CA inválida":         {CertFile: f.certFile, KeyFile: f.keyFile, ClientCAFile: f.keyFile},
+	}
+	for name, opts := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := tlsconfig.New(opts)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestParseCipherSuites(t *testing.T) {
+	ids, err := tlsconfig.ParseCipherSuites("TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256, TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256")
+	require.NoError(t, err)
+	assert.Equal(t, []uint16{tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256, tls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256}, ids)
+
+	ids, err = tlsconfig.ParseCipherSuites("")
+	require.NoError(t, err)
+	assert.Empty(t, ids)
+}
+
+func TestReloader_ClientAuthModes(t *testing.T) {
+	f := newFixture(t)
+	client := issue(t, "batch-estoque", f.ca, x509.ExtKeyUsageClientAuth).tlsCert()
+	stranger := issue(t, "intruso", issue(t, "other-ca", nil, x509.ExtKeyUsageAny), x509.ExtKeyUsageClientAuth).tlsCert()
+
+	t.Run("optional", func(t *testing.T) {
+		r, err := tlsconfig.New(tlsconfig.Options{CertFile: f.certFile, KeyFile: f.keyFile, ClientCAFile: f.caFile})
+		require.NoError(t, err)
+
+		_, state, err := handshake(t, r.Config(), nil)
+		require.NoError(t, err)
+		assert.Empty(t, state.VerifiedChains, "sem certificado o handshake segue (token continua aceito)")
+
+		_, state, err = handshake(t, r.Config(), &client)
+		require.NoError(t, err)
+		require.NotEmpty(t, state.VerifiedChains)
+		assert.Equal(t, "batch-estoque", state.VerifiedChains[0][0].Subject.CommonName)
+
+		_, _, err = handshake(t, r.Config(), &stranger)
+		assert.Error(t, err, "certificado de outra CA é recusado")
+	})
+
+	t.Run("require", func(t *testing.T) {
+		r, err := tlsconfig.New(tlsconfig.Options{
+			CertFile: f.certFile, KeyFile: f.keyFile, ClientCAFile: f.caFile,
+			ClientAuth: tlsconfig.ClientAuthRequire, MinVersion: "1.3",
+		})
+		require.NoError(t, err)
+
+		_, _, err = handshake(t, r.Config(), nil)
+		assert.Error(t, err)
+
+		_, state, err := handshake(t, r.Config(), &client)
+		require.NoError(t, err)
+		assert.Equal(t, uint16(tls.VersionTLS13), state.Version)
+	})
+}
+
+func TestReloader_ReloadRotatesCertificates(t *testing.T) {
+	f := newFixture(t)
+	r, err := tlsconfig.New(tlsconfig.Options{CertFile: f.certFile, KeyFile: f.keyFile, ClientCAFile: f.caFile})
+	require.NoError(t, err)
+	cfg := r.Config()
+
+	before, _, err := handshake(t, cfg, nil)
+	require.NoError(t, err)
+
+	// Rotação: novo certificado do servidor e nova CA de clientes
+	rotated := issue(t, "localhost", f.ca, x509.ExtKeyUsageServerAuth)
+	rotated.write(t, f.certFile, f.keyFile)
+	newCA := issue(t, "new-ca", nil, x509.ExtKeyUsageAny)
+	newCA.write(t, f.caFile, "")
+	require.NoError(t, r.Reload())
+
+	after, _, err := handshake(t, cfg, nil)
+	require.NoError(t, err)
+	assert.NotEqual(t, before.SerialNumber, after.SerialNumber)
+	assert.Equal(t, rotated.cert.SerialNumber, after.SerialNumber)
+
+	oldClient := issue(t, "batch-estoque", f.ca, x509.ExtKeyUsageClientAuth).tlsCert()
+	_, _, err = handshake(t, cfg, &oldClient)
+	assert.Error(t, err, "clientes da CA antiga deixam de ser aceitos")
+	newClient := issue(t, "batch-estoque", newCA, x509.ExtKeyUsageClientAuth).tlsCert()
+	_, _, err = handshake(t, cfg, &newClient)
+	assert.NoError(t, err)
+
+	// Arquivo inválido: Reload falha e o certificado atual é mantido
+	require.NoError(t, os.WriteFile(f.certFile, []byte("lixo"), 0o600))
+	assert.Error(t, r.Reload())
+	kept, _, err := handshake(t, cfg, nil)
+	require.NoError(t, err)
+	assert.Equal(t, rotated.cert.SerialNumber, kept.SerialNumber)
+}
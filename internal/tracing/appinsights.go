@@ -0,0 +1,157 @@
+package tracing
+
+import (
+	"context"
+
+	"github.com/microsoft/ApplicationInsights-Go/appinsights"
+	"github.com/microsoft/ApplicationInsights-Go/appinsights/contracts"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// NewAppInsightsExporter converte os spans em telemetria do Application Insights:
+// spans de servidor (HTTP e gRPC) viram RequestTelemetry, spans de cliente (GORM,
+// chamadas OIDC) viram RemoteDependencyTelemetry e os internos (auth.verify,
+// product.*) viram dependências "InProc", para o portal montar a árvore completa.
+// operation_Id é o trace ID e operation_ParentId o span pai, os mesmos IDs que
+// o AzureHandler grava nos logs.
+//
+// Os atributos (URL, IP, usuário, SQL) e a descrição de erro passam por redactor
+// antes de virar telemetria, como os logs passam pelo RedactHandler; nil = sem redação.
+//
+// O envio fica a cargo do canal do client (pkg/logger); Shutdown não o fecha.
+func NewAppInsightsExporter(client appinsights.TelemetryClient, redactor PropertyRedactor) sdktrace.SpanExporter {
+	return &appInsightsExporter{client: client, redactor: redactor}
+}
+
+// PropertyRedactor redige as propriedades da telemetria (ex: *logger.Redactor);
+// ok=false remove a propriedade.
+type PropertyRedactor interface {
+	Property(key, value string) (redacted string, ok bool)
+}
+
+type appInsightsExporter struct {
+	client   appinsights.TelemetryClient
+	redactor PropertyRedactor
+}
+
+func (e *appInsightsExporter) ExportSpans(_ context.Context, spans []sdktrace.ReadOnlySpan) error {
+	for _, span := range spans {
+		e.client.Track(e.spanTelemetry(span))
+	}
+	return nil
+}
+
+func (e *appInsightsExporter) Shutdown(context.Context) error {
+	return nil
+}
+
+func (e *appInsightsExporter) spanTelemetry(span sdktrace.ReadOnlySpan) appinsights.Telemetry {
+	attrs := e.redact(attributeMap(span.Attributes()))
+	success := span.Status().Code != codes.Error
+
+	var base *appinsights.BaseTelemetry
+	var telemetry appinsights.Telemetry
+	switch span.SpanKind() {
+	case trace.SpanKindServer, trace.SpanKindConsumer:
+		req := appinsights.NewRequestTelemetry("", "", 0, "")
+		req.Name = span.Name()
+		req.Id = span.SpanContext().SpanID().String()
+		req.Url = attrs[string(semconv.URLPathKey)]
+		req.ResponseCode = resultCode(attrs)
+		req.Success = success
+		req.Source = attrs[string(semconv.ClientAddressKey)]
+		req.MarkTime(span.StartTime(), span.EndTime())
+		req.Tags.Operation().SetName(span.Name())
+		base, telemetry = &req.BaseTelemetry, req
+
+	default:
+		dep := appinsights.NewRemoteDependencyTelemetry(span.Name(), dependencyType(span.SpanKind(), attrs), dependencyTarget(attrs), success)
+		dep.Id = span.SpanContext().SpanID().String()
+		dep.ResultCode = resultCode(attrs)
+		dep.Data = attrs[string(semconv.DBQueryTextKey)]
+		if dep.Data == "" {
+			dep.Data = attrs[string(semconv.URLFullKey)]
+		}
+		dep.MarkTime(span.StartTime(), span.EndTime())
+		base, telemetry = &dep.BaseTelemetry, dep
+	}
+
+	for k, v := range attrs {
+		base.Properties[k] = v
+	}
+	if status := span.Status(); status.Code == codes.Error && status.Description != "" {
+		if desc, ok := e.property("error", status.Description); ok {
+			base.Properties["error"] = desc
+		}
+	}
+	setOperation(base.Tags, span)
+	if role, ok := span.Resource().Set().Value(semconv.ServiceNameKey); ok {
+		base.Tags.Cloud().SetRole(role.AsString())
+	}
+	return telemetry
+}
+
+func setOperation(tags contracts.ContextTags, span sdktrace.ReadOnlySpan) {
+	tags.Operation().SetId(span.SpanContext().TraceID().String())
+	if parent := span.Parent(); parent.IsValid() {
+		tags.Operation().SetParentId(parent.SpanID().String())
+	}
+}
+
+// resultCode é o status HTTP ou o código gRPC do span, se houver.
+func resultCode(attrs map[string]string) string {
+	if code, ok := attrs[string(semconv.HTTPResponseStatusCodeKey)]; ok {
+		return code
+	}
+	return attrs[string(semconv.RPCGRPCStatusCodeKey)]
+}
+
+func dependencyType(kind trace.SpanKind, attrs map[string]string) string {
+	switch {
+	case kind != trace.SpanKindClient && kind != trace.SpanKindProducer:
+		return "InProc"
+	case attrs[string(semconv.DBSystemNameKey)] != "":
+		return "SQL"
+	case attrs[string(semconv.HTTPRequestMethodKey)] != "":
+		return "HTTP"
+	}
+	return "Other"
+}
+
+func dependencyTarget(attrs map[string]string) string {
+	if host := attrs[string(semconv.ServerAddressKey)]; host != "" {
+		return host
+	}
+	return attrs[string(semconv.DBSystemNameKey)]
+}
+
+// redact aplica o redactor a cada propriedade, removendo as que ele descarta.
+func (e *appInsightsExporter) redact(props map[string]string) map[string]string {
+	for k, v := range props {
+		if redacted, ok := e.property(k, v); ok {
+			props[k] = redacted
+		} else {
+			delete(props, k)
+		}
+	}
+	return props
+}
+
+func (e *appInsightsExporter) property(key, value string) (string, bool) {
+	if e.redactor == nil {
+		return value, true
+	}
+	return e.redactor.Property(key, value)
+}
+
+func attributeMap(kvs []attribute.KeyValue) map[string]string {
+	out := make(map[string]string, len(kvs))
+	for _, kv := range kvs {
+		out[string(kv.Key)] = kv.Value.Emit()
+	}
+	return out
+}
@@ -0,0 +1,95 @@
+package tracing_test
+
+import (
+	"context"
+	"testing"
+
+	"go-api-first-steps/internal/mockappinsights"
+	"go-api-first-steps/internal/tracing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestAppInsightsExporter(t *testing.T) {
+	ingest := mockappinsights.Start(t)
+	client := ingest.NewClient()
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithSyncer(tracing.NewAppInsightsExporter(client, nil)),
+		sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceName("api-test"))),
+	)
+	tracer := provider.Tracer("test")
+
+	ctx, req := tracer.Start(context.Background(), "GET /api/v1/products", trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(semconv.URLPath("/api/v1/products"), semconv.HTTPResponseStatusCode(500)))
+	ctx, svc := tracer.Start(ctx, "product.ListProducts")
+	_, db := tracer.Start(ctx, "db.query", trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(semconv.DBSystemNameSQLite, semconv.DBQueryText("SELECT * FROM `products`")))
+	db.SetStatus(codes.Error, "database is locked")
+	db.End()
+	svc.End()
+	req.SetStatus(codes.Error, "Internal Server Error")
+	req.End()
+
+	_, oidc := tracer.Start(context.Background(), "HTTP GET", trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(semconv.HTTPRequestMethodKey.String("GET"), semconv.ServerAddress("idp.local"), semconv.HTTPResponseStatusCode(200)))
+	oidc.End()
+
+	ingest.Flush(client)
+
+	requests := ingest.Items("RequestData")
+	require.Len(t, requests, 1)
+	r := requests[0]
+	traceID := req.SpanContext().TraceID().String()
+	assert.Equal(t, "GET /api/v1/products", r.Field("name"))
+	assert.Equal(t, req.SpanContext().SpanID().String(), r.Field("id"))
+	assert.Equal(t, "500", r.Field("responseCode"))
+	assert.Equal(t, "false", r.Field("success"))
+	assert.Equal(t, traceID, r.Tags["ai.operation.id"])
+	assert.Equal(t, "api-test", r.Tags["ai.cloud.role"])
+
+	deps := map[string]mockappinsights.Envelope{}
+	for _, d := range ingest.Items("RemoteDependencyData") {
+		deps[d.Field("name")] = d
+	}
+	require.Len(t, deps, 3)
+
+	assert.Equal(t, "InProc", deps["product.ListProducts"].Field("type"))
+	assert.Equal(t, r.Field("id"), deps["product.ListProducts"].Tags["ai.operation.parentId"])
+
+	sql := deps["db.query"]
+	assert.Equal(t, "SQL", sql.Field("type"))
+	assert.Equal(t, "sqlite", sql.Field("target"))
+	assert.Equal(t, "SELECT * FROM `products`", sql.Field("data"))
+	assert.Equal(t, "false", sql.Field("success"))
+	assert.Equal(t, traceID, sql.Tags["ai.operation.id"])
+	assert.Equal(t, svc.SpanContext().SpanID().String(), sql.Tags["ai.operation.parentId"])
+	assert.Equal(t, "database is locked", sql.Properties()["error"])
+
+	http := deps["HTTP GET"]
+	assert.Equal(t, "HTTP", http.Field("type"))
+	assert.Equal(t, "idp.local", http.Field("target"))
+	assert.Equal(t, "200", http.Field("resultCode"))
+}
+
+func TestSetup_SendsSpansToAppInsights(t *testing.T) {
+	ingest := mockappinsights.Start(t)
+	client := ingest.NewClient()
+	prev := otel.GetTracerProvider()
+	t.Cleanup(func() { otel.SetTracerProvider(prev) })
+	shutdown, err := tracing.Setup(context.Background(), tracing.Options{ServiceName: "api-test", SampleRatio: 1, AppInsights: client})
+	require.NoError(t, err)
+
+	_, span := tracing.Tracer().Start(context.Background(), "GET /health", trace.WithSpanKind(trace.SpanKindServer))
+	span.End()
+	require.NoError(t, shutdown(context.Background()))
+	ingest.Flush(client)
+
+	require.Len(t, ingest.Items("RequestData"), 1)
+}
@@ -0,0 +1,142 @@
+// Package tracing configura o OpenTelemetry: TracerProvider global, propagação W3C
+// (traceparent/tracestate + baggage) e exportação OTLP/HTTP. Sem endpoint
+// configurado, os spans continuam sendo criados (IDs nos logs e propagação para
+// outros serviços), só não são exportados.
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/microsoft/ApplicationInsights-Go/appinsights"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TracerName identifica a instrumentação desta API nos spans.
+const TracerName = "go-api-first-steps"
+
+// LegacyTraceHeader é o header de trace anterior ao W3C, mantido por compatibilidade.
+const LegacyTraceHeader = "X-Trace-ID"
+
+// Options descreve como os spans são amostrados e exportados.
+type Options struct {
+	ServiceName string
+	Endpoint    string                // URL OTLP/HTTP (ex: http://localhost:4318). Vazio = sem exportação
+	SampleRatio float64               // Fração de traces novos amostrados (0 a 1); traces recebidos seguem o pai
+	Exporter    sdktrace.SpanExporter // Alternativa ao Endpoint (ex: tracetest.InMemoryExporter)
+
+	// AppInsights, se não for nil, também recebe os spans como requests/dependências,
+	// com as propriedades redigidas por Redactor (ex: logger.Redaction())
+	AppInsights appinsights.TelemetryClient
+	Redactor    PropertyRedactor
+}
+
+// Tracer devolve o tracer da API a partir do provider global.
+func Tracer() trace.Tracer {
+	return otel.Tracer(TracerName)
+}
+
+// Setup instala o TracerProvider e o propagador globais. A função devolvida
+// exporta os spans pendentes e deve ser chamada no shutdown.
+func Setup(ctx context.Context, opts Options) (func(context.Context) error, error) {
+	if opts.SampleRatio < 0 || opts.SampleRatio > 1 {
+		return nil, fmt.Errorf("taxa de amostragem inválida %v (use 0 a 1)", opts.SampleRatio)
+	}
+
+	exporter := opts.Exporter
+	if exporter == nil && opts.Endpoint != "" {
+		var err error
+		exporter, err = otlptracehttp.New(ctx, otlptracehttp.WithEndpointURL(opts.Endpoint))
+		if err != nil {
+			return nil, fmt.Errorf("falha ao criar exportador OTLP: %w", err)
+		}
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(semconv.ServiceName(opts.ServiceName)))
+	if err != nil {
+		return nil, fmt.Errorf("falha ao montar resource do tracing: %w", err)
+	}
+
+	providerOpts := []sdktrace.TracerProviderOption{
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(opts.SampleRatio))),
+	}
+	if exporter != nil {
+		providerOpts = append(providerOpts, sdktrace.WithBatcher(exporter))
+	}
+	if opts.AppInsights != nil {
+		providerOpts = append(providerOpts, sdktrace.WithBatcher(NewAppInsightsExporter(opts.AppInsights, opts.Redactor)))
+	}
+	provider := sdktrace.NewTracerProvider(providerOpts...)
+
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(Propagator())
+	return provider.Shutdown, nil
+}
+
+// Propagator é o propagador W3C usado em HTTP, gRPC e no SDK cliente.
+func Propagator() propagation.TextMapPropagator {
+	return propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
+}
+
+// Extract lê traceparent/tracestate dos headers. Sem traceparent, aceita o
+// X-Trace-ID legado quando ele é um trace ID válido (32 hex, ou UUID com hífens):
+// vira o pai remoto, para que clientes antigos continuem correlacionando.
+func Extract(ctx context.Context, carrier propagation.TextMapCarrier) context.Context {
+	ctx = otel.GetTextMapPropagator().Extract(ctx, carrier)
+	if trace.SpanContextFromContext(ctx).IsValid() {
+		return ctx
+	}
+
+	legacy := carrier.Get(LegacyTraceHeader)
+	if legacy == "" {
+		legacy = carrier.Get(strings.ToLower(LegacyTraceHeader)) // metadata gRPC é minúscula
+	}
+	traceID, err := trace.TraceIDFromHex(strings.ReplaceAll(legacy, "-", ""))
+	if err != nil {
+		return ctx
+	}
+	// O span pai não existe de fato; o ID aleatório só torna o contexto válido
+	var spanID trace.SpanID
+	_, _ = rand.Read(spanID[:])
+	return trace.ContextWithRemoteSpanContext(ctx, trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: trace.FlagsSampled,
+		Remote:     true,
+	}))
+}
+
+// Inject escreve traceparent/tracestate do span atual nos headers.
+func Inject(ctx context.Context, carrier propagation.TextMapCarrier) {
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+}
+
+// LegacyTraceID é o valor de X-Trace-ID da resposta: o enviado pelo cliente, sem
+// alterações, ou o trace ID W3C do span em ctx.
+func LegacyTraceID(ctx context.Context, received string) string {
+	if received != "" {
+		return received
+	}
+	return TraceID(ctx)
+}
+
+// TraceID devolve o trace ID do span em ctx. Sem span válido (tracing não
+// configurado), gera um ID aleatório no mesmo formato, para os logs continuarem correlacionáveis.
+func TraceID(ctx context.Context) string {
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		return sc.TraceID().String()
+	}
+	var id [16]byte
+	_, _ = rand.Read(id[:])
+	return hex.EncodeToString(id[:])
+}
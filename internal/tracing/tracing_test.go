@@ -0,0 +1,65 @@
+package tracing_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"go-api-first-steps/internal/tracing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestSetup_RejectsInvalidRatio(t *testing.T) {
+	_, err := tracing.Setup(context.Background(), tracing.Options{SampleRatio: 1.5})
+	assert.Error(t, err)
+}
+
+func TestExtract(t *testing.T) {
+	tracing.StartInMemory(t)
+
+	tests := []struct {
+		name    string
+		headers map[string]string
+		traceID string // vazio = sem pai remoto
+	}{
+		{"traceparent", map[string]string{"Traceparent": "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"}, "4bf92f3577b34da6a3ce929d0e0e4736"},
+		{"traceparent vence o legado", map[string]string{
+			"Traceparent": "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			"X-Trace-Id":  "0af7651916cd43dd8448eb211c80319c",
+		}, "4bf92f3577b34da6a3ce929d0e0e4736"},
+		{"legado em hex", map[string]string{"X-Trace-Id": "0af7651916cd43dd8448eb211c80319c"}, "0af7651916cd43dd8448eb211c80319c"},
+		{"legado UUID", map[string]string{"X-Trace-Id": "0af76519-16cd-43dd-8448-eb211c80319c"}, "0af7651916cd43dd8448eb211c80319c"},
+		{"legado inválido", map[string]string{"X-Trace-Id": "trace-abc"}, ""},
+		{"sem headers", nil, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := http.Header{}
+			for k, v := range tt.headers {
+				h.Set(k, v)
+			}
+			sc := trace.SpanContextFromContext(tracing.Extract(context.Background(), propagation.HeaderCarrier(h)))
+			if tt.traceID == "" {
+				assert.False(t, sc.IsValid())
+				return
+			}
+			require.True(t, sc.IsValid())
+			assert.True(t, sc.IsRemote())
+			assert.Equal(t, tt.traceID, sc.TraceID().String())
+		})
+	}
+}
+
+func TestLegacyTraceID(t *testing.T) {
+	tracing.StartInMemory(t)
+	ctx, span := tracing.Tracer().Start(context.Background(), "op")
+	defer span.End()
+
+	assert.Equal(t, "trace-abc", tracing.LegacyTraceID(ctx, "trace-abc"), "valor do cliente volta sem alterações")
+	assert.Equal(t, span.SpanContext().TraceID().String(), tracing.LegacyTraceID(ctx, ""))
+	assert.Len(t, tracing.TraceID(context.Background()), 32, "sem span, gera um ID no formato W3C")
+}
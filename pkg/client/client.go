@@ -0,0 +1,230 @@
+// Package client é o SDK Go para a API de Produtos (/api/v1/products).
+//
+// Exemplo:
+//
+//	c := client.New("http://localhost:8080", client.WithTokenSource(client.StaticToken(token)))
+//	for p, err := range c.AllProducts(ctx, 50) {
+//		...
+//	}
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"go-api-first-steps/pkg/traceid"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// basePath é o prefixo das rotas versionadas da API
+const basePath = "/api/v1"
+
+// TokenSource fornece o token Bearer usado em cada requisição.
+// Implementações podem renovar o token (ex: client credentials do Keycloak).
+type TokenSource interface {
+	Token(ctx context.Context) (string, error)
+}
+
+// TokenSourceFunc adapta uma função comum para TokenSource.
+type TokenSourceFunc func(ctx context.Context) (string, error)
+
+func (f TokenSourceFunc) Token(ctx context.Context) (string, error) {
+	return f(ctx)
+}
+
+// StaticToken devolve sempre o mesmo token (útil para testes e scripts).
+func StaticToken(token string) TokenSource {
+	return TokenSourceFunc(func(context.Context) (string, error) {
+		return token, nil
+	})
+}
+
+// Client é o cliente HTTP tipado da API de Produtos. É seguro para uso concorrente.
+type Client struct {
+	baseURL     string
+	httpClient  *http.Client
+	tokenSource TokenSource
+	maxRetries  int
+	backoff     time.Duration
+}
+
+// Option configura o Client em New.
+type Option func(*Client)
+
+// WithHTTPClient substitui o http.Client padrão (timeout de 30s).
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) { c.httpClient = hc }
+}
+
+// WithTokenSource define de onde vem o token Bearer. Sem ele, nenhuma credencial é enviada.
+func WithTokenSource(ts TokenSource) Option {
+	return func(c *Client) { c.tokenSource = ts }
+}
+
+// WithRetries configura as tentativas extras em chamadas idempotentes (GET, PUT, DELETE)
+// e o backoff inicial, que dobra a cada tentativa. Default: 3 tentativas, 200ms.
+func WithRetries(maxRetries int, backoff time.Duration) Option {
+	return func(c *Client) {
+		c.maxRetries = maxRetries
+		c.backoff = backoff
+	}
+}
+
+// New cria um Client para a API em baseURL (ex: "http://localhost:8080").
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		maxRetries: 3,
+		backoff:    200 * time.Millisecond,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// do executa a requisição, decodifica a resposta em out (se não for nil) e
+// converte respostas de erro em *APIError. Métodos idempotentes são repetidos
+// em falhas de rede e status 429/502/503/504.
+func (c *Client) do(ctx context.Context, method, path string, body, out any) error {
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return fmt.Errorf("client: falha ao serializar body: %w", err)
+		}
+	}
+
+	attempts := 1
+	if isIdempotent(method) {
+		attempts += c.maxRetries
+	}
+
+	var lastErr error
+	for attempt := 0; attempt < attempts; attempt++ {
+		if attempt > 0 {
+			if err := sleep(ctx, c.backoff<<(attempt-1)); err != nil {
+				return err
+			}
+		}
+
+		resp, err := c.send(ctx, method, path, payload)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			lastErr = err
+			continue
+		}
+
+		err = decodeResponse(resp, out)
+		if retryable(resp.StatusCode) {
+			lastErr = err
+			continue
+		}
+		return err
+	}
+	return lastErr
+}
+
+func (c *Client) send(ctx context.Context, method, path string, payload []byte) (*http.Response, error) {
+	var body io.Reader
+	if payload != nil {
+		body = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+basePath+path, body)
+	if err != nil {
+		return nil, fmt.Errorf("client: requisição inválida: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	// Propaga o trace: traceparent/tracestate do span em ctx (propagador global do
+	// OpenTelemetry) e o X-Trace-ID legado (pkg/traceid, a mesma chave do servidor)
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+	if traceID := traceid.From(ctx); traceID != "" {
+		req.Header.Set(traceid.Header, traceID)
+	}
+
+	if c.tokenSource != nil {
+		token, err := c.tokenSource.Token(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("client: falha ao obter token: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	return c.httpClient.Do(req)
+}
+
+func decodeResponse(resp *http.Response, out any) error {
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("client: falha ao ler resposta: %w", err)
+	}
+
+	if resp.StatusCode >= 400 {
+		return newAPIError(resp, data)
+	}
+
+	if out == nil || len(data) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("client: resposta inválida: %w", err)
+	}
+	return nil
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete, http.MethodOptions:
+		return true
+	}
+	return false
+}
+
+func retryable(status int) bool {
+	switch status {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// WithTraceID devolve um contexto cujo Trace ID será enviado no header X-Trace-ID.
+func WithTraceID(ctx context.Context, traceID string) context.Context {
+	return traceid.With(ctx, traceID)
+}
+
+// IsNotFound informa se err é um *APIError com status 404.
+func IsNotFound(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
@@ -0,0 +1,181 @@
+package client_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go-api-first-steps/internal/api"
+	"go-api-first-steps/internal/config"
+	"go-api-first-steps/internal/dependencies"
+	"go-api-first-steps/internal/middleware"
+	"go-api-first-steps/pkg/client"
+	"go-api-first-steps/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupAPI sobe o router real (DevMode + SQLite em memória) num httptest.Server.
+// wrap permite interceptar as requisições antes do router (ex: simular falhas).
+func setupAPI(t *testing.T, wrap func(http.Handler) http.Handler) (*httptest.Server, *dependencies.Container) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	cfg := &config.Config{DBUrl: ":memory:", DevMode: true}
+	ctn := dependencies.NewContainer(cfg)
+
+	var handler http.Handler = api.NewRouter(cfg, ctn)
+	if wrap != nil {
+		handler = wrap(handler)
+	}
+
+	ts := httptest.NewServer(handler)
+	t.Cleanup(ts.Close)
+	return ts, ctn
+}
+
+func TestClient_CRUD(t *testing.T) {
+	ts, _ := setupAPI(t, nil)
+	c := client.New(ts.URL, client.WithTokenSource(client.StaticToken("dev")))
+	ctx := context.Background()
+
+	msg, err := c.CreateProduct(ctx, client.ProductInput{Name: "Headset", Category: "audio"})
+	require.NoError(t, err)
+	assert.Contains(t, msg.Message, "Headset")
+
+	products, err := c.ListProducts(ctx, 1, 10)
+	require.NoError(t, err)
+	require.Len(t, products, 1)
+	assert.Equal(t, "Headset", products[0].Name)
+	assert.Equal(t, "audio", products[0].Category)
+	assert.Equal(t, "draft", products[0].Status)
+
+	_, err = c.UpdateProduct(ctx, products[0].ID, client.ProductInput{Name: "Headset Pro", Category: "perifericos", Status: "published"})
+	require.NoError(t, err)
+
+	products, err = c.ListProducts(ctx, 1, 10)
+	require.NoError(t, err)
+	require.Len(t, products, 1)
+	assert.Equal(t, "Headset Pro", products[0].Name)
+	assert.Equal(t, "perifericos", products[0].Category)
+	assert.Equal(t, "published", products[0].Status)
+
+	// Campos vazios mantêm o valor atual
+	_, err = c.UpdateProduct(ctx, products[0].ID, client.ProductInput{Name: "Headset Max"})
+	require.NoError(t, err)
+	products, err = c.ListProducts(ctx, 1, 10)
+	require.NoError(t, err)
+	assert.Equal(t, "perifericos", products[0].Category)
+	assert.Equal(t, "published", products[0].Status)
+
+	_, err = c.DeleteProduct(ctx, products[0].ID)
+	require.NoError(t, err)
+
+	products, err = c.ListProducts(ctx, 1, 10)
+	require.NoError(t, err)
+	assert.Empty(t, products)
+}
+
+func TestClient_AllProductsIterator(t *testing.T) {
+	ts, _ := setupAPI(t, nil)
+	c := client.New(ts.URL)
+	ctx := context.Background()
+
+	for _, name := range []string{"A", "B", "C", "D", "E"} {
+		_, err := c.CreateProduct(ctx, client.ProductInput{Name: name})
+		require.NoError(t, err)
+	}
+
+	var names []string
+	for p, err := range c.AllProducts(ctx, 2) {
+		require.NoError(t, err)
+		names = append(names, p.Name)
+	}
+	assert.Equal(t, []string{"A", "B", "C", "D", "E"}, names)
+}
+
+func TestClient_DecodesAPIError(t *testing.T) {
+	ts, ctn := setupAPI(t, nil)
+	// Authenticator sem Verifier: a API responde 500 "Autenticação não configurada"
+	*ctn.Authenticator = middleware.Authenticator{}
+
+	c := client.New(ts.URL, client.WithRetries(0, 0))
+	ctx := client.WithTraceID(context.Background(), "trace-abc")
+
+	_, err := c.ListProducts(ctx, 1, 10)
+
+	var apiErr *client.APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusInternalServerError, apiErr.StatusCode)
+	assert.Equal(t, "Autenticação não configurada", apiErr.Message)
+	assert.Equal(t, "trace-abc", apiErr.TraceID)
+}
+
+func TestClient_RetriesIdempotentCalls(t *testing.T) {
+	var gets, posts atomic.Int32
+	flaky := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodGet:
+				if gets.Add(1) <= 2 {
+					w.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+			case http.MethodPost:
+				posts.Add(1)
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+	ts, _ := setupAPI(t, flaky)
+	c := client.New(ts.URL, client.WithRetries(3, time.Millisecond))
+	ctx := context.Background()
+
+	// GET: duas falhas 503 seguidas de sucesso
+	_, err := c.ListProducts(ctx, 1, 10)
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), gets.Load())
+
+	// POST: não é idempotente, então não é repetido
+	_, err = c.CreateProduct(ctx, client.ProductInput{Name: "Duplicado?"})
+	require.Error(t, err)
+	assert.Equal(t, int32(1), posts.Load())
+}
+
+func TestClient_SendsTokenAndTraceID(t *testing.T) {
+	var gotAuth, gotTrace string
+	capture := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			gotAuth = r.Header.Get("Authorization")
+			gotTrace = r.Header.Get("X-Trace-ID")
+			next.ServeHTTP(w, r)
+		})
+	}
+	ts, _ := setupAPI(t, capture)
+
+	var calls int
+	tokens := client.TokenSourceFunc(func(context.Context) (string, error) {
+		calls++
+		return "token-123", nil
+	})
+	c := client.New(ts.URL, client.WithTokenSource(tokens))
+
+	ctx := client.WithTraceID(context.Background(), "trace-xyz")
+	_, err := c.ListProducts(ctx, 1, 10)
+
+	require.NoError(t, err)
+	assert.Equal(t, "Bearer token-123", gotAuth)
+	assert.Equal(t, "trace-xyz", gotTrace)
+	assert.Equal(t, 1, calls)
+
+	// A chave é a mesma lida pelo logger do servidor (pkg/traceid)
+	traceID, _ := logger.TraceContext(ctx)
+	assert.Equal(t, "trace-xyz", traceID)
+}
@@ -0,0 +1,71 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"go-api-first-steps/pkg/traceid"
+)
+
+// APIError representa uma resposta de erro (status >= 400) da API.
+//
+// Entende tanto o formato atual da API ({"error": "..."}) quanto
+// Problem Details (RFC 9457, application/problem+json).
+type APIError struct {
+	StatusCode int
+	Message    string
+	TraceID    string
+
+	// Campos de Problem Details (vazios quando a API responde {"error": "..."})
+	Type     string
+	Title    string
+	Detail   string
+	Instance string
+}
+
+func (e *APIError) Error() string {
+	msg := e.Message
+	if msg == "" {
+		msg = http.StatusText(e.StatusCode)
+	}
+	if e.TraceID != "" {
+		return fmt.Sprintf("api: %d %s (trace_id=%s)", e.StatusCode, msg, e.TraceID)
+	}
+	return fmt.Sprintf("api: %d %s", e.StatusCode, msg)
+}
+
+func newAPIError(resp *http.Response, body []byte) *APIError {
+	apiErr := &APIError{
+		StatusCode: resp.StatusCode,
+		TraceID:    resp.Header.Get(traceid.Header),
+	}
+
+	var payload struct {
+		Error    string `json:"error"`
+		Type     string `json:"type"`
+		Title    string `json:"title"`
+		Detail   string `json:"detail"`
+		Instance string `json:"instance"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		apiErr.Message = strings.TrimSpace(string(body))
+		return apiErr
+	}
+
+	apiErr.Type = payload.Type
+	apiErr.Title = payload.Title
+	apiErr.Detail = payload.Detail
+	apiErr.Instance = payload.Instance
+
+	switch {
+	case payload.Error != "":
+		apiErr.Message = payload.Error
+	case payload.Detail != "":
+		apiErr.Message = payload.Detail
+	default:
+		apiErr.Message = payload.Title
+	}
+	return apiErr
+}
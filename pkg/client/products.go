@@ -0,0 +1,108 @@
+package client
+
+import (
+	"context"
+	"iter"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Product espelha o JSON de produto retornado pela API.
+type Product struct {
+	ID        uint       `json:"id"`
+	Name      string     `json:"name"`
+	Price     float64    `json:"price"`
+	Category  string     `json:"category"`
+	Status    string     `json:"status"`
+	CreatedBy string     `json:"created_by"`
+	UpdatedBy string     `json:"updated_by"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+}
+
+// ProductInput é o corpo de POST e PUT /products.
+// Status vazio vale draft na criação; Category e Status vazios mantêm o valor atual na atualização.
+type ProductInput struct {
+	Name     string `json:"name"`
+	Category string `json:"category,omitempty"`
+	Status   string `json:"status,omitempty"` // draft ou published
+}
+
+// Message é a resposta simples ({"message": "..."}) das operações de escrita.
+type Message struct {
+	Message string `json:"message"`
+}
+
+// ListProducts chama GET /products com paginação (page inicia em 1).
+func (c *Client) ListProducts(ctx context.Context, page, pageSize int) ([]Product, error) {
+	q := url.Values{}
+	q.Set("page", strconv.Itoa(page))
+	q.Set("page_size", strconv.Itoa(pageSize))
+
+	var products []Product
+	if err := c.do(ctx, http.MethodGet, "/products?"+q.Encode(), nil, &products); err != nil {
+		return nil, err
+	}
+	return products, nil
+}
+
+// AllProducts percorre todas as páginas de GET /products. A iteração para
+// no primeiro erro (entregue como segundo valor) ou quando as páginas acabam.
+// pageSize fora de 1..100 usa o default da API (10).
+func (c *Client) AllProducts(ctx context.Context, pageSize int) iter.Seq2[Product, error] {
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 10
+	}
+
+	return func(yield func(Product, error) bool) {
+		for page := 1; ; page++ {
+			products, err := c.ListProducts(ctx, page, pageSize)
+			if err != nil {
+				yield(Product{}, err)
+				return
+			}
+
+			for _, p := range products {
+				if !yield(p, nil) {
+					return
+				}
+			}
+
+			if len(products) < pageSize {
+				return
+			}
+		}
+	}
+}
+
+// CreateProduct chama POST /products. Não é repetido automaticamente (não idempotente).
+func (c *Client) CreateProduct(ctx context.Context, in ProductInput) (*Message, error) {
+	var msg Message
+	if err := c.do(ctx, http.MethodPost, "/products", in, &msg); err != nil {
+		return nil, err
+	}
+	return &msg, nil
+}
+
+// UpdateProduct chama PUT /products/{id}.
+func (c *Client) UpdateProduct(ctx context.Context, id uint, in ProductInput) (*Message, error) {
+	var msg Message
+	path := "/products/" + strconv.FormatUint(uint64(id), 10)
+	if err := c.do(ctx, http.MethodPut, path, in, &msg); err != nil {
+		return nil, err
+	}
+	return &msg, nil
+}
+
+// DeleteProduct chama DELETE /products/{id} (soft delete).
+func (c *Client) DeleteProduct(ctx context.Context, id uint) (*Message, error) {
+	var msg Message
+	path := "/products/" + strconv.FormatUint(uint64(id), 10)
+	if err := c.do(ctx, http.MethodDelete, path, nil, &msg); err != nil {
+		return nil, err
+	}
+	return &msg, nil
+}
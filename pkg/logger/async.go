@@ -0,0 +1,209 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// OverflowPolicy define o que fazer com um log quando a fila do AsyncHandler está cheia.
+type OverflowPolicy int
+
+const (
+	DropOldest OverflowPolicy = iota // Descarta o log mais antigo da fila (default)
+	DropNew                          // Descarta o log novo
+	Block                            // Espera até BlockTimeout por espaço; depois descarta o novo
+)
+
+// ParseOverflowPolicy lê "drop_oldest", "drop_new" ou "block".
+func ParseOverflowPolicy(s string) (OverflowPolicy, error) {
+	switch s {
+	case "", "drop_oldest":
+		return DropOldest, nil
+	case "drop_new":
+		return DropNew, nil
+	case "block":
+		return Block, nil
+	}
+	return 0, fmt.Errorf("política de overflow desconhecida %q (use drop_oldest, drop_new ou block)", s)
+}
+
+// AsyncOptions configura a fila de um AsyncHandler.
+type AsyncOptions struct {
+	QueueSize    int            // Capacidade da fila. Default: 1024
+	Workers      int            // Goroutines que entregam ao destino. Default: 1 (mantém a ordem)
+	Overflow     OverflowPolicy // Fila cheia: DropOldest, DropNew ou Block
+	BlockTimeout time.Duration  // Espera máxima com Block. Default: 100ms
+	// OnError recebe as falhas do destino. Default: escreve em stderr (não pode usar o slog)
+	OnError func(sink string, err error)
+}
+
+// AsyncStats são os contadores de um AsyncHandler.
+type AsyncStats struct {
+	Sink    string
+	Queued  int    // Logs na fila agora
+	Dropped uint64 // Descartados por fila cheia
+	Failed  uint64 // Entregues ao destino, que devolveu erro
+}
+
+// AsyncHandler entrega os logs a um destino (handler) em goroutines próprias,
+// através de uma fila limitada: um destino lento não atrasa as requisições.
+// Close drena a fila; depois dele, os logs são entregues de forma síncrona.
+type AsyncHandler struct {
+	handler slog.Handler
+	p       *pipeline
+}
+
+type pipeline struct {
+	sink string
+	opts AsyncOptions
+
+	mu     sync.RWMutex // Handle (leitura) x Close (escrita): nunca envia na fila fechada
+	closed bool
+	queue  chan asyncRecord
+	done   chan struct{}
+
+	dropped atomic.Uint64
+	failed  atomic.Uint64
+}
+
+type asyncRecord struct {
+	ctx     context.Context
+	handler slog.Handler
+	record  slog.Record
+}
+
+// NewAsyncHandler cria o handler assíncrono para o destino h, identificado por sink
+// nos contadores e erros (ex: "console", "appinsights").
+func NewAsyncHandler(sink string, h slog.Handler, opts AsyncOptions) *AsyncHandler {
+	if opts.QueueSize <= 0 {
+		opts.QueueSize = 1024
+	}
+	if opts.Workers <= 0 {
+		opts.Workers = 1
+	}
+	if opts.BlockTimeout <= 0 {
+		opts.BlockTimeout = 100 * time.Millisecond
+	}
+	if opts.OnError == nil {
+		opts.OnError = func(sink string, err error) {
+			fmt.Fprintf(os.Stderr, "logger: falha no destino %s: %v\n", sink, err)
+		}
+	}
+
+	p := &pipeline{sink: sink, opts: opts, queue: make(chan asyncRecord, opts.QueueSize), done: make(chan struct{})}
+	var wg sync.WaitGroup
+	for range opts.Workers {
+		wg.Go(p.work)
+	}
+	go func() {
+		wg.Wait()
+		close(p.done)
+	}()
+	return &AsyncHandler{handler: h, p: p}
+}
+
+func (h *AsyncHandler) Enabled(ctx context.Context, l slog.Level) bool {
+	return h.handler.Enabled(ctx, l)
+}
+
+// Handle enfileira o log. Não devolve erro: falhas do destino vão para OnError.
+func (h *AsyncHandler) Handle(ctx context.Context, r slog.Record) error {
+	p := h.p
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.closed {
+		p.deliver(ctx, h.handler, r)
+		return nil
+	}
+
+	// O contexto só carrega valores (trace); o cancelamento da requisição não vale aqui
+	item := asyncRecord{ctx: context.WithoutCancel(ctx), handler: h.handler, record: r.Clone()}
+	select {
+	case p.queue <- item:
+		return nil
+	default:
+	}
+
+	switch p.opts.Overflow {
+	case DropNew:
+		p.dropped.Add(1)
+	case Block:
+		timer := time.NewTimer(p.opts.BlockTimeout)
+		defer timer.Stop()
+		select {
+		case p.queue <- item:
+		case <-timer.C:
+			p.dropped.Add(1)
+		}
+	default: // DropOldest
+		for {
+			select {
+			case p.queue <- item:
+				return nil
+			default:
+			}
+			select {
+			case <-p.queue:
+				p.dropped.Add(1)
+			default: // Um worker esvaziou a fila nesse meio tempo
+			}
+		}
+	}
+	return nil
+}
+
+func (h *AsyncHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &AsyncHandler{handler: h.handler.WithAttrs(attrs), p: h.p}
+}
+
+func (h *AsyncHandler) WithGroup(name string) slog.Handler {
+	return &AsyncHandler{handler: h.handler.WithGroup(name), p: h.p}
+}
+
+// Close para de enfileirar e espera os workers entregarem o que está na fila (ou o
+// fim de ctx). Logs que chegarem depois são entregues de forma síncrona.
+func (h *AsyncHandler) Close(ctx context.Context) error {
+	p := h.p
+	p.mu.Lock()
+	if !p.closed {
+		p.closed = true
+		close(p.queue)
+	}
+	p.mu.Unlock()
+
+	select {
+	case <-p.done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("fila de logs %s não drenada (%d pendentes): %w", p.sink, len(p.queue), ctx.Err())
+	}
+}
+
+// Stats devolve os contadores da fila.
+func (h *AsyncHandler) Stats() AsyncStats {
+	return AsyncStats{
+		Sink:    h.p.sink,
+		Queued:  len(h.p.queue),
+		Dropped: h.p.dropped.Load(),
+		Failed:  h.p.failed.Load(),
+	}
+}
+
+func (p *pipeline) work() {
+	for item := range p.queue {
+		p.deliver(item.ctx, item.handler, item.record)
+	}
+}
+
+func (p *pipeline) deliver(ctx context.Context, h slog.Handler, r slog.Record) {
+	if err := h.Handle(ctx, r); err != nil {
+		p.failed.Add(1)
+		p.opts.OnError(p.sink, err)
+	}
+}
@@ -0,0 +1,141 @@
+package logger_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"go-api-first-steps/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// gatedSink só grava depois que gate é fechado, simulando um destino lento.
+type gatedSink struct {
+	gate chan struct{}
+	err  error
+
+	mu       sync.Mutex
+	messages []string
+}
+
+func newGatedSink() *gatedSink { return &gatedSink{gate: make(chan struct{})} }
+
+func (s *gatedSink) Enabled(context.Context, slog.Level) bool { return true }
+func (s *gatedSink) WithAttrs([]slog.Attr) slog.Handler       { return s }
+func (s *gatedSink) WithGroup(string) slog.Handler            { return s }
+
+func (s *gatedSink) Handle(_ context.Context, r slog.Record) error {
+	<-s.gate
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.messages = append(s.messages, r.Message)
+	return s.err
+}
+
+func (s *gatedSink) Messages() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.messages...)
+}
+
+func record(msg string) slog.Record {
+	return slog.NewRecord(time.Now(), slog.LevelInfo, msg, 0)
+}
+
+// fill bloqueia o worker no primeiro log e enche a fila de tamanho 2.
+func fill(t *testing.T, h *logger.AsyncHandler) {
+	t.Helper()
+	require.NoError(t, h.Handle(context.Background(), record("a")))
+	require.Eventually(t, func() bool { return h.Stats().Queued == 0 }, time.Second, time.Millisecond, "worker não pegou o primeiro log")
+	require.NoError(t, h.Handle(context.Background(), record("b")))
+	require.NoError(t, h.Handle(context.Background(), record("c")))
+}
+
+func TestAsyncHandler_Overflow(t *testing.T) {
+	tests := []struct {
+		policy logger.OverflowPolicy
+		want   []string
+	}{
+		{logger.DropOldest, []string{"a", "c", "d"}},
+		{logger.DropNew, []string{"a", "b", "c"}},
+		{logger.Block, []string{"a", "b", "c"}}, // Timeout esgotado: descarta o novo
+	}
+	for _, tt := range tests {
+		sink := newGatedSink()
+		h := logger.NewAsyncHandler("test", sink, logger.AsyncOptions{QueueSize: 2, Overflow: tt.policy, BlockTimeout: 10 * time.Millisecond})
+		fill(t, h)
+
+		began := time.Now()
+		require.NoError(t, h.Handle(context.Background(), record("d")))
+		if tt.policy == logger.Block {
+			assert.GreaterOrEqual(t, time.Since(began), 10*time.Millisecond)
+		}
+		assert.Equal(t, uint64(1), h.Stats().Dropped)
+
+		close(sink.gate)
+		require.NoError(t, h.Close(context.Background()))
+		assert.Equal(t, tt.want, sink.Messages())
+	}
+}
+
+func TestAsyncHandler_BlockWaitsForSpace(t *testing.T) {
+	sink := newGatedSink()
+	h := logger.NewAsyncHandler("test", sink, logger.AsyncOptions{QueueSize: 2, Overflow: logger.Block, BlockTimeout: time.Second})
+	fill(t, h)
+
+	time.AfterFunc(20*time.Millisecond, func() { close(sink.gate) })
+	require.NoError(t, h.Handle(context.Background(), record("d")))
+	require.NoError(t, h.Close(context.Background()))
+	assert.Equal(t, []string{"a", "b", "c", "d"}, sink.Messages())
+	assert.Zero(t, h.Stats().Dropped)
+}
+
+func TestAsyncHandler_SlowSinkDoesNotBlock(t *testing.T) {
+	sink := newGatedSink()
+	h := logger.NewAsyncHandler("test", sink, logger.AsyncOptions{QueueSize: 100})
+	log := slog.New(h)
+
+	began := time.Now()
+	for range 50 {
+		log.Info("Requisição finalizada")
+	}
+	assert.Less(t, time.Since(began), 100*time.Millisecond)
+	assert.Empty(t, sink.Messages())
+
+	// Close espera o destino; com prazo curto, devolve erro
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.Error(t, h.Close(ctx))
+
+	close(sink.gate)
+	require.NoError(t, h.Close(context.Background()))
+	assert.Len(t, sink.Messages(), 50, "Close drena a fila")
+
+	// Depois do Close, a entrega é síncrona
+	log.Info("Depois do shutdown")
+	assert.Len(t, sink.Messages(), 51)
+}
+
+func TestAsyncHandler_ReportsSinkErrors(t *testing.T) {
+	sink := newGatedSink()
+	sink.err = errors.New("disco cheio")
+	close(sink.gate)
+
+	var mu sync.Mutex
+	var reported []string
+	h := logger.NewAsyncHandler("console", sink, logger.AsyncOptions{OnError: func(name string, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		reported = append(reported, name+": "+err.Error())
+	}})
+
+	require.NoError(t, h.Handle(context.Background(), record("a")))
+	require.NoError(t, h.Close(context.Background()))
+	assert.Equal(t, []string{"console: disco cheio"}, reported)
+	assert.Equal(t, uint64(1), h.Stats().Failed)
+}
@@ -8,53 +8,86 @@ import (
 	"github.com/microsoft/ApplicationInsights-Go/appinsights/contracts"
 )
 
+// AzureHandler envia os logs ao Application Insights. Level filtra por nível
+// (nil = Info) e Sampler, se definido, amostra os logs abaixo de Error.
 type AzureHandler struct {
-	Client appinsights.TelemetryClient
-	attrs  []slog.Attr
-	group  string
+	Client  appinsights.TelemetryClient
+	Level   slog.Leveler
+	Sampler Sampler
+	attrs   []slog.Attr
+	group   string
 }
 
 func NewAzureHandler(client appinsights.TelemetryClient) *AzureHandler {
 	return &AzureHandler{Client: client}
 }
 
-func (h *AzureHandler) Enabled(_ context.Context, _ slog.Level) bool {
-	return true
+// Enabled aceita logs a partir de Level, ou qualquer um liberado por override (LevelHandler).
+func (h *AzureHandler) Enabled(ctx context.Context, l slog.Level) bool {
+	if forced(ctx) {
+		return true
+	}
+	minLevel := slog.LevelInfo
+	if h.Level != nil {
+		minLevel = h.Level.Level()
+	}
+	return l >= minLevel
 }
 
+// Handle envia o log como TraceTelemetry. Logs de nível Error com um atributo do
+// tipo error viram ExceptionTelemetry (tabela exceptions do portal), com a mensagem
+// do log na propriedade "message".
 func (h *AzureHandler) Handle(ctx context.Context, r slog.Record) error {
-	msg := r.Message
-	severity := mapSeverity(r.Level)
-
-	// Cria o Trace com o nível correto
-	trace := appinsights.NewTraceTelemetry(msg, severity)
-	trace.Timestamp = r.Time
-
-	// Adiciona attrs pré-configurados (via WithAttrs)
-	for _, attr := range h.attrs {
-		key := attr.Key
-		if h.group != "" {
-			key = h.group + "." + key
-		}
-		trace.Properties[key] = attr.Value.String()
+	if h.Sampler != nil && r.Level < slog.LevelError && !h.Sampler.Sample(ctx, r) {
+		return nil
 	}
 
-	// Adiciona attrs do record
-	r.Attrs(func(a slog.Attr) bool {
+	properties := make(map[string]string)
+	var logErr error
+
+	// Adiciona attrs pré-configurados (via WithAttrs) e os do record
+	collect := func(a slog.Attr) bool {
 		key := a.Key
 		if h.group != "" {
 			key = h.group + "." + key
 		}
-		trace.Properties[key] = a.Value.String()
+		properties[key] = a.Value.String()
+		if err, ok := a.Value.Any().(error); ok && logErr == nil {
+			logErr = err
+		}
 		return true
-	})
+	}
+	for _, attr := range h.attrs {
+		collect(attr)
+	}
+	r.Attrs(collect)
+
+	var telemetry appinsights.Telemetry
+	var base *appinsights.BaseTelemetry
+	if r.Level >= slog.LevelError && logErr != nil {
+		exception := appinsights.NewExceptionTelemetry(logErr)
+		exception.Frames = nil // A pilha seria a do próprio handler; panics levam a sua em "stack"
+		properties["message"] = r.Message
+		telemetry, base = exception, &exception.BaseTelemetry
+	} else {
+		// Cria o Trace com o nível correto
+		trace := appinsights.NewTraceTelemetry(r.Message, mapSeverity(r.Level))
+		telemetry, base = trace, &trace.BaseTelemetry
+	}
+	base.Timestamp = r.Time
+	base.Properties = properties
 
-	if id, ok := ctx.Value(TraceIDKey).(string); ok {
-		trace.Tags.Operation().SetId(id)
-		trace.Properties["trace_id"] = id
+	// Correlação com as requisições e dependências do App Insights (operation_Id = trace W3C)
+	if traceID, spanID := TraceContext(ctx); traceID != "" {
+		base.Tags.Operation().SetId(traceID)
+		properties["trace_id"] = traceID
+		if spanID != "" {
+			base.Tags.Operation().SetParentId(spanID)
+			properties["span_id"] = spanID
+		}
 	}
 
-	h.Client.Track(trace)
+	h.Client.Track(telemetry)
 	return nil
 }
 
@@ -76,7 +109,7 @@ func (h *AzureHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
 	newAttrs := make([]slog.Attr, len(h.attrs), len(h.attrs)+len(attrs))
 	copy(newAttrs, h.attrs)
 	newAttrs = append(newAttrs, attrs...)
-	return &AzureHandler{Client: h.Client, attrs: newAttrs, group: h.group}
+	return &AzureHandler{Client: h.Client, Level: h.Level, Sampler: h.Sampler, attrs: newAttrs, group: h.group}
 }
 
 func (h *AzureHandler) WithGroup(name string) slog.Handler {
@@ -84,5 +117,5 @@ func (h *AzureHandler) WithGroup(name string) slog.Handler {
 	if h.group != "" {
 		newGroup = h.group + "." + name
 	}
-	return &AzureHandler{Client: h.Client, attrs: h.attrs, group: newGroup}
+	return &AzureHandler{Client: h.Client, Level: h.Level, Sampler: h.Sampler, attrs: h.attrs, group: newGroup}
 }
@@ -0,0 +1,79 @@
+package logger_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"go-api-first-steps/internal/mockappinsights"
+	"go-api-first-steps/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAzureHandler_ErrorsBecomeExceptions(t *testing.T) {
+	ingest := mockappinsights.Start(t)
+	client := ingest.NewClient()
+	log := slog.New(logger.NewAzureHandler(client)).With("component", "test")
+
+	ctx := context.WithValue(context.Background(), logger.TraceIDKey, "4bf92f3577b34da6a3ce929d0e0e4736")
+	log.InfoContext(ctx, "Requisição finalizada", "status", 200)
+	log.ErrorContext(ctx, "Falha ao salvar produto", "error", errors.New("database is locked"))
+	log.ErrorContext(ctx, "Erro sem causa") // Sem atributo error continua sendo trace
+	ingest.Flush(client)
+
+	traces := ingest.Items("MessageData")
+	require.Len(t, traces, 2)
+
+	exceptions := ingest.Items("ExceptionData")
+	require.Len(t, exceptions, 1)
+	exc := exceptions[0]
+	props := exc.Properties()
+	assert.Equal(t, "Falha ao salvar produto", props["message"])
+	assert.Equal(t, "test", props["component"])
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", exc.Tags["ai.operation.id"])
+	assert.Contains(t, exc.Field("exceptions"), "database is locked")
+}
+
+func TestAzureHandler_LevelAndSampling(t *testing.T) {
+	ingest := mockappinsights.Start(t)
+	client := ingest.NewClient()
+	h := logger.NewAzureHandler(client)
+	h.Level = slog.LevelWarn
+	h.Sampler = logger.FixedRateSampler{Rate: 0}
+
+	ctx := context.Background()
+	assert.False(t, h.Enabled(ctx, slog.LevelInfo))
+	assert.True(t, h.Enabled(ctx, slog.LevelWarn))
+
+	// Console aceita Info, App Insights não: o fanout só entrega a quem aceita
+	log := slog.New(logger.NewFanoutHandler(slog.DiscardHandler, h))
+	log.Info("Requisição finalizada")
+	log.Warn("Acesso negado")                                   // descartado pela amostragem
+	log.Error("Falha ao salvar", "error", errors.New("falhou")) // erros nunca são amostrados
+	ingest.Flush(client)
+
+	assert.Empty(t, ingest.Items("MessageData"))
+	assert.Len(t, ingest.Items("ExceptionData"), 1)
+}
+
+func TestShutdown_FlushesAppInsights(t *testing.T) {
+	prev := slog.Default()
+	t.Cleanup(func() { slog.SetDefault(prev) })
+
+	ingest := mockappinsights.Start(t)
+	logger.Setup(logger.Options{ConnectionString: ingest.ConnectionString(), Level: slog.LevelError})
+	slog.Warn("Desligando servidor graciosamente...")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.NoError(t, logger.Shutdown(ctx))
+	// Sem esperar o MaxBatchInterval: o lote foi enviado pelo Shutdown
+	assert.Len(t, ingest.Items("MessageData"), 1)
+
+	slog.Warn("Depois do shutdown") // Não bloqueia nem é enviado
+	assert.Len(t, ingest.Items("MessageData"), 1)
+}
@@ -2,10 +2,12 @@ package logger
 
 import (
 	"context"
+	"errors"
 	"log/slog"
 )
 
-// FanoutHandler aceita uma lista de handlers e manda o log para todos eles
+// FanoutHandler aceita uma lista de handlers e manda o log para todos eles. Cada
+// handler tem o seu nível mínimo (Enabled): o log só vai para quem o aceita.
 type FanoutHandler struct {
 	handlers []slog.Handler
 }
@@ -23,11 +25,17 @@ func (h *FanoutHandler) Enabled(ctx context.Context, l slog.Level) bool {
 	return false
 }
 
+// Handle entrega a todos os handlers, mesmo que algum falhe, e devolve os erros juntos.
 func (h *FanoutHandler) Handle(ctx context.Context, r slog.Record) error {
+	var errs []error
 	for _, handler := range h.handlers {
-		_ = handler.Handle(ctx, r.Clone())
+		if handler.Enabled(ctx, r.Level) {
+			if err := handler.Handle(ctx, r.Clone()); err != nil {
+				errs = append(errs, err)
+			}
+		}
 	}
-	return nil
+	return errors.Join(errs...)
 }
 
 func (h *FanoutHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
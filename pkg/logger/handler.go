@@ -3,13 +3,28 @@ package logger
 import (
 	"context"
 	"log/slog"
+
+	"go-api-first-steps/pkg/traceid"
+
+	"go.opentelemetry.io/otel/trace"
 )
 
 // 1. Crie um tipo customizado (pode ser privado)
 type ctxKey string
 
-// TraceIDKey é a chave usada no Contexto
-const TraceIDKey ctxKey = "trace_id"
+// TraceIDKey é a chave usada no Contexto (a mesma de pkg/traceid, lida pelo pkg/client).
+// Com OpenTelemetry, o trace ID do span ativo tem precedência (veja TraceContext).
+const TraceIDKey = traceid.Key
+
+// TraceContext devolve os IDs de correlação do contexto: trace e span do span
+// OpenTelemetry ativo ou, sem span, o trace ID legado (TraceIDKey) sem span ID.
+func TraceContext(ctx context.Context) (traceID, spanID string) {
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		return sc.TraceID().String(), sc.SpanID().String()
+	}
+	traceID, _ = ctx.Value(TraceIDKey).(string)
+	return traceID, ""
+}
 
 type ContextHandler struct {
 	slog.Handler
@@ -20,10 +35,18 @@ func NewContextHandler(h slog.Handler) *ContextHandler {
 	return &ContextHandler{Handler: h}
 }
 
-// Handle intercepta o log, olha o Contexto e injeta o ID se existir
+// Handle intercepta o log, olha o Contexto e injeta trace_id e span_id se existirem.
+// Um X-Trace-ID legado diferente do trace W3C vai em legacy_trace_id.
 func (h *ContextHandler) Handle(ctx context.Context, r slog.Record) error {
-	if id, ok := ctx.Value(TraceIDKey).(string); ok {
-		r.AddAttrs(slog.String("trace_id", id))
+	traceID, spanID := TraceContext(ctx)
+	if traceID != "" {
+		r.AddAttrs(slog.String("trace_id", traceID))
+	}
+	if spanID != "" {
+		r.AddAttrs(slog.String("span_id", spanID))
+	}
+	if legacy, ok := ctx.Value(TraceIDKey).(string); ok && legacy != traceID {
+		r.AddAttrs(slog.String("legacy_trace_id", legacy))
 	}
 	return h.Handler.Handle(ctx, r)
 }
@@ -0,0 +1,236 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Escopos de override de nível.
+const (
+	ScopePackage = "package" // Pacote Go de quem loga (ex: go-api-first-steps/internal/middleware)
+	ScopeRoute   = "route"   // Template da rota HTTP (ex: /api/v1/products/:id) ou método gRPC
+)
+
+const (
+	debugKey ctxKey = "log_debug"
+	routeKey ctxKey = "log_route"
+	forceKey ctxKey = "log_forced" // Log liberado por override: os destinos não o filtram de novo
+)
+
+// WithDebug liga os logs de debug só para este contexto (uma requisição/trace).
+func WithDebug(ctx context.Context) context.Context {
+	return context.WithValue(ctx, debugKey, true)
+}
+
+// WithRoute registra a rota do contexto, usada pelos overrides de escopo "route".
+func WithRoute(ctx context.Context, route string) context.Context {
+	return context.WithValue(ctx, routeKey, route)
+}
+
+func forced(ctx context.Context) bool {
+	v, _ := ctx.Value(forceKey).(bool)
+	return v
+}
+
+// Override é um nível mínimo temporário para um pacote ou rota.
+type Override struct {
+	Scope     string     `json:"scope" example:"route"`
+	Name      string     `json:"name" example:"/api/v1/products"`
+	Level     slog.Level `json:"level" swaggertype:"string" example:"DEBUG"`
+	ExpiresAt time.Time  `json:"expires_at"`
+}
+
+// LevelController guarda os níveis mínimos em tempo de execução: um slog.LevelVar
+// por destino (console e App Insights) e overrides por pacote/rota que expiram
+// sozinhos. Setup usa o controller global (veja Controller).
+type LevelController struct {
+	Console     *slog.LevelVar
+	AppInsights *slog.LevelVar
+	Now         func() time.Time // Relógio (substituível em testes)
+
+	mu        sync.RWMutex
+	overrides map[string]Override // scope + "\x00" + name
+}
+
+// NewLevelController cria um controller com os dois destinos em Info.
+func NewLevelController() *LevelController {
+	return &LevelController{
+		Console:     new(slog.LevelVar),
+		AppInsights: new(slog.LevelVar),
+		Now:         time.Now,
+		overrides:   make(map[string]Override),
+	}
+}
+
+var controller = NewLevelController()
+
+// Controller devolve o controller usado pelo logger global.
+func Controller() *LevelController {
+	return controller
+}
+
+// SetOverride define o nível mínimo de um pacote ou rota por ttl.
+func (c *LevelController) SetOverride(scope, name string, level slog.Level, ttl time.Duration) (Override, error) {
+	if scope != ScopePackage && scope != ScopeRoute {
+		return Override{}, fmt.Errorf("escopo inválido %q (use %s ou %s)", scope, ScopePackage, ScopeRoute)
+	}
+	if name == "" || ttl <= 0 {
+		return Override{}, fmt.Errorf("override exige nome e duração positiva")
+	}
+	o := Override{Scope: scope, Name: name, Level: level, ExpiresAt: c.Now().Add(ttl)}
+	c.mu.Lock()
+	c.overrides[scope+"\x00"+name] = o
+	c.mu.Unlock()
+	return o, nil
+}
+
+// RemoveOverride remove um override; devolve false se ele não existia.
+func (c *LevelController) RemoveOverride(scope, name string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key := scope + "\x00" + name
+	_, ok := c.overrides[key]
+	delete(c.overrides, key)
+	return ok
+}
+
+// Overrides devolve os overrides ainda válidos, descartando os expirados.
+func (c *LevelController) Overrides() []Override {
+	now := c.Now()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	out := make([]Override, 0, len(c.overrides))
+	for key, o := range c.overrides {
+		if !now.Before(o.ExpiresAt) {
+			delete(c.overrides, key)
+			continue
+		}
+		out = append(out, o)
+	}
+	slices.SortFunc(out, func(a, b Override) int {
+		return strings.Compare(a.Scope+"\x00"+a.Name, b.Scope+"\x00"+b.Name)
+	})
+	return out
+}
+
+// overrideLevel devolve o menor nível dos overrides válidos que casam com a rota
+// de ctx e o pacote de pc (pc 0 = qualquer pacote com override).
+func (c *LevelController) overrideLevel(ctx context.Context, pc uintptr, anyPackage bool) (slog.Level, bool) {
+	now := c.Now()
+	route, _ := ctx.Value(routeKey).(string)
+	pkg := ""
+	if pc != 0 {
+		pkg = packageOf(pc)
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	var level slog.Level
+	found := false
+	for _, o := range c.overrides {
+		if !now.Before(o.ExpiresAt) {
+			continue
+		}
+		match := false
+		switch o.Scope {
+		case ScopeRoute:
+			match = route != "" && o.Name == route
+		case ScopePackage:
+			match = anyPackage || pkg == o.Name || strings.HasPrefix(pkg, o.Name+"/")
+		}
+		if match && (!found || o.Level < level) {
+			level, found = o.Level, true
+		}
+	}
+	return level, found
+}
+
+// packageOf extrai o caminho do pacote da função em pc.
+func packageOf(pc uintptr) string {
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	fn := frame.Function // ex: go-api-first-steps/internal/handlers.(*ProductHandler).List
+	slash := strings.LastIndex(fn, "/")
+	if dot := strings.Index(fn[slash+1:], "."); dot >= 0 {
+		return fn[:slash+1+dot]
+	}
+	return fn
+}
+
+// LevelHandler aplica os níveis do LevelController antes dos destinos: passa o
+// que algum destino aceita, o que um override (pacote, rota ou debug da
+// requisição) libera. Logs liberados por override chegam a todos os destinos.
+type LevelHandler struct {
+	handler    slog.Handler
+	controller *LevelController
+}
+
+// NewLevelHandler cria o filtro de níveis em volta de h.
+func NewLevelHandler(h slog.Handler, c *LevelController) *LevelHandler {
+	return &LevelHandler{handler: h, controller: c}
+}
+
+func (h *LevelHandler) Enabled(ctx context.Context, l slog.Level) bool {
+	if h.base(l) || h.debug(ctx) {
+		return true
+	}
+	// O pacote só é conhecido no Handle (pelo PC); aqui basta haver algum override
+	level, ok := h.controller.overrideLevel(ctx, 0, true)
+	return ok && l >= level
+}
+
+func (h *LevelHandler) Handle(ctx context.Context, r slog.Record) error {
+	c := h.controller
+	// Só importa o override se algum destino filtraria o log
+	if r.Level < max(c.Console.Level(), c.AppInsights.Level()) {
+		level, ok := c.overrideLevel(ctx, r.PC, false)
+		switch {
+		case h.debug(ctx) || (ok && r.Level >= level):
+			ctx = context.WithValue(ctx, forceKey, true)
+		case !h.base(r.Level):
+			return nil
+		}
+	}
+	return h.handler.Handle(ctx, r)
+}
+
+func (h *LevelHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &LevelHandler{handler: h.handler.WithAttrs(attrs), controller: h.controller}
+}
+
+func (h *LevelHandler) WithGroup(name string) slog.Handler {
+	return &LevelHandler{handler: h.handler.WithGroup(name), controller: h.controller}
+}
+
+// base diz se algum destino aceita o nível sem override.
+func (h *LevelHandler) base(l slog.Level) bool {
+	return l >= min(h.controller.Console.Level(), h.controller.AppInsights.Level())
+}
+
+func (h *LevelHandler) debug(ctx context.Context) bool {
+	v, _ := ctx.Value(debugKey).(bool)
+	return v
+}
+
+// sinkLevel filtra um destino pelo seu nível, exceto logs liberados por override.
+type sinkLevel struct {
+	slog.Handler
+	level slog.Leveler
+}
+
+func (h *sinkLevel) Enabled(ctx context.Context, l slog.Level) bool {
+	return forced(ctx) || l >= h.level.Level()
+}
+
+func (h *sinkLevel) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &sinkLevel{Handler: h.Handler.WithAttrs(attrs), level: h.level}
+}
+
+func (h *sinkLevel) WithGroup(name string) slog.Handler {
+	return &sinkLevel{Handler: h.Handler.WithGroup(name), level: h.level}
+}
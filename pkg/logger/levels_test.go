@@ -0,0 +1,92 @@
+package logger_test
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"go-api-first-steps/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// leveledLogger monta o LevelHandler com console em Info e App Insights em Warn
+// sobre um destino que aceita tudo.
+func leveledLogger(t *testing.T) (*slog.Logger, *logger.LevelController, *bytes.Buffer) {
+	t.Helper()
+	c := logger.NewLevelController()
+	c.AppInsights.Set(slog.LevelWarn)
+	var buf bytes.Buffer
+	h := slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})
+	return slog.New(logger.NewLevelHandler(h, c)), c, &buf
+}
+
+func TestLevelHandler_GlobalLevel(t *testing.T) {
+	log, c, buf := leveledLogger(t)
+
+	log.Debug("debug desligado")
+	log.Info("info passa")
+	assert.NotContains(t, buf.String(), "debug desligado")
+	assert.Contains(t, buf.String(), "info passa")
+
+	// Troca em runtime, sem recriar o logger
+	c.Console.Set(slog.LevelError)
+	log.Info("info cortado")
+	log.Warn("warn vai para o App Insights")
+	assert.NotContains(t, buf.String(), "info cortado")
+	assert.Contains(t, buf.String(), "warn vai para o App Insights")
+}
+
+func TestLevelHandler_Overrides(t *testing.T) {
+	log, c, buf := leveledLogger(t)
+	ctx := logger.WithRoute(context.Background(), "/api/v1/products")
+
+	_, err := c.SetOverride(logger.ScopeRoute, "/api/v1/products", slog.LevelDebug, time.Minute)
+	require.NoError(t, err)
+	log.DebugContext(ctx, "debug da rota")
+	log.DebugContext(logger.WithRoute(context.Background(), "/api/v1/me"), "debug de outra rota")
+	assert.Contains(t, buf.String(), "debug da rota")
+	assert.NotContains(t, buf.String(), "debug de outra rota")
+
+	// Pacote de quem loga (aqui, o pacote de teste)
+	_, err = c.SetOverride(logger.ScopePackage, "go-api-first-steps/pkg/logger_test", slog.LevelDebug, time.Minute)
+	require.NoError(t, err)
+	log.Debug("debug do pacote")
+	assert.Contains(t, buf.String(), "debug do pacote")
+
+	_, err = c.SetOverride("tenant", "x", slog.LevelDebug, time.Minute)
+	assert.Error(t, err)
+
+	assert.True(t, c.RemoveOverride(logger.ScopePackage, "go-api-first-steps/pkg/logger_test"))
+	assert.False(t, c.RemoveOverride(logger.ScopePackage, "go-api-first-steps/pkg/logger_test"))
+	log.Debug("debug sem override")
+	assert.NotContains(t, buf.String(), "debug sem override")
+}
+
+func TestLevelHandler_OverrideExpires(t *testing.T) {
+	log, c, buf := leveledLogger(t)
+	now := time.Now()
+	c.Now = func() time.Time { return now }
+	ctx := logger.WithRoute(context.Background(), "/api/v1/products")
+
+	_, err := c.SetOverride(logger.ScopeRoute, "/api/v1/products", slog.LevelDebug, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, c.Overrides(), 1)
+
+	now = now.Add(time.Minute)
+	log.DebugContext(ctx, "debug expirado")
+	assert.NotContains(t, buf.String(), "debug expirado")
+	assert.Empty(t, c.Overrides())
+}
+
+func TestLevelHandler_DebugContext(t *testing.T) {
+	log, _, buf := leveledLogger(t)
+
+	log.DebugContext(logger.WithDebug(context.Background()), "debug desta requisição")
+	log.Debug("debug de outra requisição")
+	assert.Contains(t, buf.String(), "debug desta requisição")
+	assert.NotContains(t, buf.String(), "debug de outra requisição")
+}
@@ -0,0 +1,396 @@
+package logger
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// RedactionAction é o que acontece com um dado sensível.
+type RedactionAction int
+
+const (
+	Mask   RedactionAction = iota // Troca por "[REDACTED]"
+	Hash                          // Troca por "hmac:<12 hex>": estável, permite correlacionar sem expor
+	Remove                        // Remove o atributo (só para regras por chave)
+)
+
+// ParseRedactionAction lê "mask", "hash" ou "remove".
+func ParseRedactionAction(s string) (RedactionAction, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "mask":
+		return Mask, nil
+	case "hash":
+		return Hash, nil
+	case "remove":
+		return Remove, nil
+	}
+	return 0, fmt.Errorf("ação de redação desconhecida %q (use mask, hash ou remove)", s)
+}
+
+// Redacted é o texto que substitui um valor mascarado.
+const Redacted = "[REDACTED]"
+
+// KeyRule redige atributos pelo nome (sem diferenciar maiúsculas). Key casa com o
+// nome do atributo ou com o caminho com grupos (ex: "user.email").
+type KeyRule struct {
+	Key    string
+	Action RedactionAction
+}
+
+// PatternRule redige trechos de valores texto (e da mensagem) que casam com Pattern.
+// Valid, se definido, confirma cada trecho (ex: Luhn para cartões).
+type PatternRule struct {
+	Name    string
+	Pattern *regexp.Regexp
+	Valid   func(match string) bool
+	Action  RedactionAction // Mask ou Hash
+}
+
+// Padrões embutidos, usados por nome em ParsePatternRules.
+var (
+	EmailPattern = PatternRule{Name: "email", Pattern: regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)}
+	CardPattern  = PatternRule{Name: "card", Pattern: regexp.MustCompile(`\b(?:\d[ -]?){12,18}\d\b`), Valid: luhn}
+)
+
+// Regras sempre aplicadas, independente da configuração: credenciais nunca chegam aos destinos.
+var (
+	alwaysRedactedKeys = []string{"authorization", "proxy-authorization", "x-api-key", "cookie", "set-cookie"}
+	jwtPattern         = PatternRule{Name: "jwt", Pattern: regexp.MustCompile(`\beyJ[A-Za-z0-9_-]*\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`)}
+	schemePattern      = regexp.MustCompile(`(?i)\b(bearer|basic|apikey)\s+[A-Za-z0-9._~+/=-]+`)
+)
+
+// RedactOptions configura o Redactor (e o RedactHandler).
+type RedactOptions struct {
+	Keys     []KeyRule
+	Patterns []PatternRule
+	// HashKey é a chave do HMAC de Hash. Vazia = chave aleatória por processo (os
+	// hashes só correlacionam dentro da mesma instância).
+	HashKey []byte
+}
+
+// Regras padrão (LOG_REDACT_KEYS e LOG_REDACT_PATTERNS não definidos). enduser.id
+// e client.address são os atributos de usuário e IP dos spans enviados ao App Insights.
+const (
+	DefaultRedactKeys     = "user_email=hash,email=hash,user_name=mask,password=remove,client_secret=remove,enduser.id=hash,client.address=hash"
+	DefaultRedactPatterns = "email=mask;card=mask"
+)
+
+// DefaultRedaction devolve as regras padrão.
+func DefaultRedaction() RedactOptions {
+	keys, _ := ParseKeyRules(DefaultRedactKeys)
+	patterns, _ := ParsePatternRules(DefaultRedactPatterns)
+	return RedactOptions{Keys: keys, Patterns: patterns}
+}
+
+// ParseKeyRules lê "chave=ação,..." (ex: "user_email=hash,password=remove").
+func ParseKeyRules(s string) ([]KeyRule, error) {
+	var rules []KeyRule
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		key, action, ok := strings.Cut(part, "=")
+		if !ok || strings.TrimSpace(key) == "" {
+			return nil, fmt.Errorf("regra de redação inválida %q (use chave=ação)", part)
+		}
+		a, err := ParseRedactionAction(action)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, KeyRule{Key: strings.TrimSpace(key), Action: a})
+	}
+	return rules, nil
+}
+
+// ParsePatternRules lê "padrão=ação;..." onde padrão é "email", "card" ou uma
+// regex entre barras (ex: "email=mask;card=mask;/\d{3}\.\d{3}\.\d{3}-\d{2}/=hash").
+// O separador é ";" porque regexes podem ter vírgulas.
+func ParsePatternRules(s string) ([]PatternRule, error) {
+	var rules []PatternRule
+	for _, part := range strings.Split(s, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		i := strings.LastIndex(part, "=")
+		if i <= 0 {
+			return nil, fmt.Errorf("padrão de redação inválido %q (use padrão=ação)", part)
+		}
+		name, action := strings.TrimSpace(part[:i]), part[i+1:]
+		a, err := ParseRedactionAction(action)
+		if err != nil {
+			return nil, err
+		}
+		if a == Remove {
+			return nil, fmt.Errorf("padrão %q: remove só vale para chaves (use mask ou hash)", name)
+		}
+
+		var rule PatternRule
+		switch {
+		case name == EmailPattern.Name:
+			rule = EmailPattern
+		case name == CardPattern.Name:
+			rule = CardPattern
+		case len(name) > 2 && strings.HasPrefix(name, "/") && strings.HasSuffix(name, "/"):
+			re, err := regexp.Compile(name[1 : len(name)-1])
+			if err != nil {
+				return nil, fmt.Errorf("padrão %q: %w", name, err)
+			}
+			rule = PatternRule{Name: name, Pattern: re}
+		default:
+			return nil, fmt.Errorf("padrão de redação desconhecido %q (use email, card ou /regex/)", name)
+		}
+		rule.Action = a
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// Redactor aplica as regras de redação. Além das regras configuradas, sempre
+// mascara os headers de credencial (Authorization, X-API-Key, Cookie), JWTs e
+// "Bearer/Basic <valor>". O RedactHandler o usa nos logs; o exportador de spans
+// do App Insights, nas propriedades da telemetria (veja Redaction).
+type Redactor struct {
+	keys     map[string]RedactionAction
+	patterns []PatternRule
+	hashKey  []byte
+}
+
+// NewRedactor compila as regras de opts.
+func NewRedactor(opts RedactOptions) *Redactor {
+	keys := make(map[string]RedactionAction, len(opts.Keys)+len(alwaysRedactedKeys))
+	for _, rule := range opts.Keys {
+		keys[strings.ToLower(rule.Key)] = rule.Action
+	}
+	for _, key := range alwaysRedactedKeys {
+		if action, ok := keys[key]; !ok || action == Hash {
+			keys[key] = Mask
+		}
+	}
+
+	hashKey := opts.HashKey
+	if len(hashKey) == 0 {
+		hashKey = make([]byte, 32)
+		_, _ = rand.Read(hashKey)
+	}
+	return &Redactor{
+		keys:     keys,
+		patterns: append([]PatternRule{jwtPattern}, opts.Patterns...),
+		hashKey:  hashKey,
+	}
+}
+
+// Property redige o par chave/valor de texto (ex: atributo de span); ok=false
+// indica que a propriedade deve ser removida.
+func (r *Redactor) Property(key, value string) (string, bool) {
+	if action, ok := r.keyAction(key, key); ok {
+		switch action {
+		case Remove:
+			return "", false
+		case Hash:
+			return r.hash(value), true
+		default:
+			return Redacted, true
+		}
+	}
+	return r.redactString(value), true
+}
+
+// RedactHandler remove dados sensíveis da mensagem e dos atributos antes de
+// repassar o log (regras do Redactor). Deve envolver todos os destinos (veja Setup).
+type RedactHandler struct {
+	handler slog.Handler
+	*Redactor
+	group string // Prefixo dos grupos abertos via WithGroup
+}
+
+// NewRedactHandler cria o handler de redação em volta de h.
+func NewRedactHandler(h slog.Handler, opts RedactOptions) *RedactHandler {
+	return &RedactHandler{handler: h, Redactor: NewRedactor(opts)}
+}
+
+func (h *RedactHandler) Enabled(ctx context.Context, l slog.Level) bool {
+	return h.handler.Enabled(ctx, l)
+}
+
+func (h *RedactHandler) Handle(ctx context.Context, r slog.Record) error {
+	out := slog.NewRecord(r.Time, r.Level, h.redactString(r.Message), r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		if a, ok := h.redactAttr(h.group, a); ok {
+			out.AddAttrs(a)
+		}
+		return true
+	})
+	return h.handler.Handle(ctx, out)
+}
+
+func (h *RedactHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redacted := make([]slog.Attr, 0, len(attrs))
+	for _, a := range attrs {
+		if a, ok := h.redactAttr(h.group, a); ok {
+			redacted = append(redacted, a)
+		}
+	}
+	clone := *h
+	clone.handler = h.handler.WithAttrs(redacted)
+	return &clone
+}
+
+func (h *RedactHandler) WithGroup(name string) slog.Handler {
+	clone := *h
+	clone.handler = h.handler.WithGroup(name)
+	clone.group = joinKey(h.group, name)
+	return &clone
+}
+
+// redactAttr aplica as regras ao atributo; ok=false remove o atributo.
+func (h *RedactHandler) redactAttr(group string, a slog.Attr) (slog.Attr, bool) {
+	a.Value = a.Value.Resolve()
+	path := joinKey(group, a.Key)
+
+	if action, ok := h.keyAction(a.Key, path); ok {
+		switch action {
+		case Remove:
+			return a, false
+		case Hash:
+			return slog.String(a.Key, h.hash(a.Value.String())), true
+		default:
+			return slog.String(a.Key, Redacted), true
+		}
+	}
+
+	switch a.Value.Kind() {
+	case slog.KindString:
+		a.Value = slog.StringValue(h.redactString(a.Value.String()))
+	case slog.KindGroup:
+		var attrs []slog.Attr
+		for _, ga := range a.Value.Group() {
+			if ga, ok := h.redactAttr(path, ga); ok {
+				attrs = append(attrs, ga)
+			}
+		}
+		a.Value = slog.GroupValue(attrs...)
+	case slog.KindAny:
+		if value, ok := mapAsGroup(a.Value.Any()); ok {
+			// Headers e mapas viram grupo, para as regras por chave valerem nas entradas
+			return h.redactAttr(group, slog.Attr{Key: a.Key, Value: value})
+		}
+		a.Value = h.redactAny(a.Value.Any(), a.Value)
+	}
+	return a, true
+}
+
+// mapAsGroup converte headers HTTP e mapas com chave string em um grupo (chaves ordenadas).
+func mapAsGroup(v any) (slog.Value, bool) {
+	var attrs []slog.Attr
+	switch m := v.(type) {
+	case http.Header:
+		return mapAsGroup(map[string][]string(m))
+	case map[string][]string:
+		for k, vs := range m {
+			attrs = append(attrs, slog.String(k, strings.Join(vs, ", ")))
+		}
+	case map[string]string:
+		for k, s := range m {
+			attrs = append(attrs, slog.String(k, s))
+		}
+	case map[string]any:
+		for k, a := range m {
+			attrs = append(attrs, slog.Any(k, a))
+		}
+	default:
+		return slog.Value{}, false
+	}
+	slices.SortFunc(attrs, func(a, b slog.Attr) int { return strings.Compare(a.Key, b.Key) })
+	return slog.GroupValue(attrs...), true
+}
+
+// redactAny redige o texto de valores arbitrários (erros, slices, mapas de headers).
+// Se nada muda, o valor original é mantido; erros continuam sendo erros (o
+// AzureHandler os envia como exceções).
+func (h *RedactHandler) redactAny(v any, original slog.Value) slog.Value {
+	if err, ok := v.(error); ok {
+		if msg := h.redactString(err.Error()); msg != err.Error() {
+			return slog.AnyValue(redactedError(msg))
+		}
+		return original
+	}
+	text := fmt.Sprint(v)
+	if redacted := h.redactString(text); redacted != text {
+		return slog.StringValue(redacted)
+	}
+	return original
+}
+
+func (r *Redactor) keyAction(key, path string) (RedactionAction, bool) {
+	if action, ok := r.keys[strings.ToLower(path)]; ok {
+		return action, true
+	}
+	action, ok := r.keys[strings.ToLower(key)]
+	return action, ok
+}
+
+func (r *Redactor) redactString(s string) string {
+	s = schemePattern.ReplaceAllString(s, "$1 "+Redacted)
+	for _, rule := range r.patterns {
+		s = rule.Pattern.ReplaceAllStringFunc(s, func(match string) string {
+			if rule.Valid != nil && !rule.Valid(match) {
+				return match
+			}
+			if rule.Action == Hash {
+				return r.hash(match)
+			}
+			return Redacted
+		})
+	}
+	return s
+}
+
+func (r *Redactor) hash(value string) string {
+	mac := hmac.New(sha256.New, r.hashKey)
+	mac.Write([]byte(value))
+	return "hmac:" + hex.EncodeToString(mac.Sum(nil))[:12]
+}
+
+// redactedError é um erro cuja mensagem passou pela redação.
+type redactedError string
+
+func (e redactedError) Error() string { return string(e) }
+
+func joinKey(group, key string) string {
+	if group == "" {
+		return key
+	}
+	return group + "." + key
+}
+
+// luhn confirma números de cartão (ignora espaços e hífens).
+func luhn(s string) bool {
+	var sum, n int
+	for i := len(s) - 1; i >= 0; i-- {
+		c := s[i]
+		if c == ' ' || c == '-' {
+			continue
+		}
+		d := int(c - '0')
+		if n%2 == 1 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		n++
+	}
+	return n >= 13 && sum%10 == 0
+}
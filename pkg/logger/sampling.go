@@ -0,0 +1,92 @@
+package logger
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"hash/fnv"
+	"log/slog"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+// Sampler decide se um log abaixo de Error é enviado (logs de Error sempre são).
+// A decisão usa o trace ID: os logs de uma mesma requisição são mantidos ou
+// descartados juntos, e a fração enviada bate com a dos requests no App Insights.
+type Sampler interface {
+	Sample(ctx context.Context, r slog.Record) bool
+}
+
+// FixedRateSampler envia uma fração fixa dos logs (Rate entre 0 e 1).
+type FixedRateSampler struct {
+	Rate float64
+}
+
+func (s FixedRateSampler) Sample(ctx context.Context, _ slog.Record) bool {
+	return sampleScore(ctx) < s.Rate
+}
+
+// AdaptiveSampler limita o envio a cerca de MaxPerSecond logs por segundo: a cada
+// janela de um segundo, a fração enviada é recalculada pelo volume da janela anterior.
+// Com pouco tráfego, tudo é enviado.
+type AdaptiveSampler struct {
+	MaxPerSecond float64
+	Now          func() time.Time // Relógio (substituível em testes)
+
+	mu          sync.Mutex
+	windowStart time.Time
+	count       int
+	rate        float64
+}
+
+// NewAdaptiveSampler cria um AdaptiveSampler começando com taxa 1.
+func NewAdaptiveSampler(maxPerSecond float64) *AdaptiveSampler {
+	return &AdaptiveSampler{MaxPerSecond: maxPerSecond, Now: time.Now, rate: 1}
+}
+
+func (s *AdaptiveSampler) Sample(ctx context.Context, _ slog.Record) bool {
+	s.mu.Lock()
+	now := s.Now()
+	if s.windowStart.IsZero() {
+		s.windowStart = now
+	}
+	if elapsed := now.Sub(s.windowStart); elapsed >= time.Second {
+		observed := float64(s.count) / elapsed.Seconds()
+		s.rate = 1
+		if observed > s.MaxPerSecond {
+			s.rate = s.MaxPerSecond / observed
+		}
+		s.windowStart, s.count = now, 0
+	}
+	s.count++
+	rate := s.rate
+	s.mu.Unlock()
+
+	return sampleScore(ctx) < rate
+}
+
+// Rate devolve a fração enviada na janela atual.
+func (s *AdaptiveSampler) Rate() float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.rate
+}
+
+// sampleScore devolve um valor em [0, 1) estável por trace ID (aleatório sem trace).
+// Para trace IDs W3C usa os 8 bytes finais, como o TraceIDRatioBased do OpenTelemetry.
+func sampleScore(ctx context.Context) float64 {
+	traceID, _ := TraceContext(ctx)
+	if traceID == "" {
+		return rand.Float64()
+	}
+	var bits uint64
+	if id, err := hex.DecodeString(traceID); err == nil && len(id) == 16 {
+		bits = binary.BigEndian.Uint64(id[8:])
+	} else {
+		h := fnv.New64a()
+		_, _ = h.Write([]byte(traceID))
+		bits = h.Sum64()
+	}
+	return float64(bits>>11) / (1 << 53)
+}
@@ -0,0 +1,56 @@
+package logger_test
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"testing"
+	"time"
+
+	"go-api-first-steps/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// traceCtx devolve um contexto com um trace ID W3C pseudoaleatório (estável por i).
+func traceCtx(i int) context.Context {
+	r := rand.New(rand.NewPCG(uint64(i), 0))
+	return context.WithValue(context.Background(), logger.TraceIDKey, fmt.Sprintf("%016x%016x", r.Uint64(), r.Uint64()))
+}
+
+func TestFixedRateSampler(t *testing.T) {
+	s := logger.FixedRateSampler{Rate: 0.25}
+	kept := 0
+	for i := range 4000 {
+		ctx := traceCtx(i)
+		got := s.Sample(ctx, slog.Record{})
+		assert.Equal(t, got, s.Sample(ctx, slog.Record{}), "mesma decisão para o mesmo trace")
+		if got {
+			kept++
+		}
+	}
+	assert.InDelta(t, 1000, kept, 150)
+
+	assert.False(t, logger.FixedRateSampler{Rate: 0}.Sample(traceCtx(1), slog.Record{}))
+	assert.True(t, logger.FixedRateSampler{Rate: 1}.Sample(traceCtx(1), slog.Record{}))
+}
+
+func TestAdaptiveSampler(t *testing.T) {
+	now := time.Unix(0, 0)
+	s := logger.NewAdaptiveSampler(10)
+	s.Now = func() time.Time { return now }
+
+	// Primeiro segundo: 100 logs (10x o limite), todos enviados
+	for i := range 100 {
+		assert.True(t, s.Sample(traceCtx(i), slog.Record{}))
+	}
+	now = now.Add(time.Second)
+	s.Sample(traceCtx(0), slog.Record{})
+	assert.InDelta(t, 0.1, s.Rate(), 0.001)
+
+	// Tráfego baixo volta a enviar tudo
+	now = now.Add(10 * time.Second)
+	s.Sample(traceCtx(0), slog.Record{})
+	assert.Equal(t, 1.0, s.Rate())
+}
@@ -1,29 +1,77 @@
 package logger
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/microsoft/ApplicationInsights-Go/appinsights"
 )
 
+// telemetryClient é o client do App Insights criado pelo Setup (nil se desabilitado).
+var telemetryClient appinsights.TelemetryClient
+
+// Telemetry devolve o client do App Insights configurado pelo Setup, ou nil se
+// APPINSIGHTS_CONNECTION_STRING estiver vazio. Usado para enviar requests e
+// dependências (veja tracing.Options.AppInsights) pelo mesmo canal dos logs.
+func Telemetry() appinsights.TelemetryClient {
+	return telemetryClient
+}
+
+// redactor aplica as regras de redação do Setup (por padrão, só as regras fixas).
+var redactor = NewRedactor(RedactOptions{})
+
+// Redaction devolve o Redactor configurado pelo Setup, para que a telemetria
+// enviada fora do slog (spans no App Insights, veja tracing.Options.Redactor)
+// passe pelas mesmas regras e chave de hash dos logs.
+func Redaction() *Redactor {
+	return redactor
+}
+
+// Options configura o logger global.
+type Options struct {
+	ConnectionString string        // App Insights; vazio = só o console
+	Debug            bool          // Mostra os erros internos do SDK do App Insights
+	Level            slog.Leveler  // Nível inicial do console (Controller().Console). nil = Info
+	AzureLevel       slog.Leveler  // Nível inicial do App Insights (Controller().AppInsights). nil = Info
+	AzureSampler     Sampler       // Amostragem dos logs abaixo de Error no App Insights. nil = envia todos
+	Async            *AsyncOptions // Fila por destino (veja AsyncHandler). nil = entrega síncrona
+	// Redação de dados sensíveis antes de qualquer destino. O valor zero aplica só
+	// as regras fixas (credenciais e JWTs); veja DefaultRedaction
+	Redaction RedactOptions
+}
+
+// sinks guarda o flush de cada destino com buffer, chamado por Shutdown, e
+// pipelines as filas assíncronas criadas pelo Setup (veja Stats).
+var (
+	sinksMu   sync.Mutex
+	sinks     []func(context.Context) error
+	pipelines []*AsyncHandler
+)
+
 // Setup configura o logger global (JSON + Azure opcional)
-func Setup(connectionString string, debug bool) {
+func Setup(opts Options) {
+	// 0. Níveis ajustáveis em runtime (LevelController global)
+	controller.Console.Set(levelOrInfo(opts.Level))
+	controller.AppInsights.Set(levelOrInfo(opts.AzureLevel))
+
 	// 1. Handler Básico (Terminal)
-	jsonHandler := slog.NewJSONHandler(os.Stdout, nil)
+	var jsonHandler slog.Handler = &sinkLevel{Handler: slog.NewJSONHandler(os.Stdout, nil), level: controller.Console}
 
 	// 2. Handler Azure (Se tiver config)
 	var azureHandler slog.Handler
 
-	if connectionString != "" {
-		iKey, endpoint := parseConnectionString(connectionString)
+	if opts.ConnectionString != "" {
+		iKey, endpoint := parseConnectionString(opts.ConnectionString)
 
 		// Se falhar o parse (ex: for só a key antiga), tenta usar direto
 		if iKey == "" {
-			iKey = connectionString
+			iKey = opts.ConnectionString
 		}
 
 		config := appinsights.NewTelemetryConfiguration(iKey)
@@ -40,7 +88,7 @@ func Setup(connectionString string, debug bool) {
 		client := appinsights.NewTelemetryClientFromConfig(config)
 
 		// DIAGNÓSTICO: Ver erros internos do SDK
-		if debug {
+		if opts.Debug {
 			appinsights.NewDiagnosticsMessageListener(func(msg string) error {
 				fmt.Printf("🔴 [AppInsights Internal] %s\n", msg)
 				return nil
@@ -48,7 +96,25 @@ func Setup(connectionString string, debug bool) {
 		}
 
 		client.Context().CommonProperties["service"] = "api-go-template" // TODO change to you own app
-		azureHandler = NewAzureHandler(client)
+		telemetryClient = client
+
+		azure := NewAzureHandler(client)
+		azure.Level = controller.AppInsights
+		azure.Sampler = opts.AzureSampler
+		azureHandler = azure
+	}
+
+	// 2.1 Filas assíncronas: um destino lento não atrasa quem loga. Drenadas no
+	// Shutdown antes do flush do App Insights
+	if opts.Async != nil {
+		jsonHandler = startPipeline("console", jsonHandler, *opts.Async)
+		if azureHandler != nil {
+			azureHandler = startPipeline("appinsights", azureHandler, *opts.Async)
+		}
+	}
+	if telemetryClient != nil {
+		client := telemetryClient
+		registerSink(func(ctx context.Context) error { return flushAppInsights(ctx, client) })
 	}
 
 	// 3. Unificação (Fanout)
@@ -59,13 +125,86 @@ func Setup(connectionString string, debug bool) {
 		finalHandler = jsonHandler
 	}
 
-	// 4. Trace ID Context Wrapper
+	// 4. Redação (PII, tokens) antes das filas e de qualquer destino
+	redactor = NewRedactor(opts.Redaction)
+	finalHandler = &RedactHandler{handler: finalHandler, Redactor: redactor}
+
+	// 4.1 Níveis (globais, overrides por pacote/rota e debug por requisição)
+	finalHandler = NewLevelHandler(finalHandler, controller)
+
+	// 5. Trace ID Context Wrapper
 	ctxHandler := NewContextHandler(finalHandler)
 
-	// 5. Define como Global
+	// 6. Define como Global
 	slog.SetDefault(slog.New(ctxHandler))
 }
 
+// Shutdown envia o que está em buffer em todos os destinos (ex: lotes pendentes do
+// App Insights). Chame no graceful shutdown, depois do último log: os posteriores
+// não chegam mais ao App Insights.
+func Shutdown(ctx context.Context) error {
+	sinksMu.Lock()
+	flushes := sinks
+	sinks = nil
+	sinksMu.Unlock()
+
+	var errs []error
+	for _, flush := range flushes {
+		errs = append(errs, flush(ctx))
+	}
+	return errors.Join(errs...)
+}
+
+func levelOrInfo(l slog.Leveler) slog.Level {
+	if l == nil {
+		return slog.LevelInfo
+	}
+	return l.Level()
+}
+
+// Stats devolve os contadores das filas assíncronas criadas pelo Setup.
+func Stats() []AsyncStats {
+	sinksMu.Lock()
+	defer sinksMu.Unlock()
+	out := make([]AsyncStats, 0, len(pipelines))
+	for _, p := range pipelines {
+		out = append(out, p.Stats())
+	}
+	return out
+}
+
+func startPipeline(sink string, h slog.Handler, opts AsyncOptions) *AsyncHandler {
+	async := NewAsyncHandler(sink, h, opts)
+	sinksMu.Lock()
+	pipelines = append(pipelines, async)
+	sinksMu.Unlock()
+	registerSink(async.Close)
+	return async
+}
+
+func registerSink(flush func(context.Context) error) {
+	sinksMu.Lock()
+	sinks = append(sinks, flush)
+	sinksMu.Unlock()
+}
+
+// flushAppInsights fecha o canal do client, reenviando lotes com falha até o prazo de ctx.
+// O client é desabilitado antes: depois do Shutdown os logs vão só para o console
+// (um Track no canal fechado bloquearia).
+func flushAppInsights(ctx context.Context, client appinsights.TelemetryClient) error {
+	client.SetIsEnabled(false)
+	retryTimeout := 10 * time.Second
+	if deadline, ok := ctx.Deadline(); ok {
+		retryTimeout = time.Until(deadline)
+	}
+	select {
+	case <-client.Channel().Close(retryTimeout):
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("telemetria do App Insights não enviada: %w", ctx.Err())
+	}
+}
+
 // parseConnectionString extrai InstrumentationKey e IngestionEndpoint
 func parseConnectionString(cs string) (string, string) {
 	parts := strings.Split(cs, ";")
@@ -0,0 +1,25 @@
+// Package traceid guarda no context.Context o trace ID legado, propagado no header
+// X-Trace-ID. Não tem dependências, para que o SDK (pkg/client) e o servidor
+// (pkg/logger, middlewares) compartilhem a chave sem se importarem.
+package traceid
+
+import "context"
+
+type ctxKey string
+
+// Key é a chave do trace ID no contexto.
+const Key ctxKey = "trace_id"
+
+// Header é o header HTTP que carrega o trace ID legado.
+const Header = "X-Trace-ID"
+
+// With devolve um contexto com o trace ID.
+func With(ctx context.Context, traceID string) context.Context {
+	return context.WithValue(ctx, Key, traceID)
+}
+
+// From devolve o trace ID do contexto ("" se ausente).
+func From(ctx context.Context) string {
+	traceID, _ := ctx.Value(Key).(string)
+	return traceID
+}